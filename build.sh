@@ -16,6 +16,7 @@ EXTEND_DIR="$BASE/extend"
 LOCAL_BUILD_DIR="$BASE/cmd"
 EXTEND_BUILT_DIR="$BASE/built/usr/bin"
 LOCAL_BUILT_DIR="$BASE/built/bin"
+LOCAL_MAN_DIR="$BASE/built/usr/share/man/man1"
 
 # Function to log to stdout with green color
 log() {
@@ -84,6 +85,20 @@ move_executables() {
     done
 }
 
+# Function to generate section-1 man pages for the binaries that support the hidden --man flag
+install_man_pages() {
+    mkdir -p "$LOCAL_MAN_DIR"
+    for bin in "$LOCAL_BUILT_DIR"/*; do
+        [ -x "$bin" ] || continue
+        name="${bin##*/}"
+        # Commands such as `test` and `printf` can't take flags, they'll just print something else
+        if page="$("$bin" --man 2>/dev/null </dev/null)" && [ "${page#.TH }" != "$page" ]; then
+            printf '%s\n' "$page" > "$LOCAL_MAN_DIR/$name.1"
+            log "Installed man page for \"$name\""
+        fi
+    done
+}
+
 # Function to clean up the built directory and remove binaries in specified main directories
 clean_up() {
     # Remove the built directory
@@ -216,6 +231,7 @@ case "$1" in
     build_commands "$LOCAL_BUILD_DIR" "$EXTEND_DIR"
     move_executables "$LOCAL_BUILD_DIR" "$EXTEND_DIR"
     remove_excluded_files "$EXTEND_EFILES" "$EXTEND_BUILT_DIR" "$LOCAL_BUILT_DIR"
+    install_man_pages
     log "Build process completed"
     ;;
 "clean")
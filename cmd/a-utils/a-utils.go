@@ -0,0 +1,38 @@
+// Copyright (c) 2024-2024 xplshn                       [3BSD]
+// For more details refer to https://github.com/xplshn/a-utils
+
+// a-utils is a multicall binary containing every command of a-utils. It runs the command it is
+// invoked as (through a symlink named after it, see --install) or the one its first argument
+// names, see ccmd.MulticallMain. noroot-do isn't one of them: it embeds bwrap and keeps its
+// modes in its own executable, see the README.
+package main
+
+import (
+	"os"
+
+	"github.com/xplshn/a-utils/pkg/ccmd"
+	_ "github.com/xplshn/a-utils/pkg/cmds/cal"
+	_ "github.com/xplshn/a-utils/pkg/cmds/catv"
+	_ "github.com/xplshn/a-utils/pkg/cmds/ccat"
+	_ "github.com/xplshn/a-utils/pkg/cmds/dial"
+	_ "github.com/xplshn/a-utils/pkg/cmds/ed"
+	_ "github.com/xplshn/a-utils/pkg/cmds/envsubst"
+	_ "github.com/xplshn/a-utils/pkg/cmds/fin"
+	_ "github.com/xplshn/a-utils/pkg/cmds/fortune"
+	_ "github.com/xplshn/a-utils/pkg/cmds/getconf"
+	_ "github.com/xplshn/a-utils/pkg/cmds/hpwd"
+	_ "github.com/xplshn/a-utils/pkg/cmds/importenv"
+	_ "github.com/xplshn/a-utils/pkg/cmds/isainfo"
+	_ "github.com/xplshn/a-utils/pkg/cmds/issue"
+	_ "github.com/xplshn/a-utils/pkg/cmds/listen"
+	_ "github.com/xplshn/a-utils/pkg/cmds/ntpdate"
+	_ "github.com/xplshn/a-utils/pkg/cmds/printf"
+	_ "github.com/xplshn/a-utils/pkg/cmds/relf"
+	_ "github.com/xplshn/a-utils/pkg/cmds/test"
+	_ "github.com/xplshn/a-utils/pkg/cmds/walk"
+	_ "github.com/xplshn/a-utils/pkg/cmds/wttr"
+)
+
+func main() {
+	os.Exit(ccmd.MulticallMain(os.Args, ccmd.OSStdio()))
+}
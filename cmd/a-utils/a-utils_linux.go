@@ -0,0 +1,6 @@
+package main
+
+// Commands that are only available on Linux
+import (
+	_ "github.com/xplshn/a-utils/pkg/cmds/lddfiles"
+)
@@ -11,6 +11,8 @@ import (
 )
 
 func main() {
+	fs := ccmd.NewFlagSet("demo", flag.ExitOnError)
+
 	// Create an instance of CmdInfo
 	cmdInfo := &ccmd.CmdInfo{
 		Authors:     []string{"John Doe", "Jane Smith", "Joe Momma"},
@@ -19,28 +21,28 @@ func main() {
 		Synopsis:    "demo [options]",
 		Description: "This is an example command line tool to demonstrate the ccmd (consistent command line) library.",
 		CustomFields: map[string]interface{}{
-			"1_Behavior": "This tool demonstrates basic usage of the ccmd library and formatting.",
-			"2_Notes":    "We good",
+			"Notes": "We good",
 		},
-		Since: 1999,
+		Behavior: "This tool demonstrates basic usage of the ccmd library and formatting.",
+		Since:    1999,
+		Flags:    fs,
 	}
 
-	// Define some flags using the flag package
-	flag.Bool("verbose", false, "Enable verbose output")
-	flag.Int("count", 1, "Number of times to repeat")
-	flag.String("name", "user", "Name to greet")
-	flag.Duration("duration", time.Second, "Duration to wait")
+	// Define some flags, ccmd.FlagSet embeds a flag.FlagSet
+	fs.Bool("verbose", false, "Enable verbose output")
+	fs.Int("count", 1, "Number of times to repeat")
+	fs.String("name", "user", "Name to greet")
+	fs.Duration("duration", time.Second, "Duration to wait")
 
-	flag.Usage = func() {
+	fs.Usage = func() {
 		helpPage, err := cmdInfo.GenerateHelpPage()
 		if err != nil {
-			fmt.Printf("Error generating help page: %v\n", err)
-			return
+			ccmd.Fatalf("generating help page: %v", err)
 		}
 		fmt.Print(helpPage)
 	}
 	// Parse the flags
-	flag.Parse()
+	cmdInfo.Parse()
 
 	// Print the centered help page
 	fmt.Println(ccmd.FormatCenter("Centered text 1"))
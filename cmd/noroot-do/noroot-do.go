@@ -6,14 +6,15 @@ import (
 	"flag"
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"syscall"
 
 	"github.com/maja42/ember"
 	"github.com/maja42/ember/embedding"
+	"github.com/xplshn/a-utils/pkg/ccmd"
 )
 
 //go:embed bwrap
@@ -208,6 +209,7 @@ func runBwrapCommand(args []string, mode string) error {
 			return fmt.Errorf("failed to create temp file for embedded bwrap: %v", err)
 		}
 		defer os.Remove(tmpFile.Name())
+		defer ccmd.AtExit(func() { os.Remove(tmpFile.Name()) })()
 
 		bwrapBinary, err := embeddedBwrap.Open("bwrap")
 		if err != nil {
@@ -247,29 +249,33 @@ func runBwrapCommand(args []string, mode string) error {
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
 
-	return cmd.Run()
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	defer ccmd.ForwardSignals(cmd.Process)()
+	return cmd.Wait()
 }
 
 // Command handlers
 func cmdSet(rootfs string) {
 	absRootfs, err := filepath.Abs(rootfs)
 	if err != nil {
-		log.Fatalf("Error determining absolute path: %v", err)
+		ccmd.Fatalf("determining absolute path: %v", err)
 	}
 	config.RootFS = absRootfs
 	if err := saveConfig(); err != nil {
-		log.Fatalf("Error saving config: %v", err)
+		ccmd.Fatalf("saving config: %v", err)
 	}
 	fmt.Printf("Root filesystem set to: %s\n", absRootfs)
 }
 
 func cmdUnset() {
 	if config.RootFS == "" {
-		log.Fatalf("No rootfs is currently set.")
+		ccmd.Fatalf("no rootfs is currently set")
 	}
 	config.RootFS = ""
 	if err := saveConfig(); err != nil {
-		log.Fatalf("Error saving config: %v", err)
+		ccmd.Fatalf("saving config: %v", err)
 	}
 	fmt.Println("Root filesystem unset successfully.")
 }
@@ -282,8 +288,9 @@ func cmdInfo() {
 		fmt.Println("bwrap: system")
 	}
 
+	color := ccmd.StdoutColor(ccmd.ColorAuto)
 	for mode, flags := range config.ModeFlags {
-		fmt.Printf("Mode: \x1b[94m%s\x1b[0m, Flags: %s\n", mode, flags)
+		fmt.Printf("Mode: %s, Flags: %s\n", color.Paint(mode, ccmd.FgBrightBlue), flags)
 		if config.SedimentModes[mode] {
 			fmt.Printf("Mode %s is read-only (sedimented).\n", mode)
 		}
@@ -293,7 +300,7 @@ func cmdInfo() {
 func cmdToggleEmbeddedBwrap() {
 	config.UseEmbedded = !config.UseEmbedded
 	if err := saveConfig(); err != nil {
-		log.Fatalf("Error saving config: %v", err)
+		ccmd.Fatalf("saving config: %v", err)
 	}
 	if config.UseEmbedded {
 		fmt.Println("Now using embedded bwrap.")
@@ -304,53 +311,57 @@ func cmdToggleEmbeddedBwrap() {
 
 func cmdRun(mode string, args []string) {
 	if config.RootFS == "" {
-		log.Fatalf("No rootfs is currently set.")
+		ccmd.Fatalf("no rootfs is currently set")
 	}
 	if _, ok := config.ModeFlags[mode]; !ok {
-		log.Fatalf("Mode [%s] does not exist.", mode)
+		ccmd.Exitf(ccmd.ExitNotFound, "mode [%s] does not exist", mode)
 	}
 	if len(args) == 0 {
-		log.Fatalf("No command provided to run inside the chroot.")
+		ccmd.Exitf(ccmd.ExitUsage, "no command provided to run inside the chroot")
 	}
 	if err := runBwrapCommand(args, mode); err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
-			os.Exit(exitErr.ExitCode())
+			// Report a child killed by a signal as shells do, see ccmd.ExitInterrupted
+			if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+				ccmd.Exit(ccmd.ExitCode(128 + int(status.Signal())))
+			}
+			ccmd.Exit(ccmd.ExitCode(exitErr.ExitCode()))
 		}
 		// I hope this is unreachable code, if it isn't, something has gone terribly wrong
-		os.Exit(1)
+		ccmd.Fatalf("%v", err)
 	}
 }
 
 func cmdSetModeFlags(mode string, flags string) {
 	if config.SedimentModes[mode] {
-		log.Fatalf("Mode %s is read-only (sedimented) and cannot be modified.", mode)
+		ccmd.Exitf(ccmd.ExitPermission, "mode %s is read-only (sedimented) and cannot be modified", mode)
 	}
 	config.ModeFlags[mode] = flags
 	if err := saveConfig(); err != nil {
-		log.Fatalf("Error saving config: %v", err)
+		ccmd.Fatalf("saving config: %v", err)
 	}
 	fmt.Printf("Successfully configured mode \"%s\"\n", mode)
 }
 
 func cmdRemoveMode(mode string) {
 	if config.SedimentModes[mode] {
-		log.Fatalf("Mode %s is read-only (sedimented) and cannot be removed.", mode)
+		ccmd.Exitf(ccmd.ExitPermission, "mode %s is read-only (sedimented) and cannot be removed", mode)
 	}
 	delete(config.ModeFlags, mode)
 	delete(config.SedimentModes, mode)
 	if err := saveConfig(); err != nil {
-		log.Fatalf("Error saving config: %v", err)
+		ccmd.Fatalf("saving config: %v", err)
 	}
 	fmt.Printf("Mode %s removed successfully.\n", mode)
 }
 
 func cmdSetSediment(mode string) {
 	if _, exists := config.ModeFlags[mode]; !exists {
-		log.Fatalf("Mode [%s] does not exist.", mode)
+		ccmd.Exitf(ccmd.ExitNotFound, "mode [%s] does not exist", mode)
 	}
 	config.SedimentModes[mode] = true
 	if err := saveConfig(); err != nil {
-		log.Fatalf("Error saving config: %v", err)
+		ccmd.Fatalf("saving config: %v", err)
 	}
 	fmt.Printf("Mode %s set to read-only (sedimented).\n", mode)
 }
@@ -358,10 +369,10 @@ func cmdSetSediment(mode string) {
 func cmdDumpConfig(filePath string) {
 	data, err := json.MarshalIndent(config, "", "  ")
 	if err != nil {
-		log.Fatalf("Error marshalling config: %v", err)
+		ccmd.Fatalf("marshalling config: %v", err)
 	}
 	if err := os.WriteFile(filePath, data, 0644); err != nil {
-		log.Fatalf("Error writing config to file: %v", err)
+		ccmd.Fatalf("writing config to file: %v", err)
 	}
 	fmt.Printf("Config dumped to: %s\n", filePath)
 }
@@ -369,35 +380,74 @@ func cmdDumpConfig(filePath string) {
 func cmdLoadConfig(filePath string) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
-		log.Fatalf("Error reading config file: %v", err)
+		ccmd.Fatalf("reading config file: %v", err)
 	}
 	if err := json.Unmarshal(data, &config); err != nil {
-		log.Fatalf("Error unmarshalling config: %v", err)
+		ccmd.Fatalf("unmarshalling config: %v", err)
 	}
 	if err := saveConfig(); err != nil {
-		log.Fatalf("Error saving config: %v", err)
+		ccmd.Fatalf("saving config: %v", err)
 	}
 	fmt.Printf("Config loaded from: %s\n", filePath)
 }
 
+// modeNames lists the modes present in the configuration, for shell completion.
+func modeNames() []string {
+	var modes []string
+	for mode := range config.ModeFlags {
+		modes = append(modes, mode)
+	}
+	return modes
+}
+
 func main() {
 	if err := loadConfig(); err != nil {
-		log.Fatalf("Error loading configuration: %v", err)
+		ccmd.Fatalf("loading configuration: %v", err)
+	}
+
+	fs := ccmd.NewFlagSet("noroot-do", flag.ExitOnError)
+	setFlag := fs.String("set", "", "Set the root filesystem")
+	unsetFlag := fs.Bool("unset", false, "Unset the root filesystem")
+	infoFlag := fs.Bool("info", false, "Show information about the root filesystem")
+	modeFlag := fs.String("mode", "", "Run command in a specific mode")
+	toggleEmbeddedFlag := fs.Bool("toggle-embedded-bwrap", false, "Toggle between embedded and system bwrap")
+	setModeFlagsFlag := fs.String("set-mode-flags", "", "Set flags for a specific mode (e.g., --set-mode-flags mode:\"flags\")")
+	removeModeFlag := fs.String("remove-mode", "", "Remove a specific mode")
+	setSedimentFlag := fs.String("sediment", "", "Set a mode to read-only (sediment)")
+	dumpConfigFlag := fs.String("dump-config", "", "Dump the current config to a file")
+	loadConfigFlag := fs.String("load-config", "", "Load config from a file")
+
+	info := &ccmd.CmdInfo{
+		Authors:     []string{"xplshn"},
+		Repository:  "https://github.com/xplshn/a-utils",
+		Name:        "noroot-do",
+		Synopsis:    "<--set [ROOTFS]|--unset|--info|--toggle-embedded-bwrap|--mode [MODE] [COMMAND]|...>",
+		Description: "Run commands inside of a rootfs, without root, using bwrap",
+		Examples: []ccmd.Example{
+			{Description: "Set the rootfs", Command: "noroot-do --set ./alpine-rootfs"},
+			{Description: "Run a shell inside of it", Command: "noroot-do --mode default sh"},
+		},
+		Behavior: "The configuration is stored within the noroot-do binary itself, see --dump-config and --load-config.",
+		Completions: map[string]ccmd.Completion{
+			"set":         {Dirs: true},
+			"mode":        {Dynamic: modeNames},
+			"remove-mode": {Dynamic: modeNames},
+			"sediment":    {Dynamic: modeNames},
+			"dump-config": {Files: true},
+			"load-config": {Files: true},
+		},
+		Flags: fs,
+	}
+	helpPage, err := info.GenerateHelpPage()
+	if err != nil {
+		ccmd.Fatalf("generating help page: %v", err)
+	}
+	fs.Usage = func() {
+		fmt.Print(helpPage)
 	}
 
-	setFlag := flag.String("set", "", "Set the root filesystem")
-	unsetFlag := flag.Bool("unset", false, "Unset the root filesystem")
-	infoFlag := flag.Bool("info", false, "Show information about the root filesystem")
-	modeFlag := flag.String("mode", "", "Run command in a specific mode")
-	toggleEmbeddedFlag := flag.Bool("toggle-embedded-bwrap", false, "Toggle between embedded and system bwrap")
-	setModeFlagsFlag := flag.String("set-mode-flags", "", "Set flags for a specific mode (e.g., --set-mode-flags mode:\"flags\")")
-	removeModeFlag := flag.String("remove-mode", "", "Remove a specific mode")
-	setSedimentFlag := flag.String("sediment", "", "Set a mode to read-only (sediment)")
-	dumpConfigFlag := flag.String("dump-config", "", "Dump the current config to a file")
-	loadConfigFlag := flag.String("load-config", "", "Load config from a file")
-
-	flag.Parse()
-	args := flag.Args()
+	info.Parse()
+	args := fs.Args()
 
 	switch {
 	case *setFlag != "":
@@ -410,13 +460,13 @@ func main() {
 		cmdToggleEmbeddedBwrap()
 	case *modeFlag != "":
 		if *modeFlag == "" {
-			log.Fatalf("--mode is obligatory")
+			ccmd.Exitf(ccmd.ExitUsage, "--mode is obligatory")
 		}
 		cmdRun(*modeFlag, args)
 	case *setModeFlagsFlag != "":
 		parts := strings.SplitN(*setModeFlagsFlag, ":", 2)
 		if len(parts) != 2 {
-			log.Fatalf("Invalid format for --set-mode-flags. Use --set-mode-flags modeName:\"flags to be passed to bwrap\"")
+			ccmd.Exitf(ccmd.ExitUsage, "invalid format for --set-mode-flags, use --set-mode-flags modeName:\"flags to be passed to bwrap\"")
 		}
 		cmdSetModeFlags(parts[0], parts[1])
 	case *removeModeFlag != "":
@@ -428,7 +478,7 @@ func main() {
 	case *loadConfigFlag != "":
 		cmdLoadConfig(*loadConfigFlag)
 	default:
-		flag.Usage()
+		fs.Usage()
 	}
 }
 
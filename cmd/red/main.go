@@ -0,0 +1,15 @@
+// Copyright (c) 2024, xplshn, u-root and contributors  [3BSD]
+// For more details refer to https://github.com/xplshn/a-utils
+package main
+
+import (
+	"os"
+
+	"github.com/xplshn/a-utils/pkg/ccmd"
+	"github.com/xplshn/a-utils/pkg/cmds/ed"
+)
+
+// red is ed in restricted mode, see ed -r
+func main() {
+	os.Exit(ccmd.Run(ed.Main, os.Args, ccmd.OSStdio()))
+}
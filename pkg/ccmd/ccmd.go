@@ -2,15 +2,11 @@
 package ccmd
 
 import (
+	"errors"
 	"flag"
 	"fmt"
-	"os"
-	"os/exec"
-	"regexp"
-	"strconv"
 	"strings"
 	"time"
-	"unicode/utf8"
 )
 
 type CmdInfo struct {
@@ -23,136 +19,131 @@ type CmdInfo struct {
 	Options      []string               // Populated automatically
 	ExcludeFlags map[string]bool        // Tracks flags to exclude from help
 	Since        int                    // Start year of the project
-	CustomFields map[string]interface{} // Support for additional custom fields
+	Examples     []Example              // Examples of use, shown in order
+	Behavior     string                 // How the command behaves, in prose
+	EnvVars      []EnvVar               // Environment variables the command reads
+	Extensions   []Extension            // Behaviors that aren't POSIX, see PosixlyCorrect; flags are marked with FlagSet.Extension
+	ExitStatus   map[int]string         // Meaning of exit statuses other than the standard ones, see ExitCode
+	SeeAlso      []string               // Related commands, e.g: "walk(1)"
+	CustomFields map[string]interface{} // Support for additional custom fields, see customSections
+	Flags        *FlagSet               // Flags of the command, defaults to CommandLine
+	Completions  map[string]Completion  // Completion hints for the values of flags, by flag name
+	Fields       []Field                // Fields of the records output with --format
 }
 
-// PopulateOptions fills the Options slice based on registered flags.
+// flagSet returns the FlagSet the command uses.
+func (ci *CmdInfo) flagSet() *FlagSet {
+	if ci.Flags != nil {
+		return ci.Flags
+	}
+	return CommandLine
+}
+
+// PopulateOptions fills the Options slice based on registered flags, with their usage
+// translated, see T. Aliases of a flag are shown on the same line, e.g: "-v, --verbose: usage",
+// and extensions are noted as such, see FlagSet.Extension; if only some of the names are, those
+// are named, e.g: "-s, --silent: usage (NOT POSIX: --silent)".
 func (ci *CmdInfo) PopulateOptions() {
 	ci.Options = nil
-	flag.VisitAll(func(f *flag.Flag) {
-		if !ci.ExcludeFlags[f.Name] {
-			prefix := "--"
-			if len(f.Name) == 1 {
-				prefix = "-"
+	fs := ci.flagSet()
+	fs.VisitAll(func(f *flag.Flag) {
+		if ci.ExcludeFlags[f.Name] || fs.IsAlias(f.Name) || fs.IsHidden(f.Name) {
+			return
+		}
+		var names, extensions []string
+		for _, name := range fs.Aliases(f.Name) {
+			names = append(names, flagPrefix(name)+name)
+			if fs.IsExtension(name) {
+				extensions = append(extensions, flagPrefix(name)+name)
 			}
-			ci.Options = append(ci.Options, fmt.Sprintf("%s%s: %s", prefix, f.Name, f.Usage))
 		}
+		usage := T(f.Usage)
+		if len(extensions) == len(names) {
+			usage += " (" + T("This is NOT POSIX") + ")"
+		} else if len(extensions) > 0 {
+			usage += " (" + fmt.Sprintf(T("NOT POSIX: %s"), strings.Join(extensions, ", ")) + ")"
+		}
+		ci.Options = append(ci.Options, fmt.Sprintf("%s: %s", strings.Join(names, ", "), usage))
 	})
 }
 
-// GenerateHelpPage creates a help page based on CmdInfo fields.
+// flagPrefix returns "-" for single-letter flags and "--" for long ones.
+func flagPrefix(name string) string {
+	if len(name) == 1 {
+		return "-"
+	}
+	return "--"
+}
+
+// GenerateHelpPage creates a help page based on CmdInfo fields, in the language of the
+// locale of messages when the catalogs have translations for it, see T.
 func (ci *CmdInfo) GenerateHelpPage() (string, error) {
 	if ci.Name == "" || ci.Description == "" || (ci.Synopsis == "" && ci.Usage == "") {
-		return "", fmt.Errorf("Name, Description, and either Synopsis or Usage must be set")
+		return "", errors.New(T("Name, Description, and either Synopsis or Usage must be set"))
 	}
 	ci.PopulateOptions()
 
 	sb := &strings.Builder{}
+	width := GetTerminalWidth()
 
 	// Copyright and Authors
 	year := time.Now().Year()
 	if ci.Since > 0 {
-		sb.WriteString(fmt.Sprintf("\n Copyright (c) %d-%d: ", ci.Since, year))
+		sb.WriteString("\n " + fmt.Sprintf(T("Copyright (c) %d-%d: "), ci.Since, year))
 	} else {
-		sb.WriteString(fmt.Sprintf("\n Copyright (c) %d: ", year))
+		sb.WriteString("\n " + fmt.Sprintf(T("Copyright (c) %d: "), year))
 	}
-	sb.WriteString(strings.Join(ci.Authors, ", ") + " and contributors\n")
+	sb.WriteString(fmt.Sprintf(T("%s and contributors"), strings.Join(ci.Authors, ", ")) + "\n")
 	if ci.Repository != "" {
-		sb.WriteString(fmt.Sprintf(" For more details refer to %s\n", ci.Repository))
+		sb.WriteString(" " + fmt.Sprintf(T("For more details refer to %s"), ci.Repository) + "\n")
 	}
 
 	// Synopsis or Usage
 	if ci.Synopsis != "" {
-		sb.WriteString("\n  Synopsis\n")
+		sb.WriteString("\n  " + T("Synopsis") + "\n")
 		sb.WriteString(fmt.Sprintf("    %s %s\n", ci.Name, ci.Synopsis))
 	} else if ci.Usage != "" {
-		sb.WriteString("\n  Usage\n")
+		sb.WriteString("\n  " + T("Usage") + "\n")
 		sb.WriteString(fmt.Sprintf("    %s %s\n", ci.Name, ci.Usage))
 	}
 
 	// Description
-	sb.WriteString("  Description:\n")
-	sb.WriteString(fmt.Sprintf("    %s\n", ci.Description))
+	sb.WriteString("  " + T("Description") + ":\n")
+	sb.WriteString(Wrap("    "+T(ci.Description), width) + "\n")
 
 	// Options
 	if len(ci.Options) > 0 {
-		sb.WriteString("  Options:\n")
+		sb.WriteString("  " + T("Options") + ":\n")
 		for _, opt := range ci.Options {
-			sb.WriteString(fmt.Sprintf("    %s\n", opt))
-		}
-	}
-
-	// Custom Fields
-	for i := 1; i <= len(ci.CustomFields); i++ {
-		key := fmt.Sprintf("%d_", i)
-		for field, value := range ci.CustomFields {
-			if strings.HasPrefix(field, key) {
-				sb.WriteString(fmt.Sprintf("  %s:\n", strings.TrimPrefix(field, key)))
-
-				// Ensure that each line is indented the same as the first line
-				lines := strings.Split(fmt.Sprintf("%s", value), "\n")
-				for _, line := range lines {
+			// Long usages continue under the usage, not under the flag names
+			for i, line := range wrapLine(opt, width-6) {
+				if i == 0 {
 					sb.WriteString(fmt.Sprintf("    %s\n", line))
+				} else {
+					sb.WriteString(fmt.Sprintf("      %s\n", line))
 				}
 			}
 		}
 	}
 
-	// Notes (always the last field)
-	if notes, ok := ci.CustomFields["Notes"]; ok {
-		sb.WriteString("  Notes:\n")
-		lines := strings.Split(fmt.Sprintf("%s", notes), "\n")
-		for _, line := range lines {
-			sb.WriteString(fmt.Sprintf("    %s\n", line))
+	// Output fields
+	if len(ci.Fields) > 0 {
+		sb.WriteString("  " + T("Output fields") + " (--format=json|ndjson|tsv):\n")
+		for _, f := range ci.Fields {
+			sb.WriteString(fmt.Sprintf("    %s: %s\n", f.Name, T(f.Description)))
 		}
 	}
 
+	// Examples, Behavior, Environment, Exit status, custom fields, Notes and See also
+	ci.writeHelpSections(sb, width)
+
 	sb.WriteString("\n")
 	return sb.String(), nil
 }
 
-// GetTerminalWidth attempts to determine the width of the terminal.
-// It first tries using "stty size", then "tput cols", and finally falls back to 80 columns.
-func GetTerminalWidth() int {
-	cmd := exec.Command("stty", "size")
-	cmd.Stdin = os.Stdin
-	out, err := cmd.Output()
-	if err == nil {
-		parts := strings.Split(strings.TrimSpace(string(out)), " ")
-		if len(parts) == 2 {
-			width, _ := strconv.Atoi(parts[1])
-			return width
-		}
-	}
-
-	cmd = exec.Command("tput", "cols")
-	cmd.Stdin = os.Stdin
-	out, err = cmd.Output()
-	if err == nil {
-		width, _ := strconv.Atoi(strings.TrimSpace(string(out)))
-		return width
-	}
-
-	return 80
-}
-
 // CFormatCenter formats the text to be centered based on the width passed by the user.
 func CFormatCenter(text string, width int) string {
-	lines := strings.Split(text, "\n")
-	var sb strings.Builder
-
-	for _, line := range lines {
-		lineLength := len(line)
-		if lineLength < width {
-			padding := (width - lineLength) / 2
-			// Print the line padded to the proper width
-			sb.WriteString(fmt.Sprintf("%*s", width-padding, line))
-		} else {
-			sb.WriteString(line)
-		}
-	}
-
-	return sb.String()
+	return Center(text, width)
 }
 
 // FormatCenter formats the text to be centered based on the terminal width.
@@ -167,36 +158,10 @@ func FormatRight(text string) string {
 
 // CFormatRight formats the text to be right-aligned based on the width passed by the user.
 func CFormatRight(text string, width int) string {
-	lines := strings.Split(text, "\n")
-	var sb strings.Builder
-
-	for _, line := range lines {
-		lineLength := len(line)
-		if lineLength < width {
-			// Right-align the line with padding on the left
-			sb.WriteString(fmt.Sprintf("%*s", width, line))
-		} else {
-			sb.WriteString(line)
-		}
-	}
-
-	return sb.String()
+	return AlignRight(text, width)
 }
 
-// RelativeTo finds the length of the longest line in the given string, accounting for non-printing characters and ANSI escape sequences.
+// RelativeTo finds the display width of the longest line in the given string, ignoring ANSI escape sequences.
 func RelativeTo(input string) int {
-	// Regular expression to match ANSI escape sequences
-	ansiEscape := regexp.MustCompile(`\x1b\[[0-9;]*[a-zA-Z]`)
-
-	lines := strings.Split(input, "\n")
-	maxLength := 0
-	for _, line := range lines {
-		// Remove ANSI escape sequences
-		cleanLine := ansiEscape.ReplaceAllString(line, "")
-		lineLength := utf8.RuneCountInString(cleanLine)
-		if lineLength > maxLength {
-			maxLength = lineLength
-		}
-	}
-	return maxLength
+	return MaxWidth(input)
 }
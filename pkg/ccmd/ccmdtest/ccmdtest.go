@@ -0,0 +1,28 @@
+// Package ccmdtest helps testing commands built with ccmd.
+package ccmdtest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/xplshn/a-utils/pkg/ccmd"
+)
+
+// AssertExit runs f, failing t unless f exits with want through ccmd.Exit, ccmd.Exitf or
+// ccmd.Fatalf. It returns the diagnostics f wrote.
+func AssertExit(t testing.TB, want ccmd.ExitCode, f func()) string {
+	t.Helper()
+	var stderr strings.Builder
+	saved := ccmd.Stderr
+	ccmd.Stderr = &stderr
+	defer func() { ccmd.Stderr = saved }()
+
+	got := ccmd.CatchExit(f)
+	switch {
+	case got < 0:
+		t.Errorf("returned, want exit status %d", want)
+	case got != int(want):
+		t.Errorf("exit status %d, want %d; stderr: %q", got, want, stderr.String())
+	}
+	return stderr.String()
+}
@@ -0,0 +1,360 @@
+// Copyright (c) 2024-2024 xplshn                       [3BSD]
+// For more details refer to https://github.com/xplshn/a-utils
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/xplshn/a-utils/pkg/ccmd"
+)
+
+// message is a translatable string and the places it was found at.
+type message struct {
+	id   string
+	refs []string
+}
+
+// catalog collects messages in the order they are first found.
+type catalog struct {
+	fset     *token.FileSet
+	messages []*message
+	byID     map[string]*message
+}
+
+// verbs matches the verbs of format strings.
+var verbs = regexp.MustCompile(`%[-+# 0-9.*\[\]]*[a-zA-Z%]`)
+
+// add records the string value of expr, if it is a constant string with words to translate.
+func (c *catalog) add(expr ast.Expr) {
+	id, ok := stringValue(expr)
+	if !ok || !strings.ContainsFunc(verbs.ReplaceAllString(id, ""), unicode.IsLetter) {
+		return
+	}
+	pos := c.fset.Position(expr.Pos())
+	ref := fmt.Sprintf("%s:%d", filepath.ToSlash(pos.Filename), pos.Line)
+	if m, ok := c.byID[id]; ok {
+		m.refs = append(m.refs, ref)
+		return
+	}
+	m := &message{id: id, refs: []string{ref}}
+	c.byID[id] = m
+	c.messages = append(c.messages, m)
+}
+
+// stringValue returns the value of a string literal, or of a concatenation of string literals.
+func stringValue(expr ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.STRING {
+			return "", false
+		}
+		s, err := strconv.Unquote(e.Value)
+		return s, err == nil
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return "", false
+		}
+		x, ok := stringValue(e.X)
+		if !ok {
+			return "", false
+		}
+		y, ok := stringValue(e.Y)
+		return x + y, ok
+	case *ast.ParenExpr:
+		return stringValue(e.X)
+	}
+	return "", false
+}
+
+// typeName returns the name of a named type, qualified or not, e.g: "CmdInfo" for ccmd.CmdInfo.
+func typeName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	case *ast.StarExpr:
+		return typeName(e.X)
+	}
+	return ""
+}
+
+// describedTypes are the ccmd types whose Description is translated, with the position of
+// the field in unkeyed literals.
+var describedTypes = map[string]int{"Example": 0, "EnvVar": 1, "Field": 1, "Extension": 1}
+
+// literal collects the translatable fields of a composite literal of the type named name.
+func (c *catalog) literal(lit *ast.CompositeLit, name string) {
+	if i, ok := describedTypes[name]; ok {
+		for j, elt := range lit.Elts {
+			if kv, ok := elt.(*ast.KeyValueExpr); ok {
+				if typeName(kv.Key) == "Description" {
+					c.add(kv.Value)
+				}
+			} else if j == i {
+				c.add(elt)
+			}
+		}
+		return
+	}
+	if name != "CmdInfo" {
+		return
+	}
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		switch typeName(kv.Key) {
+		case "Description", "Behavior":
+			c.add(kv.Value)
+		case "ExitStatus", "CustomFields":
+			values, ok := kv.Value.(*ast.CompositeLit)
+			if !ok {
+				continue
+			}
+			for _, elt := range values.Elts {
+				entry, ok := elt.(*ast.KeyValueExpr)
+				if !ok {
+					continue
+				}
+				if typeName(kv.Key) == "CustomFields" {
+					// The title is shown without its numbering prefix, e.g: "1_Examples"
+					if title, ok := stringValue(entry.Key); ok {
+						if prefix, rest, ok := strings.Cut(title, "_"); ok {
+							if _, err := strconv.Atoi(prefix); err == nil {
+								title = rest
+							}
+						}
+						c.add(&ast.BasicLit{ValuePos: entry.Key.Pos(), Kind: token.STRING, Value: strconv.Quote(title)})
+					}
+				}
+				c.add(entry.Value)
+			}
+		}
+	}
+}
+
+// usageArg gives, for the methods defining flags, the position of the usage among their arguments.
+var usageArg = map[string]int{
+	"Bool": 2, "String": 2, "Int": 2, "Int64": 2, "Uint": 2, "Uint64": 2, "Float64": 2, "Duration": 2,
+	"BoolVar": 3, "StringVar": 3, "IntVar": 3, "Int64Var": 3, "UintVar": 3, "Uint64Var": 3, "Float64Var": 3, "DurationVar": 3,
+	"TextVar": 3, "Var": 2, "Func": 1, "BoolFunc": 1,
+}
+
+// formatArg gives, for the ccmd functions whose message is translated, the position of the message.
+var formatArg = map[string]int{"T": 0, "N": 0, "Warnf": 0, "Fatalf": 0, "Exitf": 1}
+
+// call collects the flag usages and the messages of ccmd functions.
+func (c *catalog) call(call *ast.CallExpr) {
+	var name string
+	var qualified bool
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		name = fun.Name
+	case *ast.SelectorExpr:
+		name = fun.Sel.Name
+		x, ok := fun.X.(*ast.Ident)
+		qualified = !ok || x.Name != "ccmd"
+	default:
+		return
+	}
+
+	if i, ok := formatArg[name]; ok && !qualified && i < len(call.Args) {
+		c.add(call.Args[i])
+		return
+	}
+	// Flag definitions are methods, or functions of the flag package, named by a constant
+	i, ok := usageArg[name]
+	if !ok || !qualified || len(call.Args) != i+1 {
+		return
+	}
+	nameArg := 0
+	if strings.HasSuffix(name, "Var") {
+		nameArg = 1
+	}
+	if _, ok := stringValue(call.Args[nameArg]); ok {
+		c.add(call.Args[i])
+	}
+}
+
+// extract collects the messages of the Go file at path, test files aside.
+func (c *catalog) extract(path string) error {
+	f, err := parser.ParseFile(c.fset, path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return err
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CompositeLit:
+			if array, ok := n.Type.(*ast.ArrayType); ok {
+				// Elements of []ccmd.Example{...} and the like elide their type
+				for _, elt := range n.Elts {
+					if lit, ok := elt.(*ast.CompositeLit); ok && lit.Type == nil {
+						c.literal(lit, typeName(array.Elt))
+					}
+				}
+			} else {
+				c.literal(n, typeName(n.Type))
+			}
+		case *ast.CallExpr:
+			c.call(n)
+		}
+		return true
+	})
+	return nil
+}
+
+// files returns the Go files, test files aside, of the files and directories in paths.
+// "DIR/..." includes the subdirectories of DIR, testdata aside.
+func files(paths []string) ([]string, error) {
+	var out []string
+	isSource := func(name string) bool {
+		return strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go")
+	}
+	for _, p := range paths {
+		if dir, ok := strings.CutSuffix(p, "/..."); ok {
+			err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if d.IsDir() && path != dir && (d.Name() == "testdata" || strings.HasPrefix(d.Name(), ".")) {
+					return filepath.SkipDir
+				}
+				if !d.IsDir() && isSource(d.Name()) {
+					out = append(out, path)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		fi, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !fi.IsDir() {
+			out = append(out, p)
+			continue
+		}
+		entries, err := os.ReadDir(p)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if !e.IsDir() && isSource(e.Name()) {
+				out = append(out, filepath.Join(p, e.Name()))
+			}
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// poString quotes s for a PO file, splitting it after its newlines as msgmerge does.
+func poString(keyword, s string) string {
+	lines := strings.SplitAfter(s, "\n")
+	if len(lines) > 1 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 1 {
+		return keyword + " " + strconv.Quote(s) + "\n"
+	}
+	var sb strings.Builder
+	sb.WriteString(keyword + " \"\"\n")
+	for _, line := range lines {
+		sb.WriteString(strconv.Quote(line) + "\n")
+	}
+	return sb.String()
+}
+
+// write writes the messages as a PO template.
+func (c *catalog) write(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("# Translatable messages, extracted by ccmd-xgettext.\n")
+	sb.WriteString("msgid \"\"\nmsgstr \"\"\n\"Content-Type: text/plain; charset=UTF-8\\n\"\n")
+	for _, m := range c.messages {
+		sb.WriteString("\n#: " + strings.Join(m.refs, " ") + "\n")
+		if strings.Contains(m.id, "%") {
+			sb.WriteString("#, c-format\n")
+		}
+		sb.WriteString(poString("msgid", m.id))
+		sb.WriteString("msgstr \"\"\n")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func main() {
+	fs := ccmd.NewFlagSet("ccmd-xgettext", flag.ExitOnError)
+	cmdInfo := &ccmd.CmdInfo{
+		Authors:     []string{"xplshn"},
+		Repository:  "https://github.com/xplshn/a-utils",
+		Name:        "ccmd-xgettext",
+		Synopsis:    "[-o FILE] <PATH/s|DIR/...>",
+		Description: "Extracts the translatable strings of commands built with ccmd into a PO template",
+		Behavior: "Strings are taken from CmdInfo literals (Description, Behavior, Examples, EnvVars, Extensions, " +
+			"ExitStatus, CustomFields and Fields), from the usages of flag definitions and from the messages of ccmd.T, ccmd.N, " +
+			"ccmd.Warnf, ccmd.Exitf and ccmd.Fatalf, as long as they are constants. Test files are skipped. " +
+			"Translations are then written in a copy of the template named after their locale, e.g: \"es.po\", " +
+			"embedded in the command and added with ccmd.AddCatalogs.",
+		Examples: []ccmd.Example{
+			{Description: "Update the template of the messages of ccmd", Command: "ccmd-xgettext -o pkg/ccmd/locale/ccmd.pot pkg/ccmd"},
+			{Description: "Merge the new messages into a translation", Command: "msgmerge -U pkg/ccmd/locale/es.po pkg/ccmd/locale/ccmd.pot"},
+		},
+		SeeAlso: []string{"msgmerge(1)", "msgfmt(1)"},
+		Flags:   fs,
+	}
+	output := fs.String("o", "", "Write the template to FILE instead of stdout")
+
+	fs.Usage = func() {
+		helpPage, err := cmdInfo.GenerateHelpPage()
+		if err != nil {
+			ccmd.Fatalf("generating help page: %v", err)
+		}
+		fmt.Print(helpPage)
+	}
+	cmdInfo.Parse()
+	if fs.NArg() == 0 {
+		fs.Usage()
+		ccmd.Exit(ccmd.ExitUsage)
+	}
+
+	paths, err := files(fs.Args())
+	if err != nil {
+		ccmd.Fatalf("%v", err)
+	}
+	c := &catalog{fset: token.NewFileSet(), byID: make(map[string]*message)}
+	for _, path := range paths {
+		if err := c.extract(path); err != nil {
+			ccmd.Fatalf("%v", err)
+		}
+	}
+
+	w := io.Writer(os.Stdout)
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			ccmd.Fatalf("%v", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := c.write(w); err != nil {
+		ccmd.Fatalf("%v", err)
+	}
+}
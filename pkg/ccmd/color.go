@@ -0,0 +1,239 @@
+package ccmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Output of commands should be pretty, but special attributes must not be used when it is
+// being captured or redirected. Color implements that policy for an output stream:
+//
+//   - --color=never, or a non-empty NO_COLOR, disables colors
+//   - --color=always, or a CLICOLOR_FORCE other than "0", enables them
+//   - otherwise (--color=auto), colors are used when the stream is a terminal whose TERM isn't "dumb"
+//
+// The number of colors available is read from terminfo, COLORTERM and TERM.
+
+// SGR sequences understood by every color capable terminal.
+const (
+	Reset     = "\033[0m"
+	Bold      = "\033[1m"
+	Dim       = "\033[2m"
+	Underline = "\033[4m"
+	Blink     = "\033[5m"
+	Reverse   = "\033[7m"
+
+	FgBlack   = "\033[30m"
+	FgRed     = "\033[31m"
+	FgGreen   = "\033[32m"
+	FgYellow  = "\033[33m"
+	FgBlue    = "\033[34m"
+	FgMagenta = "\033[35m"
+	FgCyan    = "\033[36m"
+	FgWhite   = "\033[37m"
+	FgDefault = "\033[39m"
+
+	BgRed = "\033[41m"
+
+	FgBrightBlue = "\033[94m"
+)
+
+// ColorMode is the value of a --color flag. It implements flag.Value.
+type ColorMode int
+
+const (
+	ColorAuto ColorMode = iota
+	ColorAlways
+	ColorNever
+)
+
+// ColorModes are the accepted values of a --color flag.
+var ColorModes = []string{"always", "auto", "never"}
+
+func (m ColorMode) String() string {
+	switch m {
+	case ColorAlways:
+		return "always"
+	case ColorNever:
+		return "never"
+	}
+	return "auto"
+}
+
+// Set parses "always", "auto" or "never".
+func (m *ColorMode) Set(s string) error {
+	switch s {
+	case "always":
+		*m = ColorAlways
+	case "auto":
+		*m = ColorAuto
+	case "never":
+		*m = ColorNever
+	default:
+		return fmt.Errorf("invalid color mode %q, use always, auto or never", s)
+	}
+	return nil
+}
+
+// Choices lets completion scripts offer the accepted values.
+func (m *ColorMode) Choices() []string {
+	return ColorModes
+}
+
+// ColorLevel is the amount of colors an output supports.
+type ColorLevel int
+
+const (
+	NoColors ColorLevel = iota
+	Colors8
+	Colors16
+	Colors256
+	TrueColor
+)
+
+// Color tells whether, and how much, an output stream may be colored.
+type Color struct {
+	Level ColorLevel
+}
+
+// NewColor applies the color policy to f, for the given --color mode.
+func NewColor(f *os.File, mode ColorMode) *Color {
+	switch {
+	case mode == ColorNever:
+		return &Color{}
+	case mode == ColorAlways, forceColor():
+		return &Color{Level: max(TerminalColors(), Colors8)}
+	case os.Getenv("NO_COLOR") != "", !IsTerminal(f), os.Getenv("TERM") == "dumb":
+		return &Color{}
+	}
+	return &Color{Level: TerminalColors()}
+}
+
+// StdoutColor applies the color policy to os.Stdout.
+func StdoutColor(mode ColorMode) *Color {
+	return NewColor(os.Stdout, mode)
+}
+
+// forceColor reports whether CLICOLOR_FORCE asks for colors even when not on a terminal.
+func forceColor() bool {
+	v := os.Getenv("CLICOLOR_FORCE")
+	return v != "" && v != "0" && os.Getenv("NO_COLOR") == ""
+}
+
+// IsTerminal reports whether f is a terminal.
+func IsTerminal(f *os.File) bool {
+	return f != nil && term.IsTerminal(int(f.Fd()))
+}
+
+// Enabled reports whether any special attribute may be written.
+func (c *Color) Enabled() bool {
+	return c != nil && c.Level > NoColors
+}
+
+// Paint wraps s in the given attributes and a Reset, if colors are enabled.
+func (c *Color) Paint(s string, attrs ...string) string {
+	if !c.Enabled() || len(attrs) == 0 {
+		return s
+	}
+	return strings.Join(attrs, "") + s + Reset
+}
+
+// Fg256 returns the sequence for the foreground color n of the 256 color palette,
+// degraded to the closest basic color when the output doesn't support it.
+func (c *Color) Fg256(n uint8) string {
+	if !c.Enabled() {
+		return ""
+	}
+	if c.Level >= Colors256 {
+		return fmt.Sprintf("\033[38;5;%dm", n)
+	}
+	if n < 16 && c.Level >= Colors16 {
+		return basicFg(int(n))
+	}
+	r, g, b := paletteRGB(n)
+	return c.basicRGB(r, g, b)
+}
+
+// FgRGB returns the sequence for a 24-bit foreground color, degraded to the 256 color palette,
+// or to the closest basic color, when needed.
+func (c *Color) FgRGB(r, g, b uint8) string {
+	if !c.Enabled() {
+		return ""
+	}
+	switch {
+	case c.Level >= TrueColor:
+		return fmt.Sprintf("\033[38;2;%d;%d;%dm", r, g, b)
+	case c.Level >= Colors256:
+		// Nearest entry of the 6x6x6 color cube
+		cube := func(v uint8) int { return (int(v)*5 + 127) / 255 }
+		return c.Fg256(uint8(16 + 36*cube(r) + 6*cube(g) + cube(b)))
+	}
+	return c.basicRGB(r, g, b)
+}
+
+// basicPalette holds the usual (xterm) values of the 16 basic colors.
+var basicPalette = [16][3]uint8{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// paletteRGB returns the components of the color n of the 256 color palette: the basic
+// colors, the 6x6x6 color cube, then the grey ramp.
+func paletteRGB(n uint8) (r, g, b uint8) {
+	switch {
+	case n < 16:
+		return basicPalette[n][0], basicPalette[n][1], basicPalette[n][2]
+	case n >= 232:
+		v := 8 + 10*(n-232)
+		return v, v, v
+	}
+	level := func(i uint8) uint8 {
+		if i == 0 {
+			return 0
+		}
+		return 55 + 40*i
+	}
+	n -= 16
+	return level(n / 36), level(n / 6 % 6), level(n % 6)
+}
+
+// basicRGB returns the sequence for the basic color closest to r, g, b among those the output supports.
+func (c *Color) basicRGB(r, g, b uint8) string {
+	count := 8
+	if c.Level >= Colors16 {
+		count = 16
+	}
+	best, bestDist := 0, -1
+	for i, p := range basicPalette[:count] {
+		dr, dg, db := int(r)-int(p[0]), int(g)-int(p[1]), int(b)-int(p[2])
+		if d := dr*dr + dg*dg + db*db; bestDist < 0 || d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return basicFg(best)
+}
+
+// basicFg returns the sequence for the basic color n, from 0 to 15.
+func basicFg(n int) string {
+	return fmt.Sprintf("\033[%dm", 30+n%8+60*(n/8))
+}
+
+// ChromaFormatter returns the name of the chroma formatter matching the color level.
+func (c *Color) ChromaFormatter() string {
+	switch {
+	case !c.Enabled():
+		return "noop"
+	case c.Level >= TrueColor:
+		return "terminal16m"
+	case c.Level >= Colors256:
+		return "terminal256"
+	case c.Level >= Colors16:
+		return "terminal16"
+	}
+	return "terminal8"
+}
@@ -0,0 +1,76 @@
+package ccmd
+
+import (
+	"os"
+	"testing"
+)
+
+func TestNewColor(t *testing.T) {
+	// A regular file is never a terminal
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	for _, tt := range []struct {
+		name        string
+		mode        ColorMode
+		noColor     string
+		forceColor  string
+		wantEnabled bool
+	}{
+		{"auto on a file", ColorAuto, "", "", false},
+		{"always on a file", ColorAlways, "", "", true},
+		{"never", ColorNever, "", "1", false},
+		{"CLICOLOR_FORCE", ColorAuto, "", "1", true},
+		{"CLICOLOR_FORCE=0", ColorAuto, "", "0", false},
+		{"NO_COLOR wins over CLICOLOR_FORCE", ColorAuto, "1", "1", false},
+		{"always wins over NO_COLOR", ColorAlways, "1", "", true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tt.noColor)
+			t.Setenv("CLICOLOR_FORCE", tt.forceColor)
+			if got := NewColor(f, tt.mode).Enabled(); got != tt.wantEnabled {
+				t.Errorf("NewColor(%v).Enabled() = %v, want %v", tt.mode, got, tt.wantEnabled)
+			}
+		})
+	}
+}
+
+func TestColorDegrades(t *testing.T) {
+	c := &Color{Level: Colors8}
+	if got, want := c.Fg256(9), "\033[31m"; got != want {
+		t.Errorf("Fg256(9) with 8 colors = %q, want %q", got, want)
+	}
+	// The colors of the cube and the grey ramp fall back to the closest basic color
+	for _, tt := range []struct {
+		level ColorLevel
+		n     uint8
+		want  string
+	}{
+		{Colors8, 196, "\033[31m"},
+		{Colors16, 196, "\033[91m"},
+		{Colors8, 21, "\033[34m"},
+		{Colors16, 46, "\033[92m"},
+		{Colors8, 232, "\033[30m"},
+		{Colors8, 255, "\033[37m"},
+		{Colors16, 244, "\033[90m"},
+	} {
+		c.Level = tt.level
+		if got := c.Fg256(tt.n); got != tt.want {
+			t.Errorf("Fg256(%d) with level %d = %q, want %q", tt.n, tt.level, got, tt.want)
+		}
+	}
+	c.Level = Colors16
+	if got, want := c.FgRGB(0, 180, 190), "\033[36m"; got != want {
+		t.Errorf("FgRGB(0, 180, 190) with 16 colors = %q, want %q", got, want)
+	}
+	c.Level = Colors256
+	if got, want := c.FgRGB(255, 0, 0), "\033[38;5;196m"; got != want {
+		t.Errorf("FgRGB(255, 0, 0) with 256 colors = %q, want %q", got, want)
+	}
+	if got := (&Color{}).Paint("x", Bold); got != "x" {
+		t.Errorf("Paint without colors = %q, want %q", got, "x")
+	}
+}
@@ -0,0 +1,256 @@
+package ccmd
+
+import (
+	"flag"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Completion describes what the value of a flag can be completed to.
+type Completion struct {
+	Files   bool            // The value is a path to a file
+	Dirs    bool            // The value is a path to a directory
+	Choices []string        // The value is one of these
+	Dynamic func() []string // The value is one of these, computed by the command itself when completing
+}
+
+// completionFlag is a flag as seen by the completion generators.
+type completionFlag struct {
+	names []string // every name of the flag, shortest first
+	usage string
+	bool  bool
+	comp  Completion
+}
+
+// rxFileOperands guesses from the synopsis whether the operands of a command are paths.
+var rxFileOperands = regexp.MustCompile(`(?i)file|path|dir|target`)
+
+// completionFlags gathers the visible flags of the command along with their completion hints.
+func (ci *CmdInfo) completionFlags() []completionFlag {
+	var flags []completionFlag
+	fs := ci.flagSet()
+	fs.VisitAll(func(f *flag.Flag) {
+		if ci.ExcludeFlags[f.Name] || fs.IsAlias(f.Name) || fs.IsHidden(f.Name) {
+			return
+		}
+		cf := completionFlag{
+			names: fs.Aliases(f.Name),
+			usage: f.Usage,
+			bool:  isBoolFlag(f),
+		}
+		// Values that know what they accept, like ColorMode, complete their choices
+		if choices, ok := f.Value.(interface{ Choices() []string }); ok {
+			cf.comp.Choices = choices.Choices()
+		}
+		for _, name := range cf.names {
+			if comp, ok := ci.Completions[name]; ok {
+				cf.comp = comp
+			}
+		}
+		flags = append(flags, cf)
+	})
+	return flags
+}
+
+// fileOperands reports whether the operands of the command should be completed as paths.
+func (ci *CmdInfo) fileOperands() bool {
+	synopsis := ci.Synopsis
+	if synopsis == "" {
+		synopsis = ci.Usage
+	}
+	return rxFileOperands.MatchString(synopsis)
+}
+
+// completionIdent turns a command name into something usable as a shell function name.
+func completionIdent(name string) string {
+	return regexp.MustCompile(`[^A-Za-z0-9_]`).ReplaceAllString(name, "_")
+}
+
+// shellQuote single-quotes s for sh-like shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// GenerateCompletion creates a completion script for the given shell (bash, zsh or fish).
+func (ci *CmdInfo) GenerateCompletion(shell string) (string, error) {
+	if ci.Name == "" {
+		return "", fmt.Errorf("Name must be set")
+	}
+	switch shell {
+	case "bash":
+		return ci.bashCompletion(), nil
+	case "zsh":
+		return ci.zshCompletion(), nil
+	case "fish":
+		return ci.fishCompletion(), nil
+	}
+	return "", fmt.Errorf("unsupported shell %q, use bash, zsh or fish", shell)
+}
+
+// CompletionValues returns the values the flag name can take, as used by the generated scripts.
+// name may be any name of the flag, its Completion being found under any of them, like
+// completionFlags does.
+func (ci *CmdInfo) CompletionValues(name string) ([]string, error) {
+	var comp Completion
+	ok := false
+	for _, alias := range ci.flagSet().Aliases(name) {
+		if c, found := ci.Completions[alias]; found {
+			comp, ok = c, true
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("no completion for flag %q", name)
+	}
+	values := append([]string{}, comp.Choices...)
+	if comp.Dynamic != nil {
+		values = append(values, comp.Dynamic()...)
+	}
+	sort.Strings(values)
+	return values, nil
+}
+
+func (ci *CmdInfo) bashCompletion() string {
+	sb := &strings.Builder{}
+	fn := "_a_utils_" + completionIdent(ci.Name)
+
+	sb.WriteString(fmt.Sprintf("# bash completion for %s\n", ci.Name))
+	sb.WriteString(ci.completionComment())
+	sb.WriteString(fn + "() {\n")
+	sb.WriteString("\tlocal cur prev\n")
+	sb.WriteString("\tcur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	sb.WriteString("\tprev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	sb.WriteString("\t# --flag=value is split by COMP_WORDBREAKS\n")
+	sb.WriteString("\tif [ \"$prev\" = \"=\" ]; then\n\t\tprev=\"${COMP_WORDS[COMP_CWORD-2]}\"\n\tfi\n")
+	sb.WriteString("\tif [ \"$cur\" = \"=\" ]; then\n\t\tcur=\"\"\n\tfi\n")
+
+	var words []string
+	sb.WriteString("\tcase \"$prev\" in\n")
+	for _, f := range ci.completionFlags() {
+		var pattern []string
+		for _, name := range f.names {
+			pattern = append(pattern, flagPrefix(name)+name)
+		}
+		words = append(words, pattern...)
+		if f.bool {
+			continue
+		}
+		sb.WriteString("\t" + strings.Join(pattern, "|") + ")\n")
+		switch {
+		case f.comp.Dirs:
+			sb.WriteString("\t\tCOMPREPLY=($(compgen -d -- \"$cur\"))\n")
+		case f.comp.Files:
+			sb.WriteString("\t\tCOMPREPLY=($(compgen -f -- \"$cur\"))\n")
+		case f.comp.Dynamic != nil:
+			sb.WriteString(fmt.Sprintf("\t\tCOMPREPLY=($(compgen -W \"$(%s --completion-values=%s 2>/dev/null)\" -- \"$cur\"))\n", ci.Name, f.names[len(f.names)-1]))
+		case len(f.comp.Choices) > 0:
+			sb.WriteString(fmt.Sprintf("\t\tCOMPREPLY=($(compgen -W %s -- \"$cur\"))\n", shellQuote(strings.Join(f.comp.Choices, " "))))
+		default:
+			sb.WriteString("\t\tCOMPREPLY=()\n")
+		}
+		sb.WriteString("\t\treturn\n\t\t;;\n")
+	}
+	sb.WriteString("\tesac\n")
+
+	sb.WriteString("\tcase \"$cur\" in\n")
+	sb.WriteString(fmt.Sprintf("\t-*)\n\t\tCOMPREPLY=($(compgen -W %s -- \"$cur\"))\n\t\treturn\n\t\t;;\n", shellQuote(strings.Join(words, " "))))
+	sb.WriteString("\tesac\n")
+	if ci.fileOperands() {
+		sb.WriteString("\tCOMPREPLY=($(compgen -f -- \"$cur\"))\n")
+	}
+	sb.WriteString("}\n")
+	sb.WriteString(fmt.Sprintf("complete -F %s %s\n", fn, ci.Name))
+	return sb.String()
+}
+
+// zshEscape escapes the characters _arguments gives a meaning to in descriptions.
+func zshEscape(s string) string {
+	s = strings.NewReplacer(`[`, `\[`, `]`, `\]`, `:`, `\:`).Replace(s)
+	return strings.ReplaceAll(s, "'", `'\''`)
+}
+
+func (ci *CmdInfo) zshCompletion() string {
+	sb := &strings.Builder{}
+	sb.WriteString(fmt.Sprintf("#compdef %s\n", ci.Name))
+	sb.WriteString(ci.completionComment() + "\n")
+	sb.WriteString("_arguments -s \\\n")
+	for _, f := range ci.completionFlags() {
+		var action string
+		if !f.bool {
+			switch {
+			case f.comp.Dirs:
+				action = ":dir:_files -/"
+			case f.comp.Files:
+				action = ":file:_files"
+			case f.comp.Dynamic != nil:
+				action = fmt.Sprintf(`:value:{compadd -- ${(f)"$(%s --completion-values=%s 2>/dev/null)"}}`, ci.Name, f.names[len(f.names)-1])
+			case len(f.comp.Choices) > 0:
+				action = ":value:(" + strings.Join(f.comp.Choices, " ") + ")"
+			default:
+				action = ":value: "
+			}
+		}
+		var exclusion []string
+		for _, name := range f.names {
+			exclusion = append(exclusion, flagPrefix(name)+name)
+		}
+		for _, name := range f.names {
+			spec := flagPrefix(name) + name
+			if !f.bool {
+				// Value may be attached ("-p>", "--color=auto") or in the next word
+				if len(name) == 1 {
+					spec += "+"
+				} else {
+					spec += "="
+				}
+			}
+			if len(f.names) > 1 {
+				spec = "(" + strings.Join(exclusion, " ") + ")" + spec
+			}
+			sb.WriteString(fmt.Sprintf("\t'%s[%s]%s' \\\n", spec, zshEscape(f.usage), strings.ReplaceAll(action, "'", `'\''`)))
+		}
+	}
+	if ci.fileOperands() {
+		sb.WriteString("\t'*:file:_files'\n")
+	} else {
+		sb.WriteString("\t'*: :'\n")
+	}
+	return sb.String()
+}
+
+func (ci *CmdInfo) fishCompletion() string {
+	sb := &strings.Builder{}
+	sb.WriteString(fmt.Sprintf("# fish completion for %s\n", ci.Name))
+	sb.WriteString(ci.completionComment())
+	if !ci.fileOperands() {
+		sb.WriteString(fmt.Sprintf("complete -c %s -f\n", ci.Name))
+	}
+	for _, f := range ci.completionFlags() {
+		line := "complete -c " + ci.Name
+		for _, name := range f.names {
+			switch {
+			case len(name) == 1:
+				line += " -s " + name
+			default:
+				line += " -l " + name
+			}
+		}
+		if !f.bool {
+			line += " -r"
+			switch {
+			case f.comp.Dirs:
+				line += " -f -a '(__fish_complete_directories)'"
+			case f.comp.Files:
+				line += " -F"
+			case f.comp.Dynamic != nil:
+				line += fmt.Sprintf(" -f -a '(%s --completion-values=%s 2>/dev/null)'", ci.Name, f.names[len(f.names)-1])
+			case len(f.comp.Choices) > 0:
+				line += " -f -a " + shellQuote(strings.Join(f.comp.Choices, " "))
+			}
+		}
+		line += " -d " + shellQuote(f.usage)
+		sb.WriteString(line + "\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,57 @@
+package ccmd
+
+import (
+	"flag"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+// rxCompletionValues finds the flag the generated scripts ask the values of
+var rxCompletionValues = regexp.MustCompile(`--completion-values=([^ )]+)`)
+
+func TestCompletionValues(t *testing.T) {
+	fs := NewFlagSet("demo", flag.ContinueOnError)
+	fs.String("s", "", "Style")
+	fs.Alias("style", "s")
+	fs.String("t", "", "Theme")
+	fs.Alias("theme", "t")
+	ci := &CmdInfo{
+		Name:     "demo",
+		Synopsis: "[-s STYLE] [-t THEME] [FILE...]",
+		Completions: map[string]Completion{
+			"s":     {Dynamic: func() []string { return []string{"monokai", "dracula"} }},
+			"theme": {Choices: []string{"light"}, Dynamic: func() []string { return []string{"dark"} }},
+		},
+		Flags: fs,
+	}
+	want := map[string][]string{
+		"s": {"dracula", "monokai"}, "style": {"dracula", "monokai"},
+		"t": {"dark", "light"}, "theme": {"dark", "light"},
+	}
+
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		script, err := ci.GenerateCompletion(shell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		matches := rxCompletionValues.FindAllStringSubmatch(script, -1)
+		if len(matches) == 0 {
+			t.Errorf("the %s script doesn't ask for the values of flags:\n%s", shell, script)
+		}
+		for _, m := range matches {
+			got, err := ci.CompletionValues(m[1])
+			if err != nil || !reflect.DeepEqual(got, want[m[1]]) {
+				t.Errorf("%s: CompletionValues(%q) = %q, %v, want %q", shell, m[1], got, err, want[m[1]])
+			}
+		}
+	}
+	for name, values := range want {
+		if got, err := ci.CompletionValues(name); err != nil || !reflect.DeepEqual(got, values) {
+			t.Errorf("CompletionValues(%q) = %q, %v, want %q", name, got, err, values)
+		}
+	}
+	if _, err := ci.CompletionValues("x"); err == nil {
+		t.Errorf("CompletionValues(\"x\") = nil error, want one")
+	}
+}
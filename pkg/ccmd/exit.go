@@ -0,0 +1,143 @@
+package ccmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Diagnostics are written to stderr, prefixed with the name of the command ("fin: ..."), and
+// commands exit with one of the statuses below, which help pages document. Commands may give
+// other statuses a meaning through CmdInfo.ExitStatus, e.g: test exits with 1 when false.
+
+// ExitCode is the exit status of a command.
+type ExitCode int
+
+const (
+	ExitSuccess    ExitCode = 0
+	ExitFailure    ExitCode = 1 // Any other error
+	ExitUsage      ExitCode = 2 // Bad flags or operands
+	ExitNotFound   ExitCode = 3 // A file or other resource doesn't exist
+	ExitPermission ExitCode = 4 // Permission denied
+
+	ExitInterrupted ExitCode = 130 // Interrupted by SIGINT, see Context
+)
+
+// exitStatuses documents the standard exit statuses in help and man pages.
+var exitStatuses = map[int]string{
+	int(ExitSuccess):     N("Success"),
+	int(ExitFailure):     N("Failure"),
+	int(ExitUsage):       N("Invalid usage: bad flags or operands"),
+	int(ExitNotFound):    N("A file or other resource doesn't exist"),
+	int(ExitPermission):  N("Permission denied"),
+	int(ExitInterrupted): N("Interrupted (128+N when terminated by signal N)"),
+}
+
+// ExitCodeOf returns the exit status that reports err: ExitNotFound for fs.ErrNotExist,
+// ExitPermission for fs.ErrPermission, ExitUsage for an *ExtensionError, that of the signal
+// for a *SignalError or an error caused by the cancellation of Context, ExitFailure
+// otherwise, and ExitSuccess for nil.
+func ExitCodeOf(err error) ExitCode {
+	var sigErr *SignalError
+	var extErr *ExtensionError
+	switch {
+	case err == nil:
+		return ExitSuccess
+	case errors.As(err, &extErr):
+		return ExitUsage
+	case errors.As(err, &sigErr):
+		return sigErr.ExitCode()
+	case errors.Is(err, context.Canceled) && signalCtx != nil && signalCtx.Err() != nil:
+		return ExitCodeOf(context.Cause(signalCtx))
+	case errors.Is(err, fs.ErrNotExist):
+		return ExitNotFound
+	case errors.Is(err, fs.ErrPermission):
+		return ExitPermission
+	}
+	return ExitFailure
+}
+
+// Stdout and Stderr are where the output of ccmd (help pages, completion scripts, ...) and
+// diagnostics are written, os.Stdout and os.Stderr if nil. Run sets them to the Stdio of the command.
+var Stdout, Stderr io.Writer
+
+func stdout() io.Writer {
+	if Stdout == nil {
+		return os.Stdout
+	}
+	return Stdout
+}
+
+func stderr() io.Writer {
+	if Stderr == nil {
+		return os.Stderr
+	}
+	return Stderr
+}
+
+// progName prefixes diagnostics. CmdInfo.Parse sets it to the name of the command.
+var progName = filepath.Base(os.Args[0])
+
+// exit terminates the program, CatchExit replaces it while it runs.
+var exit = os.Exit
+
+// Exit terminates the program with code, after running the hooks registered with AtExit.
+func Exit(code ExitCode) {
+	runCleanups()
+	exit(int(code))
+}
+
+// Warnf writes a diagnostic to stderr, prefixed with the name of the command. format is
+// translated, see T.
+func Warnf(format string, a ...any) {
+	msg := fmt.Sprintf(format, a...)
+	if translated := T(format); translated != format {
+		msg = fmt.Sprintf(translated, a...)
+	}
+	fmt.Fprintf(stderr(), "%s: %s\n", progName, msg)
+}
+
+// Exitf writes a diagnostic like Warnf, then exits with code.
+func Exitf(code ExitCode, format string, a ...any) {
+	Warnf(format, a...)
+	Exit(code)
+}
+
+// Fatalf writes a diagnostic like Warnf, then exits with the status of the first error among
+// a, see ExitCodeOf, or ExitFailure if there is none.
+func Fatalf(format string, a ...any) {
+	code := ExitFailure
+	for _, arg := range a {
+		if err, ok := arg.(error); ok {
+			code = max(ExitCodeOf(err), ExitFailure)
+			break
+		}
+	}
+	Exitf(code, format, a...)
+}
+
+// exitPanic carries the status of an Exit caught by CatchExit.
+type exitPanic struct{ code int }
+
+// CatchExit runs f and returns the status it exits with through Exit, Exitf or Fatalf,
+// or -1 if f returns. It is meant for tests, and is not safe for concurrent use.
+func CatchExit(f func()) (code int) {
+	saved := exit
+	exit = func(code int) { panic(exitPanic{code}) }
+	defer func() {
+		exit = saved
+		if r := recover(); r != nil {
+			p, ok := r.(exitPanic)
+			if !ok {
+				panic(r)
+			}
+			code = p.code
+		}
+	}()
+	f()
+	return -1
+}
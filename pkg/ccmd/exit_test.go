@@ -0,0 +1,35 @@
+package ccmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestExit(t *testing.T) {
+	var stderr strings.Builder
+	savedName := progName
+	Stderr, progName = &stderr, "demo"
+	defer func() { Stderr, progName = nil, savedName }()
+
+	_, errNotExist := os.Open("/nonexistent/file")
+	for _, tt := range []struct {
+		f    func()
+		code int
+		msg  string
+	}{
+		{func() {}, -1, ""},
+		{func() { Warnf("just %s", "saying") }, -1, "demo: just saying\n"},
+		{func() { Fatalf("oops") }, 1, "demo: oops\n"},
+		{func() { Fatalf("%v", errNotExist) }, 3, "demo: open /nonexistent/file: no such file or directory\n"},
+		{func() { Fatalf("reading: %v", fmt.Errorf("wrapped: %w", fs.ErrPermission)) }, 4, "demo: reading: wrapped: permission denied\n"},
+		{func() { Exitf(ExitUsage, "bad operand %q", "x") }, 2, "demo: bad operand \"x\"\n"},
+	} {
+		stderr.Reset()
+		if code := CatchExit(tt.f); code != tt.code || stderr.String() != tt.msg {
+			t.Errorf("got status %d and %q, want %d and %q", code, stderr.String(), tt.code, tt.msg)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package ccmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// Flag files hold arguments, as they would be typed on a shell, one or more per line.
+// They are used through "@path" arguments and through the default flag file of a command,
+// whose contents are prepended to its arguments. Lines starting with '#' are comments.
+//
+//	# ~/.config/a-utils/fin.flags
+//	--color=auto
+//	-h
+
+// maxFlagFiles bounds how many "@path" arguments are expanded, so flag files can't include each other forever.
+const maxFlagFiles = 64
+
+// FlagFilePath returns the path of the default flag file of the command name:
+// $XDG_CONFIG_HOME/a-utils/<name>.flags, or ~/.config/a-utils/<name>.flags.
+func FlagFilePath(name string) string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "a-utils", name+".flags")
+}
+
+// ReadFlagFile reads the arguments stored in the flag file at path.
+func ReadFlagFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	args, err := FileToArgv(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return args, nil
+}
+
+// FileToArgv splits the contents of a flag file into arguments. Words are separated by
+// blanks; single quotes, double quotes and backslashes work as in sh(1), and '#' starts
+// a comment when it begins a word.
+func FileToArgv(content string) ([]string, error) {
+	var (
+		args   []string
+		word   strings.Builder
+		inWord bool
+	)
+	runes := []rune(content)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			if inWord {
+				args = append(args, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case r == '#' && !inWord:
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '\\':
+			inWord = true
+			if i+1 < len(runes) {
+				i++
+				if runes[i] != '\n' {
+					word.WriteRune(runes[i])
+				}
+			}
+		case r == '\'':
+			inWord = true
+			for i++; i < len(runes) && runes[i] != '\''; i++ {
+				word.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+		case r == '"':
+			inWord = true
+			for i++; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`\n", runes[i+1]) {
+					i++
+					if runes[i] == '\n' {
+						continue
+					}
+				}
+				word.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated double quote")
+			}
+		default:
+			inWord = true
+			word.WriteRune(r)
+		}
+	}
+	if inWord {
+		args = append(args, word.String())
+	}
+	return args, nil
+}
+
+// ArgvToFile encodes args so that FileToArgv reads them back, one argument per line.
+func ArgvToFile(args []string) string {
+	var sb strings.Builder
+	for _, arg := range args {
+		if arg != "" && !strings.ContainsAny(arg, " \t\n'\"\\#") {
+			sb.WriteString(arg + "\n")
+			continue
+		}
+		sb.WriteString(shellQuote(arg) + "\n")
+	}
+	return sb.String()
+}
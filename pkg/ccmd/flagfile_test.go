@@ -0,0 +1,85 @@
+package ccmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFileToArgv(t *testing.T) {
+	for _, tt := range []struct {
+		content string
+		want    []string
+	}{
+		{"-v\n--color=auto\n", []string{"-v", "--color=auto"}},
+		{"# comment\n-p '> ' # trailing\n", []string{"-p", "> "}},
+		{`--style "my style.xml" a\ b`, []string{"--style", "my style.xml", "a b"}},
+		{`''`, []string{""}},
+	} {
+		got, err := FileToArgv(tt.content)
+		if err != nil {
+			t.Errorf("FileToArgv(%q) = %v", tt.content, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("FileToArgv(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+		if back, _ := FileToArgv(ArgvToFile(got)); !reflect.DeepEqual(back, got) {
+			t.Errorf("FileToArgv(ArgvToFile(%q)) = %q", got, back)
+		}
+	}
+	if _, err := FileToArgv(`-p 'oops`); err == nil {
+		t.Errorf("FileToArgv with an unterminated quote = nil, want error")
+	}
+}
+
+func TestFlagSetParseFlagFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.flags")
+	if err := os.WriteFile(path, []byte("-v\n--color always\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fs := newTestFlagSet()
+	if err := fs.Parse([]string{"@" + path, "-t", "op", "@file"}); err != nil {
+		t.Fatalf("Parse = %v", err)
+	}
+	for name, want := range map[string]string{"v": "true", "t": "true", "color": "always"} {
+		if got := fs.Lookup(name).Value.String(); got != want {
+			t.Errorf("flag %s = %q, want %q", name, got, want)
+		}
+	}
+	if got := fs.Args(); !reflect.DeepEqual(got, []string{"op", "@file"}) {
+		t.Errorf("Args() = %q, want operands to be left alone", got)
+	}
+}
+
+func TestParseArgsFlagFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("POSIXLY_CORRECT", "") // restored once the test is done
+	if err := os.MkdirAll(filepath.Join(dir, "a-utils"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(FlagFilePath("test"), []byte("-v\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		posix bool
+		args  []string
+		want  string
+	}{
+		{args: nil, want: "true"},
+		{args: []string{"--no-flagfile"}, want: "false"},
+		{posix: true, args: nil, want: "false"},
+	} {
+		os.Unsetenv("POSIXLY_CORRECT")
+		if tt.posix {
+			t.Setenv("POSIXLY_CORRECT", "")
+		}
+		ci := &CmdInfo{Name: "test", Flags: newTestFlagSet()}
+		ci.ParseArgs(tt.args)
+		if got := ci.Flags.Lookup("v").Value.String(); got != tt.want {
+			t.Errorf("with POSIXLY_CORRECT %v, ParseArgs(%q) sets -v from the flag file to %s, want %s", tt.posix, tt.args, got, tt.want)
+		}
+	}
+}
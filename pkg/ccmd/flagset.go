@@ -0,0 +1,338 @@
+package ccmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FlagSet wraps a flag.FlagSet, adding POSIX option clustering (-abc == -a -b -c),
+// long/short aliases (--long and -s naming the same flag) and "--" termination.
+// Flags are still registered on the embedded flag.FlagSet, so flag.Bool-style
+// definitions keep working.
+type FlagSet struct {
+	*flag.FlagSet
+	aliases    map[string]string // alias name -> canonical name
+	hidden     map[string]bool   // flags left out of help pages
+	extensions map[string]bool   // flags that aren't POSIX, see PosixlyCorrect
+}
+
+// CommandLine is the FlagSet wrapping flag.CommandLine.
+// Flags defined with the top-level functions of the flag package belong to it.
+var CommandLine = &FlagSet{
+	FlagSet:    flag.CommandLine,
+	aliases:    make(map[string]string),
+	hidden:     make(map[string]bool),
+	extensions: make(map[string]bool),
+}
+
+// NewFlagSet returns a new, empty FlagSet with the specified name and error handling property.
+func NewFlagSet(name string, errorHandling flag.ErrorHandling) *FlagSet {
+	return &FlagSet{
+		FlagSet:    flag.NewFlagSet(name, errorHandling),
+		aliases:    make(map[string]string),
+		hidden:     make(map[string]bool),
+		extensions: make(map[string]bool),
+	}
+}
+
+// Alias makes alias another name for the already defined flag name.
+// Both names share the same value; the help page shows them on a single line.
+func (f *FlagSet) Alias(alias, name string) {
+	fl := f.Lookup(name)
+	if fl == nil {
+		panic(fmt.Sprintf("ccmd: cannot alias undefined flag %q", name))
+	}
+	f.Var(fl.Value, alias, fl.Usage)
+	f.aliases[alias] = name
+}
+
+// Alias makes alias another name for the flag name of the command line.
+func Alias(alias, name string) {
+	CommandLine.Alias(alias, name)
+}
+
+// Aliases returns every name the flag is known by, shortest first.
+func (f *FlagSet) Aliases(name string) []string {
+	if canonical, ok := f.aliases[name]; ok {
+		name = canonical
+	}
+	names := []string{name}
+	for alias, canonical := range f.aliases {
+		if canonical == name {
+			names = append(names, alias)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) < len(names[j])
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// IsAlias reports whether name was defined through Alias.
+func (f *FlagSet) IsAlias(name string) bool {
+	_, ok := f.aliases[name]
+	return ok
+}
+
+// Hide keeps the flag name out of generated help and man pages.
+func (f *FlagSet) Hide(name string) {
+	f.hidden[name] = true
+}
+
+// IsHidden reports whether the flag name was hidden through Hide.
+func (f *FlagSet) IsHidden(name string) bool {
+	return f.hidden[name]
+}
+
+// Parse expands clustered short options and then parses the argument list,
+// which should not include the command name.
+func (f *FlagSet) Parse(arguments []string) error {
+	expanded, err := f.expand(arguments)
+	if err != nil {
+		fmt.Fprintln(f.Output(), err)
+		f.usage()
+		switch f.ErrorHandling() {
+		case flag.ExitOnError:
+			Exit(ExitUsage)
+		case flag.PanicOnError:
+			panic(err)
+		}
+		return err
+	}
+	return f.FlagSet.Parse(expanded)
+}
+
+// Parse parses the command-line flags from os.Args[1:].
+func Parse() {
+	// Ignore errors; CommandLine is set for ExitOnError.
+	CommandLine.Parse(os.Args[1:])
+}
+
+// Parse parses os.Args[1:], see ParseArgs.
+func (ci *CmdInfo) Parse() {
+	ci.ParseArgs(os.Args[1:])
+}
+
+// ParseArgs registers the hidden flags every command supports and parses args, which
+// should not include the command name, with the FlagSet of the command:
+//
+//	--man: print the man page of the command and exit
+//	--completion=SHELL: print a completion script for bash, zsh or fish and exit
+//	--show-flagfile: print the default flag file that was applied, and its arguments
+//	--no-flagfile: don't apply the default flag file
+//
+// The arguments in the default flag file of the command (see FlagFilePath) are
+// prepended to args, so the ones given on the command line take precedence, unless
+// POSIXLY_CORRECT is set: like @file, the flag file is an extension.
+// Invalid flags, and extension flags when POSIXLY_CORRECT is set, are reported and make
+// the command exit with ExitUsage; -h and --help print the help page and exit with ExitSuccess.
+func (ci *CmdInfo) ParseArgs(args []string) {
+	if ci.Name != "" {
+		progName = ci.Name
+	}
+	fs := ci.flagSet()
+	man := fs.Bool("man", false, "Print the man page")
+	fs.Hide("man")
+	completion := fs.String("completion", "", "Print a completion script for bash, zsh or fish")
+	fs.Hide("completion")
+	completionValues := fs.String("completion-values", "", "Print the values a flag can take, used by completion scripts")
+	fs.Hide("completion-values")
+	showFlagFile := fs.Bool("show-flagfile", false, "Print the default flag file that was applied")
+	fs.Hide("show-flagfile")
+	noFlagFile := fs.Bool("no-flagfile", false, "Don't apply the default flag file")
+	fs.Hide("no-flagfile")
+
+	flagFile, fileArgs := "", []string(nil)
+	if !hasFlag(args, "no-flagfile") && !PosixlyCorrect() {
+		if path := FlagFilePath(ci.Name); path != "" {
+			var err error
+			fileArgs, err = ReadFlagFile(path)
+			switch {
+			case err == nil:
+				flagFile = path
+				args = append(append([]string{}, fileArgs...), args...)
+			case !os.IsNotExist(err):
+				Warnf("ignoring flag file: %v", err)
+			}
+		}
+	}
+
+	fs.SetOutput(stderr())
+	if err := fs.Parse(args); err == flag.ErrHelp {
+		Exit(ExitSuccess)
+	} else if err != nil {
+		Exit(ExitUsage)
+	}
+	if err := fs.checkExtensionFlags(); err != nil {
+		Fatalf("%v", err)
+	}
+
+	if *showFlagFile {
+		if flagFile == "" || *noFlagFile {
+			fmt.Fprintln(stdout(), T("No flag file applied, it would be read from:"), FlagFilePath(ci.Name))
+		} else {
+			fmt.Fprintf(stdout(), "%s: %s\n", flagFile, strings.Join(fileArgs, " "))
+		}
+		Exit(ExitSuccess)
+	}
+
+	if *completion != "" {
+		script, err := ci.GenerateCompletion(*completion)
+		if err != nil {
+			Exitf(ExitUsage, "generating completion script: %v", err)
+		}
+		fmt.Fprint(stdout(), script)
+		Exit(ExitSuccess)
+	}
+
+	if *completionValues != "" {
+		values, err := ci.CompletionValues(*completionValues)
+		if err != nil {
+			Exitf(ExitUsage, "listing completion values: %v", err)
+		}
+		for _, value := range values {
+			fmt.Fprintln(stdout(), value)
+		}
+		Exit(ExitSuccess)
+	}
+
+	if *man {
+		page, err := ci.GenerateManPage()
+		if err != nil {
+			Fatalf("generating man page: %v", err)
+		}
+		fmt.Fprint(stdout(), page)
+		Exit(ExitSuccess)
+	}
+}
+
+// hasFlag reports whether the flag name is given in args, before any "--".
+// It is used for the flags that must be known before parsing.
+func hasFlag(args []string, name string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			return false
+		}
+		if arg == "-"+name || arg == "--"+name {
+			return true
+		}
+	}
+	return false
+}
+
+// usage calls the Usage function of the FlagSet, mimicking flag's default behaviour.
+func (f *FlagSet) usage() {
+	if f.Usage != nil {
+		f.Usage()
+		return
+	}
+	fmt.Fprintf(f.Output(), "Usage of %s:\n", filepath.Base(f.Name()))
+	f.PrintDefaults()
+}
+
+// isBoolFlag reports whether the flag does not take a value.
+func isBoolFlag(fl *flag.Flag) bool {
+	bf, ok := fl.Value.(interface{ IsBoolFlag() bool })
+	return ok && bf.IsBoolFlag()
+}
+
+// known reports whether name is a flag the underlying FlagSet will accept.
+func (f *FlagSet) known(name string) bool {
+	return f.Lookup(name) != nil || name == "h" || name == "help"
+}
+
+// expand rewrites arguments into a form the flag package understands:
+//   - "-abc" becomes "-a -b -c" unless "abc" is itself a defined flag
+//   - "-ovalue" and "-o value" become "-o=value" when o takes a value
+//   - "@path" is replaced by the arguments stored in the flag file at path, unless
+//     POSIXLY_CORRECT is set: it is then an operand like any other
+//   - parsing stops at "--", at "-" and at the first non-option argument
+func (f *FlagSet) expand(arguments []string) ([]string, error) {
+	var out []string
+	flagFiles := 0
+	for i := 0; i < len(arguments); i++ {
+		arg := arguments[i]
+		if len(arg) > 1 && arg[0] == '@' && !PosixlyCorrect() {
+			if flagFiles++; flagFiles > maxFlagFiles {
+				return nil, fmt.Errorf(T("too many flag files: %s"), arg)
+			}
+			fileArgs, err := ReadFlagFile(arg[1:])
+			if err != nil {
+				return nil, fmt.Errorf(T("flag file: %v"), err)
+			}
+			arguments = append(append(append([]string{}, arguments[:i]...), fileArgs...), arguments[i+1:]...)
+			i--
+			continue
+		}
+		if arg == "--" || arg == "-" || len(arg) < 2 || arg[0] != '-' {
+			return append(out, arguments[i:]...), nil
+		}
+
+		long := strings.HasPrefix(arg, "--")
+		name := strings.TrimLeft(arg, "-")
+		value, hasValue := "", false
+		if eq := strings.IndexByte(name, '='); eq >= 0 {
+			name, value, hasValue = name[:eq], name[eq+1:], true
+		}
+
+		if long || f.known(name) {
+			// A whole flag, either --name or a (legacy) multi-letter -name.
+			fl := f.Lookup(name)
+			if fl != nil && !hasValue && !isBoolFlag(fl) {
+				if i+1 >= len(arguments) {
+					return nil, fmt.Errorf(T("flag needs an argument: %s"), arg)
+				}
+				i++
+				out = append(out, "-"+name+"="+arguments[i])
+				continue
+			}
+			if hasValue {
+				out = append(out, "-"+name+"="+value)
+			} else {
+				out = append(out, "-"+name)
+			}
+			continue
+		}
+
+		// A cluster of single-letter options.
+		cluster := arg[1:]
+		for j := 0; j < len(cluster); j++ {
+			opt := cluster[j : j+1]
+			fl := f.Lookup(opt)
+			if fl == nil {
+				if !f.known(opt) {
+					return nil, fmt.Errorf(T("flag provided but not defined: -%s"), opt)
+				}
+				out = append(out, "-"+opt)
+				continue
+			}
+			if isBoolFlag(fl) {
+				if j+1 < len(cluster) && cluster[j+1] == '=' {
+					out = append(out, "-"+opt+cluster[j+1:])
+					break
+				}
+				out = append(out, "-"+opt)
+				continue
+			}
+			// The rest of the cluster, or the next argument, is the value.
+			rest := strings.TrimPrefix(cluster[j+1:], "=")
+			if rest == "" {
+				if i+1 >= len(arguments) {
+					return nil, fmt.Errorf(T("flag needs an argument: -%s"), opt)
+				}
+				i++
+				rest = arguments[i]
+			}
+			out = append(out, "-"+opt+"="+rest)
+			break
+		}
+	}
+	return out, nil
+}
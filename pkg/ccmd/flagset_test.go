@@ -0,0 +1,124 @@
+package ccmd
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+)
+
+func newTestFlagSet() *FlagSet {
+	fs := NewFlagSet("test", flag.ContinueOnError)
+	fs.Bool("v", false, "verbose")
+	fs.Bool("t", false, "tabs")
+	fs.Bool("e", false, "ends")
+	fs.Bool("lc", false, "legacy multi-letter flag")
+	fs.String("p", "*", "prompt")
+	fs.String("color", "never", "color")
+	fs.Alias("C", "color")
+	return fs
+}
+
+func TestFlagSetParse(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		args   []string
+		values map[string]string
+		rest   []string
+	}{
+		{
+			name:   "cluster",
+			args:   []string{"-vte", "file"},
+			values: map[string]string{"v": "true", "t": "true", "e": "true"},
+			rest:   []string{"file"},
+		},
+		{
+			name:   "cluster with attached value",
+			args:   []string{"-vp>", "file"},
+			values: map[string]string{"v": "true", "p": ">"},
+			rest:   []string{"file"},
+		},
+		{
+			name:   "cluster with separate value",
+			args:   []string{"-vp", "> ", "file"},
+			values: map[string]string{"v": "true", "p": "> "},
+			rest:   []string{"file"},
+		},
+		{
+			name:   "defined multi-letter flag wins over clustering",
+			args:   []string{"-lc"},
+			values: map[string]string{"lc": "true", "v": "false"},
+		},
+		{
+			name:   "long flag with value",
+			args:   []string{"--color", "always", "x"},
+			values: map[string]string{"color": "always"},
+			rest:   []string{"x"},
+		},
+		{
+			name:   "short alias",
+			args:   []string{"-C=auto"},
+			values: map[string]string{"color": "auto"},
+		},
+		{
+			name:   "double dash terminates",
+			args:   []string{"-v", "--", "-t"},
+			values: map[string]string{"v": "true", "t": "false"},
+			rest:   []string{"-t"},
+		},
+		{
+			name:   "first operand terminates",
+			args:   []string{"file", "-v"},
+			values: map[string]string{"v": "false"},
+			rest:   []string{"file", "-v"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := newTestFlagSet()
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatalf("Parse(%q) = %v, want nil", tt.args, err)
+			}
+			for name, want := range tt.values {
+				if got := fs.Lookup(name).Value.String(); got != want {
+					t.Errorf("flag %s = %q, want %q", name, got, want)
+				}
+			}
+			if got := fs.Args(); len(got) != 0 || len(tt.rest) != 0 {
+				if !reflect.DeepEqual(got, tt.rest) {
+					t.Errorf("Args() = %q, want %q", got, tt.rest)
+				}
+			}
+		})
+	}
+}
+
+func TestFlagSetParseErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{"-vx"},
+		{"-p"},
+		{"--color"},
+	} {
+		fs := newTestFlagSet()
+		fs.Usage = func() {}
+		fs.SetOutput(nopWriter{})
+		if err := fs.Parse(args); err == nil {
+			t.Errorf("Parse(%q) = nil, want error", args)
+		}
+	}
+}
+
+func TestPopulateOptionsAliases(t *testing.T) {
+	fs := newTestFlagSet()
+	ci := &CmdInfo{Flags: fs}
+	ci.PopulateOptions()
+	want := "-C, --color: color"
+	for _, opt := range ci.Options {
+		if opt == want {
+			return
+		}
+	}
+	t.Errorf("Options = %q, want an entry %q", ci.Options, want)
+}
+
+type nopWriter struct{}
+
+func (nopWriter) Write(p []byte) (int, error) { return len(p), nil }
@@ -0,0 +1,153 @@
+package ccmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+)
+
+// Long-running commands stop what they are doing when they are interrupted: they use Context,
+// which SIGINT, SIGTERM and SIGHUP cancel, return once it is done, and exit with the status of
+// context.Cause(Context()), see ExitCodeOf: 128+N for signal N, ExitInterrupted for SIGINT, as
+// shells report commands killed by a signal. A second signal terminates the command at once.
+// Commands that run a child in the foreground leave signals to it instead, see ForwardSignals.
+// Temporary files and other state that must not outlive the command are cleaned up by AtExit.
+
+// SignalError is the cause of the cancellation of Context.
+type SignalError struct {
+	Signal os.Signal
+}
+
+func (e *SignalError) Error() string {
+	return e.Signal.String()
+}
+
+// ExitCode returns the status of a command terminated by the signal: 128+N for signal N.
+func (e *SignalError) ExitCode() ExitCode {
+	return signalExitCode(e.Signal)
+}
+
+var (
+	signalOnce sync.Once
+	signalCtx  context.Context
+)
+
+// Context returns a context cancelled when the command receives SIGINT, SIGTERM or SIGHUP,
+// with a *SignalError as its cause. Until Context is first called, signals kill the command.
+func Context() context.Context {
+	signalOnce.Do(func() {
+		ctx, cancel := context.WithCancelCause(context.Background())
+		signalCtx = ctx
+		sigs := make(chan os.Signal, 2)
+		signal.Notify(sigs, stopSignals...)
+		go func() {
+			cancel(&SignalError{<-sigs})
+			sig := <-sigs
+			runCleanups()
+			exit(int(signalExitCode(sig)))
+		}()
+	})
+	return signalCtx
+}
+
+// WatchInterrupts sends every SIGINT the command receives, until stop is called. The channel
+// is closed afterwards. Commands for which an interrupt doesn't mean quitting (ed aborts the
+// command it runs) use it instead of Context; SIGINT still cancels Context if it is used too.
+func WatchInterrupts() (interrupts <-chan os.Signal, stop func()) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt)
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(ch)
+		})
+	}
+}
+
+// WatchHangups sends every SIGHUP and SIGTERM the command receives, until stop is called. The
+// channel is closed afterwards. Commands that save their work before terminating (ed writes its
+// buffer to ed.hup) use it instead of Context. Only interrupts can be caught on plan9 and
+// windows, where nothing is sent.
+func WatchHangups() (hangups <-chan os.Signal, stop func()) {
+	ch := make(chan os.Signal, 1)
+	if len(hangupSignals) > 0 {
+		signal.Notify(ch, hangupSignals...)
+	}
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(ch)
+		})
+	}
+}
+
+// ForwardSignals relays SIGTERM and SIGHUP to p, a child the command waits for, until stop is
+// called; the command should then exit with the status of the child. SIGINT is ignored meanwhile,
+// as the terminal sends it to the child too, which decides what an interrupt means. Commands
+// that forward signals don't use Context, which they would cancel as well.
+func ForwardSignals(p *os.Process) (stop func()) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, stopSignals...)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-sigs:
+				if sig != os.Interrupt {
+					p.Signal(sig)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(sigs)
+			close(done)
+		})
+	}
+}
+
+// cleanup is a hook registered with AtExit, a pointer so that it can be removed.
+type cleanup struct{ f func() }
+
+var (
+	cleanupsMu sync.Mutex
+	cleanups   []*cleanup
+)
+
+// AtExit registers f to run when the command exits through Exit, Exitf or Fatalf, is terminated
+// by a second signal, or returns from Run. Hooks run once, the last registered first. remove
+// unregisters f, for when whatever it cleans up is gone.
+func AtExit(f func()) (remove func()) {
+	c := &cleanup{f}
+	cleanupsMu.Lock()
+	cleanups = append(cleanups, c)
+	cleanupsMu.Unlock()
+	return func() {
+		cleanupsMu.Lock()
+		defer cleanupsMu.Unlock()
+		for i, other := range cleanups {
+			if other == c {
+				cleanups = append(cleanups[:i], cleanups[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// runCleanups runs, and unregisters, the hooks registered with AtExit.
+func runCleanups() {
+	cleanupsMu.Lock()
+	hooks := cleanups
+	cleanups = nil
+	cleanupsMu.Unlock()
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i].f()
+	}
+}
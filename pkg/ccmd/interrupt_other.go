@@ -0,0 +1,19 @@
+//go:build plan9 || windows
+
+package ccmd
+
+import "os"
+
+// stopSignals are the signals that cancel Context, only interrupts can be caught everywhere.
+var stopSignals = []os.Signal{os.Interrupt}
+
+// hangupSignals are the signals asking the command to terminate, other than SIGINT: none.
+var hangupSignals []os.Signal
+
+// signalExitCode returns ExitInterrupted for interrupts, there are no signal numbers here.
+func signalExitCode(sig os.Signal) ExitCode {
+	if sig == os.Interrupt {
+		return ExitInterrupted
+	}
+	return ExitFailure
+}
@@ -0,0 +1,101 @@
+//go:build !plan9 && !windows
+
+package ccmd
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestForwardSignals(t *testing.T) {
+	if signalCtx != nil {
+		t.Skip("Context catches signals since a previous run, more would terminate the test")
+	}
+	child := exec.Command("sleep", "10")
+	if err := child.Start(); err != nil {
+		t.Skip(err)
+	}
+	stop := ForwardSignals(child.Process)
+	defer stop()
+	syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+	err := child.Wait()
+	if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.Sys().(syscall.WaitStatus).Signal() != syscall.SIGHUP {
+		t.Errorf("the child exited with %v, want it to be killed by SIGHUP", err)
+	}
+}
+
+func TestInterrupt(t *testing.T) {
+	if signalCtx != nil {
+		t.Skip("Context catches signals since a previous run, more would terminate the test")
+	}
+
+	// Interrupts are only sent to the watcher while it runs
+	ints, stop := WatchInterrupts()
+	syscall.Kill(syscall.Getpid(), syscall.SIGINT)
+	select {
+	case sig := <-ints:
+		if sig != syscall.SIGINT {
+			t.Errorf("WatchInterrupts sent %v", sig)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("WatchInterrupts didn't send SIGINT")
+	}
+	stop()
+
+	// So are hangups
+	hups, stop := WatchHangups()
+	syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+	select {
+	case sig := <-hups:
+		if sig != syscall.SIGHUP {
+			t.Errorf("WatchHangups sent %v", sig)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("WatchHangups didn't send SIGHUP")
+	}
+	stop()
+
+	// A single signal cancels Context, a second one would terminate the test
+	ctx := Context()
+	syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("SIGTERM didn't cancel Context")
+	}
+	if code := ExitCodeOf(context.Cause(ctx)); code != 128+ExitCode(syscall.SIGTERM) {
+		t.Errorf("ExitCodeOf(cause) = %d", code)
+	}
+	if code := ExitCodeOf(fmt.Errorf("dial: %w", ctx.Err())); code != 128+ExitCode(syscall.SIGTERM) {
+		t.Errorf("ExitCodeOf(ctx.Err()) = %d", code)
+	}
+	if code := (&SignalError{syscall.SIGINT}).ExitCode(); code != ExitInterrupted {
+		t.Errorf("SIGINT exits with %d", code)
+	}
+}
+
+func TestAtExit(t *testing.T) {
+	defer func() { Stdout, Stderr = nil, nil }()
+	var ran []string
+	AtExit(func() { ran = append(ran, "first") })
+	remove := AtExit(func() { ran = append(ran, "removed") })
+	AtExit(func() { ran = append(ran, "last") })
+	remove()
+
+	var out strings.Builder
+	code := Run(func([]string, Stdio) int { return 5 }, []string{"demo"}, Stdio{Out: &out, Err: &out})
+	if got := strings.Join(ran, " "); code != 5 || got != "last first" {
+		t.Errorf("Run = %d, hooks ran: %q", code, got)
+	}
+
+	ran = nil
+	AtExit(func() { ran = append(ran, "exit") })
+	if code := CatchExit(func() { Exit(ExitFailure) }); code != 1 || len(ran) != 1 {
+		t.Errorf("Exit = %d, hooks ran: %q", code, ran)
+	}
+}
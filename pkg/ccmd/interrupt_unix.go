@@ -0,0 +1,22 @@
+//go:build !plan9 && !windows
+
+package ccmd
+
+import (
+	"os"
+	"syscall"
+)
+
+// stopSignals are the signals that cancel Context.
+var stopSignals = []os.Signal{syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP}
+
+// hangupSignals are the signals asking the command to terminate, other than SIGINT.
+var hangupSignals = []os.Signal{syscall.SIGHUP, syscall.SIGTERM}
+
+// signalExitCode returns 128+N for signal N.
+func signalExitCode(sig os.Signal) ExitCode {
+	if n, ok := sig.(syscall.Signal); ok {
+		return ExitCode(128 + int(n))
+	}
+	return ExitFailure
+}
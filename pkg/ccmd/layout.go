@@ -0,0 +1,312 @@
+package ccmd
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Text is laid out by display width, the number of terminal cells it takes: East Asian wide
+// and fullwidth characters take two cells, combining marks and other zero-width characters take
+// none, and ANSI escape sequences are kept in place without taking any space.
+
+// wideRanges are the East Asian Wide (W) and Fullwidth (F) ranges of Unicode, plus emoji presentation.
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F}, {0x231A, 0x231B}, {0x2329, 0x232A}, {0x23E9, 0x23EC}, {0x23F0, 0x23F0},
+	{0x23F3, 0x23F3}, {0x25FD, 0x25FE}, {0x2614, 0x2615}, {0x2648, 0x2653}, {0x267F, 0x267F},
+	{0x2693, 0x2693}, {0x26A1, 0x26A1}, {0x26AA, 0x26AB}, {0x26BD, 0x26BE}, {0x26C4, 0x26C5},
+	{0x26CE, 0x26CE}, {0x26D4, 0x26D4}, {0x26EA, 0x26EA}, {0x26F2, 0x26F3}, {0x26F5, 0x26F5},
+	{0x26FA, 0x26FA}, {0x26FD, 0x26FD}, {0x2705, 0x2705}, {0x270A, 0x270B}, {0x2728, 0x2728},
+	{0x274C, 0x274C}, {0x274E, 0x274E}, {0x2753, 0x2755}, {0x2757, 0x2757}, {0x2795, 0x2797},
+	{0x27B0, 0x27B0}, {0x27BF, 0x27BF}, {0x2B1B, 0x2B1C}, {0x2B50, 0x2B50}, {0x2B55, 0x2B55},
+	{0x2E80, 0x303E}, {0x3041, 0x33FF}, {0x3400, 0x4DBF}, {0x4E00, 0x9FFF}, {0xA000, 0xA4CF},
+	{0xA960, 0xA97F}, {0xAC00, 0xD7A3}, {0xF900, 0xFAFF}, {0xFE10, 0xFE19}, {0xFE30, 0xFE6F},
+	{0xFF00, 0xFF60}, {0xFFE0, 0xFFE6}, {0x16FE0, 0x16FE4}, {0x17000, 0x18CFF}, {0x1B000, 0x1B2FF},
+	{0x1F004, 0x1F004}, {0x1F0CF, 0x1F0CF}, {0x1F18E, 0x1F18E}, {0x1F191, 0x1F19A}, {0x1F200, 0x1F251},
+	{0x1F300, 0x1F64F}, {0x1F680, 0x1F6FF}, {0x1F7E0, 0x1F7EB}, {0x1F90C, 0x1F9FF}, {0x1FA70, 0x1FAFF},
+	{0x20000, 0x2FFFD}, {0x30000, 0x3FFFD},
+}
+
+// RuneWidth returns the number of cells r takes on a terminal.
+func RuneWidth(r rune) int {
+	switch {
+	case r < 0x20 || (r >= 0x7F && r < 0xA0):
+		return 0
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case r >= 0x1160 && r <= 0x11FF: // Hangul medial vowels and final consonants combine
+		return 0
+	case r < 0x1100:
+		return 1
+	}
+	i := sort.Search(len(wideRanges), func(i int) bool { return wideRanges[i][1] >= r })
+	if i < len(wideRanges) && wideRanges[i][0] <= r {
+		return 2
+	}
+	return 1
+}
+
+// escapeLen returns the length of the ANSI escape sequence s starts with, or 0.
+// CSI sequences ("\033[1;31m") and OSC sequences ("\033]8;;url\033\\") are recognized.
+func escapeLen(s string) int {
+	if len(s) < 2 || s[0] != '\033' {
+		return 0
+	}
+	switch s[1] {
+	case '[':
+		for i := 2; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7E {
+				return i + 1
+			}
+		}
+	case ']':
+		for i := 2; i < len(s); i++ {
+			if s[i] == '\a' {
+				return i + 1
+			}
+			if s[i] == '\033' && i+1 < len(s) && s[i+1] == '\\' {
+				return i + 2
+			}
+		}
+	default:
+		return 2
+	}
+	return len(s)
+}
+
+// StripANSI removes the ANSI escape sequences in s.
+func StripANSI(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		if n := escapeLen(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+		sb.WriteByte(s[i])
+		i++
+	}
+	return sb.String()
+}
+
+// StringWidth returns the number of cells s takes on a terminal. s should be a single line.
+func StringWidth(s string) int {
+	width := 0
+	for i := 0; i < len(s); {
+		if n := escapeLen(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		width += RuneWidth(r)
+		i += size
+	}
+	return width
+}
+
+// MaxWidth returns the width of the widest line of text.
+func MaxWidth(text string) int {
+	widest := 0
+	for _, line := range strings.Split(text, "\n") {
+		widest = max(widest, StringWidth(line))
+	}
+	return widest
+}
+
+// cut splits s after at most width cells, keeping escape sequences that follow the cut in head.
+func cut(s string, width int) (head, tail string) {
+	w := 0
+	for i := 0; i < len(s); {
+		if n := escapeLen(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		rw := RuneWidth(r)
+		if w+rw > width && rw > 0 {
+			return s[:i], s[i:]
+		}
+		w += rw
+		i += size
+	}
+	return s, ""
+}
+
+// Truncate shortens each line of text to width cells, ending truncated lines with ellipsis.
+// Escape sequences are never cut; a Reset is added if the cut dropped any of them.
+func Truncate(text string, width int, ellipsis string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if StringWidth(line) <= width {
+			continue
+		}
+		head, tail := cut(line, max(width-StringWidth(ellipsis), 0))
+		if strings.Contains(head, "\033") || strings.Contains(tail, "\033") {
+			lines[i] = head + Reset + ellipsis
+		} else {
+			lines[i] = head + ellipsis
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Wrap breaks each line of text so that it doesn't exceed width cells. Lines are broken
+// between words; words wider than width are broken where needed. Continuation lines keep the
+// indentation of the line they come from.
+func Wrap(text string, width int) string {
+	lines := strings.Split(text, "\n")
+	var out []string
+	for _, line := range lines {
+		out = append(out, wrapLine(line, width)...)
+	}
+	return strings.Join(out, "\n")
+}
+
+// wrapLine wraps a single line, see Wrap.
+func wrapLine(line string, width int) []string {
+	if width < 1 || StringWidth(line) <= width {
+		return []string{line}
+	}
+	body := strings.TrimLeft(line, " \t")
+	indent := line[:len(line)-len(body)]
+	avail := width - StringWidth(indent)
+	if avail < 1 {
+		indent, avail = "", width
+	}
+
+	var lines []string
+	current, currentWidth := "", 0
+	flush := func() {
+		lines = append(lines, indent+current)
+		current, currentWidth = "", 0
+	}
+	for _, word := range strings.Fields(body) {
+		ww := StringWidth(word)
+		if currentWidth > 0 && currentWidth+1+ww <= avail {
+			current += " " + word
+			currentWidth += 1 + ww
+			continue
+		}
+		if currentWidth > 0 {
+			flush()
+		}
+		for ww > avail {
+			head, tail := cut(word, avail)
+			if StringWidth(head) == 0 {
+				// A wide character doesn't fit in a single cell, let it overflow
+				_, size := utf8.DecodeRuneInString(word)
+				head, tail = word[:size], word[size:]
+			}
+			current = head
+			flush()
+			word, ww = tail, StringWidth(tail)
+		}
+		current, currentWidth = word, ww
+	}
+	if currentWidth > 0 || current != "" {
+		flush()
+	}
+	return lines
+}
+
+// Center centers each line of text within width cells, padding on the left only.
+func Center(text string, width int) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if w := StringWidth(line); w < width {
+			lines[i] = strings.Repeat(" ", (width-w)/2) + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// AlignRight aligns each line of text to the right of width cells.
+func AlignRight(text string, width int) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if w := StringWidth(line); w < width {
+			lines[i] = strings.Repeat(" ", width-w) + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Justify wraps text to width cells and spreads the words of each line so that it fills width.
+// The last line of each paragraph, and lines made of a single word, are left as-is.
+func Justify(text string, width int) string {
+	var out []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		lines := wrapLine(paragraph, width)
+		for i, line := range lines {
+			if i < len(lines)-1 {
+				line = justifyLine(line, width)
+			}
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// justifyLine spreads the words of line over width cells, keeping its indentation.
+func justifyLine(line string, width int) string {
+	body := strings.TrimLeft(line, " \t")
+	indent := line[:len(line)-len(body)]
+	words := strings.Fields(body)
+	if len(words) < 2 {
+		return line
+	}
+	gaps := len(words) - 1
+	spaces := width - StringWidth(indent)
+	for _, word := range words {
+		spaces -= StringWidth(word)
+	}
+	if spaces < gaps {
+		return line
+	}
+	var sb strings.Builder
+	sb.WriteString(indent)
+	for i, word := range words {
+		sb.WriteString(word)
+		if i < gaps {
+			n := spaces / gaps
+			if i < spaces%gaps {
+				n++
+			}
+			sb.WriteString(strings.Repeat(" ", n))
+		}
+	}
+	return sb.String()
+}
+
+// PadRight pads s with spaces up to width cells.
+func PadRight(s string, width int) string {
+	if w := StringWidth(s); w < width {
+		return s + strings.Repeat(" ", width-w)
+	}
+	return s
+}
+
+// Table renders rows as left-aligned columns separated by sep. The last column isn't padded.
+func Table(rows [][]string, sep string) string {
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], StringWidth(cell))
+		}
+	}
+	var sb strings.Builder
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(row)-1 {
+				sb.WriteString(cell)
+				break
+			}
+			sb.WriteString(PadRight(cell, widths[i]) + sep)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
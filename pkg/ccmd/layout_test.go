@@ -0,0 +1,46 @@
+package ccmd
+
+import "testing"
+
+func TestStringWidth(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want int
+	}{
+		{"hello", 5},
+		{"日本語", 6},
+		{"e\u0301", 1}, // e + combining acute accent
+		{"\033[1;31mred\033[0m", 3},
+		{"\033]8;;https://example.com\033\\link\033]8;;\033\\", 4},
+		{"ｆｕｌｌ", 8},
+	} {
+		if got := StringWidth(tt.s); got != tt.want {
+			t.Errorf("StringWidth(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestLayout(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"wrap", Wrap("the quick brown fox jumps", 10), "the quick\nbrown fox\njumps"},
+		{"wrap keeps indentation", Wrap("  aaa bbb ccc", 9), "  aaa bbb\n  ccc"},
+		{"wrap long word", Wrap("abcdefgh", 3), "abc\ndef\ngh"},
+		{"wrap wide", Wrap("日本語 日本語", 7), "日本語\n日本語"},
+		{"wrap ansi", Wrap("\033[1mbold\033[0m text", 5), "\033[1mbold\033[0m\ntext"},
+		{"center", Center("ab\n日本", 8), "   ab\n  日本"},
+		{"right", AlignRight("ab\nabcd", 4), "  ab\nabcd"},
+		{"justify", Justify("aa b cc dd", 8), "aa  b cc\ndd"},
+		{"truncate", Truncate("abcdefgh", 5, "…"), "abcd…"},
+		{"truncate wide", Truncate("日本語", 5, "…"), "日本…"},
+		{"truncate ansi", Truncate("\033[31mabcdef\033[0m", 4, "…"), "\033[31mabc\033[0m…"},
+		{"table", Table([][]string{{"a", "bb", "c"}, {"日本", "d", "e"}}, " "), "a    bb c\n日本 d  e\n"},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
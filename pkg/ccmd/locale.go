@@ -0,0 +1,276 @@
+package ccmd
+
+import (
+	"bufio"
+	"embed"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Help pages, flag usages and diagnostics are written in English and translated, through T,
+// with message catalogs: gettext .po or .mo files named after the locale they translate to,
+// e.g: "es.po", "pt_BR.mo". The locale of messages is taken from LC_ALL, LC_MESSAGES or LANG,
+// the first one set; "pt_BR.UTF-8" uses the "pt_BR" catalog, then the "pt" one. ccmd embeds
+// the catalogs of its own messages, commands add theirs with AddCatalogs. ccmd-xgettext
+// extracts the strings to translate from the sources of a command.
+
+//go:embed locale/*.po
+var ccmdCatalogs embed.FS
+
+// Catalog maps messages to their translation. Messages with a context (msgctxt) are keyed
+// by the context and the message joined by "\x04", as gettext does.
+type Catalog map[string]string
+
+var (
+	catalogsMu   sync.Mutex
+	catalogFSs   = []fs.FS{mustSub(ccmdCatalogs, "locale")}
+	catalogCache = make(map[string]Catalog) // by locale name
+)
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+// AddCatalogs makes the catalogs in the root of fsys, usually an embed.FS, available to T.
+// Their translations take precedence over those of the catalogs added before.
+func AddCatalogs(fsys fs.FS) {
+	catalogsMu.Lock()
+	defer catalogsMu.Unlock()
+	catalogFSs = append(catalogFSs, fsys)
+	catalogCache = make(map[string]Catalog)
+}
+
+// MessagesLocale returns the locale of messages, without its codeset and modifier
+// ("es_AR.UTF-8@euro" is "es_AR"), or "" for the C and POSIX locales.
+func MessagesLocale() string {
+	var locale string
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if locale = os.Getenv(env); locale != "" {
+			break
+		}
+	}
+	locale, _, _ = strings.Cut(locale, "@")
+	locale, _, _ = strings.Cut(locale, ".")
+	if locale == "C" || locale == "POSIX" {
+		return ""
+	}
+	return locale
+}
+
+// T returns the translation of msg for the locale of messages, or msg if there is none.
+func T(msg string) string {
+	locale := MessagesLocale()
+	if locale == "" || msg == "" {
+		return msg
+	}
+	if translation, ok := catalogFor(locale)[msg]; ok {
+		return translation
+	}
+	return msg
+}
+
+// N returns msg as is. It marks messages translated with T only where they are shown, e.g:
+// those of a table, for ccmd-xgettext, like gettext's N_.
+func N(msg string) string {
+	return msg
+}
+
+// catalogFor returns the translations for locale, merged from every catalog, loading them
+// the first time. Broken catalogs are reported once and ignored.
+func catalogFor(locale string) Catalog {
+	catalogsMu.Lock()
+	if catalog, ok := catalogCache[locale]; ok {
+		catalogsMu.Unlock()
+		return catalog
+	}
+	names := []string{locale}
+	if lang, _, ok := strings.Cut(locale, "_"); ok {
+		names = append(names, lang)
+	}
+
+	catalog := make(Catalog)
+	var errs []error
+	for _, fsys := range catalogFSs {
+		// The language alone is loaded first so that the translations for the territory win
+		for i := len(names) - 1; i >= 0; i-- {
+			c, err := loadCatalog(fsys, names[i])
+			if err != nil {
+				errs = append(errs, err)
+			}
+			for msg, translation := range c {
+				catalog[msg] = translation
+			}
+		}
+	}
+	catalogCache[locale] = catalog
+	catalogsMu.Unlock()
+
+	// Warnf translates its messages too, so only once the catalog is cached
+	for _, err := range errs {
+		Warnf("ignoring message catalog: %v", err)
+	}
+	return catalog
+}
+
+// loadCatalog reads the catalog name.mo, or name.po, of fsys. It returns nil if there is none.
+func loadCatalog(fsys fs.FS, name string) (Catalog, error) {
+	if data, err := fs.ReadFile(fsys, name+".mo"); err == nil {
+		c, err := ParseMO(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s.mo: %w", name, err)
+		}
+		return c, nil
+	}
+	f, err := fsys.Open(name + ".po")
+	if err != nil {
+		return nil, nil
+	}
+	defer f.Close()
+	c, err := ParsePO(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s.po: %w", name, err)
+	}
+	return c, nil
+}
+
+// ParsePO reads a catalog in the gettext PO format. Fuzzy and untranslated entries, and the
+// header, are left out, as msgfmt does; only the first form of plural messages is kept.
+func ParsePO(r io.Reader) (Catalog, error) {
+	c := make(Catalog)
+	var ctxt, id, str *string
+	var fuzzy bool
+	var current **string // the field continued by string lines
+	flush := func() {
+		if id != nil && *id != "" && str != nil && *str != "" && !fuzzy {
+			key := *id
+			if ctxt != nil {
+				key = *ctxt + "\x04" + key
+			}
+			c[key] = *str
+		}
+		ctxt, id, str, fuzzy, current = nil, nil, nil, false, nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "#"):
+			if id != nil {
+				flush()
+			}
+			if strings.HasPrefix(line, "#,") && strings.Contains(line, "fuzzy") {
+				fuzzy = true
+			}
+			continue
+		case strings.HasPrefix(line, `"`):
+			if current == nil || *current == nil {
+				return nil, fmt.Errorf("line %d: string outside of an entry", n)
+			}
+			s, err := strconv.Unquote(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", n, err)
+			}
+			**current += s
+			continue
+		}
+
+		keyword, value, _ := strings.Cut(line, " ")
+		s, err := strconv.Unquote(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		switch keyword {
+		case "msgctxt":
+			if id != nil {
+				flush()
+			}
+			ctxt, current = &s, &ctxt
+		case "msgid":
+			if id != nil {
+				flush()
+			}
+			id, current = &s, &id
+		case "msgid_plural":
+			current = nil
+		case "msgstr", "msgstr[0]":
+			str, current = &s, &str
+		default:
+			if !strings.HasPrefix(keyword, "msgstr[") {
+				return nil, fmt.Errorf("line %d: unknown keyword %s", n, keyword)
+			}
+			current = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return c, nil
+}
+
+// ParseMO reads a catalog in the gettext MO format, compiled by msgfmt. Only the first form
+// of plural messages is kept.
+func ParseMO(data []byte) (Catalog, error) {
+	if len(data) < 20 {
+		return nil, fmt.Errorf("not a MO file")
+	}
+	var order binary.ByteOrder
+	switch binary.LittleEndian.Uint32(data) {
+	case 0x950412de:
+		order = binary.LittleEndian
+	case 0xde120495:
+		order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("not a MO file")
+	}
+	if major := order.Uint32(data[4:]) >> 16; major > 1 {
+		return nil, fmt.Errorf("unsupported MO revision %d", major)
+	}
+	count := order.Uint32(data[8:])
+	originals, translations := order.Uint32(data[12:]), order.Uint32(data[16:])
+
+	// str returns the i-th string of the table at offset
+	str := func(table, i uint32) (string, error) {
+		entry := uint64(table) + uint64(i)*8
+		if entry+8 > uint64(len(data)) {
+			return "", fmt.Errorf("truncated MO file")
+		}
+		length, offset := uint64(order.Uint32(data[entry:])), uint64(order.Uint32(data[entry+4:]))
+		if offset+length > uint64(len(data)) {
+			return "", fmt.Errorf("truncated MO file")
+		}
+		return string(data[offset : offset+length]), nil
+	}
+
+	c := make(Catalog)
+	for i := uint32(0); i < count; i++ {
+		id, err := str(originals, i)
+		if err != nil {
+			return nil, err
+		}
+		translation, err := str(translations, i)
+		if err != nil {
+			return nil, err
+		}
+		// Plural forms are separated by NUL bytes
+		id, _, _ = strings.Cut(id, "\x00")
+		translation, _, _ = strings.Cut(translation, "\x00")
+		if id != "" && translation != "" {
+			c[id] = translation
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,139 @@
+package ccmd
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParsePO(t *testing.T) {
+	po := `# A comment
+msgid ""
+msgstr ""
+"Content-Type: text/plain; charset=UTF-8\n"
+
+#: demo.go:1
+msgid "Options"
+msgstr "Opciones"
+
+#, fuzzy
+msgid "Usage"
+msgstr "Uso"
+
+msgid ""
+"two\n"
+"lines"
+msgstr "dos\nlíneas"
+
+msgctxt "menu"
+msgid "Open"
+msgstr "Abrir"
+
+msgid "file"
+msgid_plural "files"
+msgstr[0] "archivo"
+msgstr[1] "archivos"
+
+msgid "untranslated"
+msgstr ""
+`
+	c, err := ParsePO(strings.NewReader(po))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Catalog{"Options": "Opciones", "two\nlines": "dos\nlíneas", "menu\x04Open": "Abrir", "file": "archivo"}
+	if len(c) != len(want) {
+		t.Errorf("ParsePO = %q, want %q", c, want)
+	}
+	for msg, translation := range want {
+		if c[msg] != translation {
+			t.Errorf("ParsePO[%q] = %q, want %q", msg, c[msg], translation)
+		}
+	}
+
+	if _, err := ParsePO(strings.NewReader("msgid \"a\"\nmsgstr \"b\nc\"\n")); err == nil {
+		t.Error("ParsePO accepted an unterminated string")
+	}
+}
+
+// mo returns a MO file, in little endian, holding the pairs of messages and translations.
+func mo(pairs ...string) []byte {
+	n := len(pairs) / 2
+	originals, translations := 28, 28+n*8
+	strs := 28 + n*16
+	data := make([]byte, strs)
+	le := binary.LittleEndian
+	le.PutUint32(data, 0x950412de)
+	le.PutUint32(data[8:], uint32(n))
+	le.PutUint32(data[12:], uint32(originals))
+	le.PutUint32(data[16:], uint32(translations))
+	for i, s := range pairs {
+		table := originals
+		if i%2 == 1 {
+			table = translations
+		}
+		entry := table + i/2*8
+		le.PutUint32(data[entry:], uint32(len(s)))
+		le.PutUint32(data[entry+4:], uint32(len(data)))
+		data = append(data, s...)
+		data = append(data, 0)
+	}
+	return data
+}
+
+func TestParseMO(t *testing.T) {
+	c, err := ParseMO(mo("", "Content-Type: text/plain; charset=UTF-8\n", "Options", "Opciones", "file\x00files", "archivo\x00archivos"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c) != 2 || c["Options"] != "Opciones" || c["file"] != "archivo" {
+		t.Errorf("ParseMO = %q", c)
+	}
+	if _, err := ParseMO(mo("Options", "Opciones")[:30]); err == nil {
+		t.Error("ParseMO accepted a truncated file")
+	}
+	if _, err := ParseMO([]byte("msgid \"Options\"\nmsgstr \"Opciones\"\n")); err == nil {
+		t.Error("ParseMO accepted a PO file")
+	}
+}
+
+func TestT(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "es_AR.UTF-8")
+	t.Setenv("LANG", "fr_FR.UTF-8")
+	savedFSs := catalogFSs
+	defer func() {
+		catalogsMu.Lock()
+		catalogFSs, catalogCache = savedFSs, make(map[string]Catalog)
+		catalogsMu.Unlock()
+	}()
+	AddCatalogs(fstest.MapFS{
+		"es.po":    {Data: []byte("msgid \"Hello\"\nmsgstr \"Hola\"\n\nmsgid \"Options\"\nmsgstr \"Parámetros\"\n")},
+		"es_AR.mo": {Data: mo("Hello", "Che, hola")},
+	})
+
+	// The territory wins over the language, which wins over the catalogs of ccmd
+	for msg, want := range map[string]string{"Hello": "Che, hola", "Options": "Parámetros", "Usage": "Uso", "Untranslated": "Untranslated"} {
+		if got := T(msg); got != want {
+			t.Errorf("T(%q) = %q, want %q", msg, got, want)
+		}
+	}
+
+	t.Setenv("LC_ALL", "C")
+	if got := T("Hello"); got != "Hello" {
+		t.Errorf("T(%q) = %q in the C locale", "Hello", got)
+	}
+
+	t.Setenv("LC_ALL", "es")
+	ci := &CmdInfo{Name: "demo", Synopsis: "[FILE]", Description: "Hello", Flags: NewFlagSet("demo", 0)}
+	page, err := ci.GenerateHelpPage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"  Sinopsis\n", "  Descripción:\n    Hola\n", "  Estado de salida:\n    0: Éxito\n"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("the help page lacks %q:\n%s", want, page)
+		}
+	}
+}
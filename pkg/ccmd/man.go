@@ -0,0 +1,114 @@
+package ccmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// roffEscape escapes text so that troff renders it literally.
+func roffEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	s = strings.ReplaceAll(s, "-", `\-`)
+	return s
+}
+
+// roffLines escapes a block of text and guards lines that troff would take as requests.
+func roffLines(text string) string {
+	var sb strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		line = roffEscape(strings.TrimLeft(line, "\t"))
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			line = `\&` + line
+		}
+		sb.WriteString(line + "\n")
+	}
+	return sb.String()
+}
+
+// manSection writes a section whose body is kept as-is (no filling), like in the help page.
+func manSection(sb *strings.Builder, title, body string) {
+	sb.WriteString(".SH " + strings.ToUpper(title) + "\n")
+	sb.WriteString(".nf\n")
+	sb.WriteString(roffLines(body))
+	sb.WriteString(".fi\n")
+}
+
+// GenerateManPage creates a man(7) page, for section 1, based on CmdInfo fields. Like the
+// help page, its text is translated, see T; the names of its sections are not.
+func (ci *CmdInfo) GenerateManPage() (string, error) {
+	if ci.Name == "" || ci.Description == "" || (ci.Synopsis == "" && ci.Usage == "") {
+		return "", errors.New(T("Name, Description, and either Synopsis or Usage must be set"))
+	}
+	ci.PopulateOptions()
+
+	sb := &strings.Builder{}
+	name := roffEscape(ci.Name)
+
+	sb.WriteString(fmt.Sprintf(".TH %s 1 %q \"a-utils\" \"User Commands\"\n", strings.ToUpper(name), time.Now().Format("2006-01-02")))
+
+	// NAME holds a one-line summary: the first line of the description
+	summary, _, _ := strings.Cut(T(ci.Description), "\n")
+	sb.WriteString(".SH NAME\n")
+	sb.WriteString(fmt.Sprintf("%s \\- %s\n", name, roffEscape(summary)))
+
+	sb.WriteString(".SH SYNOPSIS\n")
+	synopsis := ci.Synopsis
+	if synopsis == "" {
+		synopsis = ci.Usage
+	}
+	// Some synopses repeat the command name, don't print it twice
+	synopsis = strings.TrimPrefix(synopsis, ci.Name+" ")
+	sb.WriteString(fmt.Sprintf(".B %s\n%s\n", name, roffEscape(synopsis)))
+
+	sb.WriteString(".SH DESCRIPTION\n")
+	sb.WriteString(roffLines(T(ci.Description)))
+
+	if len(ci.Options) > 0 {
+		sb.WriteString(".SH OPTIONS\n")
+		for _, opt := range ci.Options {
+			names, usage, _ := strings.Cut(opt, ": ")
+			sb.WriteString(".TP\n")
+			sb.WriteString(".B " + roffEscape(names) + "\n")
+			sb.WriteString(roffLines(usage))
+		}
+	}
+
+	if len(ci.Fields) > 0 {
+		sb.WriteString(".SH OUTPUT FIELDS\n")
+		sb.WriteString(roffEscape(T("Names of the fields of the records written with --format=json, ndjson or tsv.")) + "\n")
+		for _, f := range ci.Fields {
+			sb.WriteString(".TP\n")
+			sb.WriteString(".B " + roffEscape(f.Name) + "\n")
+			sb.WriteString(roffLines(T(f.Description)))
+		}
+	}
+
+	ci.writeManSections(sb)
+
+	sb.WriteString(".SH FILES\n")
+	sb.WriteString(".TP\n")
+	sb.WriteString(".B " + roffEscape("$XDG_CONFIG_HOME/a-utils/"+ci.Name+".flags") + "\n")
+	sb.WriteString(roffEscape(T("Flag file whose arguments are prepended to the command line, see --no-flagfile and --show-flagfile.")) + "\n")
+
+	if len(ci.Authors) > 0 {
+		sb.WriteString(".SH AUTHORS\n")
+		sb.WriteString(roffEscape(fmt.Sprintf(T("%s and contributors"), strings.Join(ci.Authors, ", "))) + "\n")
+	}
+
+	if len(ci.SeeAlso) > 0 || ci.Repository != "" {
+		sb.WriteString(".SH SEE ALSO\n")
+		if len(ci.SeeAlso) > 0 {
+			sb.WriteString(roffEscape(strings.Join(ci.SeeAlso, ", ")) + "\n")
+		}
+		if ci.Repository != "" {
+			if len(ci.SeeAlso) > 0 {
+				sb.WriteString(".PP\n")
+			}
+			sb.WriteString(roffEscape(ci.Repository) + "\n")
+		}
+	}
+
+	return sb.String(), nil
+}
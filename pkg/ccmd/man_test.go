@@ -0,0 +1,95 @@
+package ccmd
+
+import (
+	"flag"
+	"strings"
+	"testing"
+)
+
+func newManCmdInfo() *CmdInfo {
+	fs := NewFlagSet("demo", flag.ContinueOnError)
+	fs.Bool("v", false, "Verbose")
+	fs.String("sep", `\t`, "Separator, e.g: \\t")
+	return &CmdInfo{
+		Name:        "demo",
+		Authors:     []string{"xplshn"},
+		Repository:  "https://github.com/xplshn/a-utils",
+		Description: "Demonstrates a man page\n.TH is a request\n'br too\nC:\\dir",
+		Synopsis:    "[-v] [FILE]",
+		Examples:    []Example{{Description: "Print a file", Command: "demo file"}},
+		EnvVars:     []EnvVar{{Name: "DEMO_PATH", Description: "Where to look"}},
+		SeeAlso:     []string{"walk(1)"},
+		Fields:      []Field{{Name: "path", Description: "Path of the file"}},
+		Flags:       fs,
+	}
+}
+
+func TestGenerateManPage(t *testing.T) {
+	page, err := newManCmdInfo().GenerateManPage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(page, ".TH DEMO 1 ") {
+		t.Errorf("the page doesn't start with its title:\n%s", page)
+	}
+	// Lines starting with a control character are guarded, backslashes and dashes escaped
+	for _, want := range []string{
+		"\\&.TH is a request\n",
+		"\\&'br too\n",
+		"C:\\edir\n",
+		".B \\-v\n",
+		"Separator, e.g: \\et\n",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("the page lacks %q:\n%s", want, page)
+		}
+	}
+	for _, line := range strings.Split(page, "\n") {
+		if strings.HasPrefix(line, ".TH is") || strings.HasPrefix(line, "'") {
+			t.Errorf("unguarded line %q", line)
+		}
+	}
+
+	last := -1
+	for _, section := range []string{
+		"NAME", "SYNOPSIS", "DESCRIPTION", "OPTIONS", "OUTPUT FIELDS", "EXAMPLES",
+		"ENVIRONMENT", "FILES", "AUTHORS", "SEE ALSO",
+	} {
+		i := strings.Index(page, ".SH "+section+"\n")
+		if i < 0 {
+			t.Fatalf("the page lacks the section %s:\n%s", section, page)
+		}
+		if i < last {
+			t.Errorf("the section %s is out of order:\n%s", section, page)
+		}
+		last = i
+	}
+}
+
+func TestManFlag(t *testing.T) {
+	defer func() { Stdout, Stderr = nil, nil }()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	var stdout, stderr strings.Builder
+	Stdout, Stderr = &stdout, &stderr
+	if code := CatchExit(func() { newManCmdInfo().ParseArgs([]string{"--man"}) }); code != int(ExitSuccess) {
+		t.Errorf("ParseArgs(--man) exited with %d, want %d; stderr: %q", code, ExitSuccess, stderr.String())
+	}
+	want, err := newManCmdInfo().GenerateManPage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The title line holds the date, which may change in between
+	_, got, _ := strings.Cut(stdout.String(), "\n")
+	if _, want, _ = strings.Cut(want, "\n"); got != want {
+		t.Errorf("--man printed\n%s\nwant\n%s", stdout.String(), want)
+	}
+
+	// The flag is hidden from the help page
+	help, err := newManCmdInfo().GenerateHelpPage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(help, "--man") {
+		t.Errorf("the help page shows --man:\n%s", help)
+	}
+}
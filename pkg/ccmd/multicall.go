@@ -0,0 +1,164 @@
+package ccmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Commands register their entry point, a MainFunc, so that a single multicall binary (busybox
+// style) can run any of them. The binary picks the command from the name it is invoked as,
+// through a symlink or a hard link named after the command, or from its first argument:
+//
+//	$ a-utils walk -f .
+//	$ ln -s a-utils walk && ./walk -f .
+
+// Stdio are the standard streams of a command.
+type Stdio struct {
+	In  io.Reader
+	Out io.Writer
+	Err io.Writer
+}
+
+// OSStdio returns the standard streams of the process.
+func OSStdio() Stdio {
+	return Stdio{In: os.Stdin, Out: os.Stdout, Err: os.Stderr}
+}
+
+// Color applies the color policy to Out, see NewColor. Outputs that aren't files, such as
+// pipes set up by a test, are treated like files that aren't terminals.
+func (s Stdio) Color(mode ColorMode) *Color {
+	f, _ := s.Out.(*os.File)
+	return NewColor(f, mode)
+}
+
+// MainFunc runs a command with args, args[0] being the name it is invoked as, and returns
+// its exit status. It may also exit through Exit, Exitf and Fatalf.
+type MainFunc func(args []string, stdio Stdio) int
+
+var registry = make(map[string]MainFunc)
+
+// Register makes a command available to the multicall binary under name.
+func Register(name string, main MainFunc) {
+	if _, dup := registry[name]; dup {
+		panic(fmt.Sprintf("ccmd: command %q registered twice", name))
+	}
+	registry[name] = main
+}
+
+// Lookup returns the entry point of the registered command name.
+func Lookup(name string) (MainFunc, bool) {
+	main, ok := registry[name]
+	return main, ok
+}
+
+// Commands returns the names of the registered commands, sorted.
+func Commands() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Run runs main with args and stdio, the output of ccmd and the diagnostics of the command
+// going to stdio too, then the hooks registered with AtExit, and returns its exit status.
+func Run(main MainFunc, args []string, stdio Stdio) int {
+	defer runCleanups()
+	Stdout, Stderr = stdio.Out, stdio.Err
+	if len(args) > 0 {
+		progName = filepath.Base(args[0])
+	}
+	return main(args, stdio)
+}
+
+// MulticallMain is the entry point of the multicall binary. If the name it is invoked as is
+// that of a registered command, the command is run; otherwise the first argument names it.
+//
+//	--list: print the names of the registered commands
+//	--install DIR: make symlinks named after every command, pointing to the binary, in DIR
+func MulticallMain(args []string, stdio Stdio) int {
+	Stdout, Stderr = stdio.Out, stdio.Err
+	self := "a-utils"
+	if len(args) > 0 {
+		self = filepath.Base(args[0])
+		if main, ok := Lookup(self); ok {
+			return Run(main, args, stdio)
+		}
+	}
+	progName = self
+
+	usage := func() {
+		fmt.Fprintf(stdio.Err, "usage: %s COMMAND [ARGS...] | --list | --install DIR\n", self)
+	}
+	if len(args) < 2 {
+		usage()
+		return int(ExitUsage)
+	}
+	switch arg := args[1]; arg {
+	case "--list", "-l":
+		for _, name := range Commands() {
+			fmt.Fprintln(stdio.Out, name)
+		}
+		return int(ExitSuccess)
+	case "--install", "-i":
+		if len(args) != 3 {
+			usage()
+			return int(ExitUsage)
+		}
+		exe, err := os.Executable()
+		if err != nil {
+			Warnf("%v", err)
+			return int(ExitFailure)
+		}
+		if err := Install(args[2], exe); err != nil {
+			Warnf("%v", err)
+			return int(ExitCodeOf(err))
+		}
+		return int(ExitSuccess)
+	case "--help", "-h":
+		usage()
+		return int(ExitSuccess)
+	default:
+		if strings.HasPrefix(arg, "-") {
+			Warnf("unknown option %s", arg)
+			usage()
+			return int(ExitUsage)
+		}
+		main, ok := Lookup(arg)
+		if !ok {
+			Warnf("%s: command not found, see --list", arg)
+			return int(ExitNotFound)
+		}
+		return Run(main, args[1:], stdio)
+	}
+}
+
+// Install creates, in dir, a symlink to exe for every registered command. Existing symlinks
+// are replaced; other files are left alone and reported.
+func Install(dir, exe string) error {
+	var failed []string
+	for _, name := range Commands() {
+		link := filepath.Join(dir, name)
+		if fi, err := os.Lstat(link); err == nil {
+			if fi.Mode()&os.ModeSymlink == 0 {
+				failed = append(failed, name)
+				continue
+			}
+			if err := os.Remove(link); err != nil {
+				return err
+			}
+		}
+		if err := os.Symlink(exe, link); err != nil {
+			return err
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("not replacing files that aren't symlinks in %s: %s", dir, strings.Join(failed, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package ccmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMulticall(t *testing.T) {
+	savedName := progName
+	defer func() { Stdout, Stderr, progName = nil, nil, savedName }()
+
+	defer delete(registry, "mc-echo")
+	Register("mc-echo", func(args []string, stdio Stdio) int {
+		stdio.Out.Write([]byte(strings.Join(args, " ") + "\n"))
+		return 7
+	})
+	run := func(args ...string) (int, string, string) {
+		var out, err strings.Builder
+		code := MulticallMain(args, Stdio{In: strings.NewReader(""), Out: &out, Err: &err})
+		return code, out.String(), err.String()
+	}
+
+	for _, tt := range []struct {
+		args []string
+		code int
+		out  string
+	}{
+		{[]string{"/usr/bin/mc-echo", "a", "b"}, 7, "/usr/bin/mc-echo a b\n"},
+		{[]string{"a-utils", "mc-echo", "a"}, 7, "mc-echo a\n"},
+		{[]string{"a-utils"}, 2, ""},
+		{[]string{"a-utils", "--bogus"}, 2, ""},
+		{[]string{"a-utils", "mc-nope"}, 3, ""},
+	} {
+		if code, out, _ := run(tt.args...); code != tt.code || out != tt.out {
+			t.Errorf("%q: got %d and %q, want %d and %q", tt.args, code, out, tt.code, tt.out)
+		}
+	}
+
+	if code, out, _ := run("a-utils", "--list"); code != 0 || !strings.Contains(out, "mc-echo\n") {
+		t.Errorf("--list: got %d and %q", code, out)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "mc-echo"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Install(dir, "/bin/a-utils"); err == nil || !strings.Contains(err.Error(), "mc-echo") {
+		t.Errorf("Install over a regular file: got %v", err)
+	}
+	os.Remove(filepath.Join(dir, "mc-echo"))
+	if err := Install(dir, "/bin/a-utils"); err != nil {
+		t.Fatal(err)
+	}
+	if target, err := os.Readlink(filepath.Join(dir, "mc-echo")); err != nil || target != "/bin/a-utils" {
+		t.Errorf("Readlink = %q, %v", target, err)
+	}
+}
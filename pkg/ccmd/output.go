@@ -0,0 +1,246 @@
+package ccmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Commands that print information emit it as records: values of named fields, documented in
+// CmdInfo.Fields. The user chooses how they are written with --format:
+//
+//	text:   the usual output of the command
+//	json:   an array of objects
+//	ndjson: one object per line
+//	tsv:    a header line with the field names, then one line of tab-separated values per record
+//
+// Field names are part of the interface of a command and must not change once published.
+
+// Format is the value of a --format flag. It implements flag.Value.
+type Format int
+
+const (
+	FormatText Format = iota
+	FormatJSON
+	FormatNDJSON
+	FormatTSV
+)
+
+// Formats are the accepted values of a --format flag.
+var Formats = []string{"json", "ndjson", "text", "tsv"}
+
+func (f Format) String() string {
+	switch f {
+	case FormatJSON:
+		return "json"
+	case FormatNDJSON:
+		return "ndjson"
+	case FormatTSV:
+		return "tsv"
+	}
+	return "text"
+}
+
+// Set parses "text", "json", "ndjson" or "tsv".
+func (f *Format) Set(s string) error {
+	switch s {
+	case "text":
+		*f = FormatText
+	case "json":
+		*f = FormatJSON
+	case "ndjson":
+		*f = FormatNDJSON
+	case "tsv":
+		*f = FormatTSV
+	default:
+		return fmt.Errorf("invalid format %q, use text, json, ndjson or tsv", s)
+	}
+	return nil
+}
+
+// Choices lets completion scripts offer the accepted values.
+func (f *Format) Choices() []string {
+	return Formats
+}
+
+// Structured reports whether the format is meant for other programs rather than for people.
+func (f Format) Structured() bool {
+	return f != FormatText
+}
+
+// Field documents a field of the records a command outputs.
+type Field struct {
+	Name        string
+	Description string
+}
+
+// Record holds the values of the fields of one item, by field name. Fields without a value are left out.
+type Record map[string]any
+
+// Output writes records in the format chosen with --format. It is safe for concurrent use.
+type Output struct {
+	Format Format
+	Fields []Field
+	// Text writes a record for FormatText; by default the values are separated by tabs.
+	Text func(w io.Writer, r Record) error
+
+	w       io.Writer
+	mu      sync.Mutex
+	written int
+	headed  bool
+	closed  bool
+}
+
+// NewOutput returns an Output writing records with the documented fields of the command to w.
+func (ci *CmdInfo) NewOutput(w io.Writer, format Format) *Output {
+	return &Output{Format: format, Fields: ci.Fields, w: w}
+}
+
+// Write writes a record.
+func (o *Output) Write(r Record) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if err := o.write(r); err != nil {
+		return err
+	}
+	o.written++
+	return nil
+}
+
+// write writes a record, o.written counting those written before it.
+func (o *Output) write(r Record) error {
+	switch o.Format {
+	case FormatJSON:
+		obj, err := o.object(r)
+		if err != nil {
+			return err
+		}
+		prefix := ",\n  "
+		if o.written == 0 {
+			prefix = "[\n  "
+		}
+		_, err = io.WriteString(o.w, prefix+obj)
+		return err
+	case FormatNDJSON:
+		obj, err := o.object(r)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(o.w, obj+"\n")
+		return err
+	case FormatTSV:
+		if err := o.header(); err != nil {
+			return err
+		}
+		_, err := io.WriteString(o.w, strings.Join(o.values(r, tsvEscape), "\t")+"\n")
+		return err
+	}
+
+	if o.Text != nil {
+		return o.Text(o.w, r)
+	}
+	_, err := io.WriteString(o.w, strings.Join(o.values(r, nil), "\t")+"\n")
+	return err
+}
+
+// header writes the line of field names of FormatTSV, once.
+func (o *Output) header() error {
+	if o.headed {
+		return nil
+	}
+	var names []string
+	for _, f := range o.Fields {
+		names = append(names, f.Name)
+	}
+	if _, err := io.WriteString(o.w, strings.Join(names, "\t")+"\n"); err != nil {
+		return err
+	}
+	o.headed = true
+	return nil
+}
+
+// Close terminates the output: for FormatJSON it closes the array, which is empty if nothing
+// was written, and for FormatTSV it writes the header line if no record did.
+func (o *Output) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed {
+		return nil
+	}
+	o.closed = true
+	switch o.Format {
+	case FormatJSON:
+		if o.written == 0 {
+			_, err := io.WriteString(o.w, "[]\n")
+			return err
+		}
+		_, err := io.WriteString(o.w, "\n]\n")
+		return err
+	case FormatTSV:
+		return o.header()
+	}
+	return nil
+}
+
+// object encodes r as a JSON object whose keys follow the order of the fields.
+func (o *Output) object(r Record) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("{")
+	first := true
+	for _, f := range o.Fields {
+		v, ok := r[f.Name]
+		if !ok {
+			continue
+		}
+		key, _ := json.Marshal(f.Name)
+		value, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("field %s: %v", f.Name, err)
+		}
+		if !first {
+			sb.WriteString(",")
+		}
+		first = false
+		sb.Write(key)
+		sb.WriteString(":")
+		sb.Write(value)
+	}
+	sb.WriteString("}")
+	return sb.String(), nil
+}
+
+// values formats the values of r in the order of the fields, passing them through escape if not nil.
+func (o *Output) values(r Record, escape func(string) string) []string {
+	var values []string
+	for _, f := range o.Fields {
+		s := formatValue(r[f.Name])
+		if escape != nil {
+			s = escape(s)
+		}
+		values = append(values, s)
+	}
+	return values
+}
+
+// formatValue turns a field value into text: times use RFC 3339, lists are joined by spaces.
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case []string:
+		return strings.Join(v, " ")
+	}
+	return fmt.Sprint(v)
+}
+
+// tsvEscape escapes the characters that would break a TSV line.
+func tsvEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`).Replace(s)
+}
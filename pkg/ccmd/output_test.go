@@ -0,0 +1,63 @@
+package ccmd
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestOutput(t *testing.T) {
+	ci := &CmdInfo{Fields: []Field{{Name: "name"}, {Name: "size"}, {Name: "tags"}}}
+	records := []Record{
+		{"size": 10, "name": "a\tb", "tags": []string{"x", "y"}},
+		{"name": "c"},
+	}
+	for _, tt := range []struct {
+		format Format
+		want   string
+	}{
+		{FormatText, "a\tb\t10\tx y\nc\t\t\n"},
+		{FormatNDJSON, `{"name":"a\tb","size":10,"tags":["x","y"]}` + "\n" + `{"name":"c"}` + "\n"},
+		{FormatJSON, "[\n  " + `{"name":"a\tb","size":10,"tags":["x","y"]}` + ",\n  " + `{"name":"c"}` + "\n]\n"},
+		{FormatTSV, "name\tsize\ttags\na\\tb\t10\tx y\nc\t\t\n"},
+	} {
+		var sb strings.Builder
+		out := ci.NewOutput(&sb, tt.format)
+		for _, r := range records {
+			if err := out.Write(r); err != nil {
+				t.Fatalf("%v: Write = %v", tt.format, err)
+			}
+		}
+		out.Close()
+		if got := sb.String(); got != tt.want {
+			t.Errorf("%v: got %q, want %q", tt.format, got, tt.want)
+		}
+	}
+
+	for format, want := range map[Format]string{FormatJSON: "[]\n", FormatTSV: "name\tsize\ttags\n", FormatNDJSON: ""} {
+		var sb strings.Builder
+		ci.NewOutput(&sb, format).Close()
+		if got := sb.String(); got != want {
+			t.Errorf("%v: empty output = %q, want %q", format, got, want)
+		}
+	}
+
+	// A record that fails to be written leaves no trace of itself
+	for format, want := range map[Format]string{
+		FormatJSON:   "[\n  " + `{"name":"c"}` + "\n]\n",
+		FormatNDJSON: `{"name":"c"}` + "\n",
+	} {
+		var sb strings.Builder
+		out := ci.NewOutput(&sb, format)
+		if err := out.Write(Record{"name": "bad", "size": math.Inf(1)}); err == nil {
+			t.Errorf("%v: Write of an infinite size = nil error, want one", format)
+		}
+		if err := out.Write(Record{"name": "c"}); err != nil {
+			t.Fatalf("%v: Write = %v", format, err)
+		}
+		out.Close()
+		if got := sb.String(); got != want {
+			t.Errorf("%v: after a failed record, got %q, want %q", format, got, want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package ccmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// Commands follow POSIX, and mark what they add to it as extensions: flags with
+// FlagSet.Extension, other behaviors with CmdInfo.Extensions. Help and man pages note of
+// every extension that it is NOT POSIX. When POSIXLY_CORRECT is set, extensions are
+// disabled: ParseArgs rejects extension flags and takes "@path" for an operand rather than a
+// flag file, and commands check their other extensions with CheckExtension before using them.
+
+// Extension documents a behavior of the command that POSIX doesn't specify.
+type Extension struct {
+	Name        string // e.g: "STRING =~ REGEX"
+	Description string // e.g: "The string matches the regular expression"
+}
+
+// PosixlyCorrect reports whether POSIXLY_CORRECT is set, to any value, as GNU utilities do.
+func PosixlyCorrect() bool {
+	_, ok := os.LookupEnv("POSIXLY_CORRECT")
+	return ok
+}
+
+// ExtensionError reports the use of an extension while POSIXLY_CORRECT is set.
+type ExtensionError struct {
+	Name string
+}
+
+func (e *ExtensionError) Error() string {
+	return fmt.Sprintf(T("%s is NOT POSIX, and POSIXLY_CORRECT is set"), e.Name)
+}
+
+// CheckExtension returns an *ExtensionError for the extension name if POSIXLY_CORRECT is set.
+func CheckExtension(name string) error {
+	if PosixlyCorrect() {
+		return &ExtensionError{Name: name}
+	}
+	return nil
+}
+
+// Extension marks the flag name as an extension, see PosixlyCorrect. Marking an alias
+// leaves the other names of the flag alone, e.g: a --long alias of a POSIX -l.
+func (f *FlagSet) Extension(name string) {
+	if f.Lookup(name) == nil {
+		panic(fmt.Sprintf("ccmd: cannot mark undefined flag %q as an extension", name))
+	}
+	f.extensions[name] = true
+}
+
+// IsExtension reports whether the flag name, or the flag it is an alias of, is an extension.
+func (f *FlagSet) IsExtension(name string) bool {
+	return f.extensions[name] || f.extensions[f.aliases[name]]
+}
+
+// hasExtensions reports whether the command has extensions, flags or other behaviors.
+func (ci *CmdInfo) hasExtensions() bool {
+	if len(ci.Extensions) > 0 {
+		return true
+	}
+	fs := ci.flagSet()
+	found := false
+	fs.VisitAll(func(fl *flag.Flag) {
+		found = found || fs.IsExtension(fl.Name)
+	})
+	return found
+}
+
+// envVars returns the environment variables the command reads, POSIXLY_CORRECT included if
+// the command has extensions.
+func (ci *CmdInfo) envVars() []EnvVar {
+	if !ci.hasExtensions() {
+		return ci.EnvVars
+	}
+	posix := EnvVar{Name: "POSIXLY_CORRECT", Description: N("If set, the extensions to POSIX, marked as NOT POSIX, are disabled")}
+	return append(append([]EnvVar{}, ci.EnvVars...), posix)
+}
+
+// checkExtensionFlags returns an *ExtensionError for the first extension flag set, if
+// POSIXLY_CORRECT is set.
+func (f *FlagSet) checkExtensionFlags() error {
+	if !PosixlyCorrect() {
+		return nil
+	}
+	var err error
+	f.Visit(func(fl *flag.Flag) {
+		if err == nil && f.IsExtension(fl.Name) {
+			err = &ExtensionError{Name: flagPrefix(fl.Name) + fl.Name}
+		}
+	})
+	return err
+}
@@ -0,0 +1,61 @@
+package ccmd
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPosixlyCorrect(t *testing.T) {
+	defer func() { Stderr = nil }()
+	newCmdInfo := func() *CmdInfo {
+		fs := NewFlagSet("demo", flag.ContinueOnError)
+		fs.Bool("l", false, "Long format")
+		fs.Alias("long", "l")
+		fs.Extension("long")
+		fs.String("color", "never", "Colorize the output")
+		fs.Extension("color")
+		return &CmdInfo{
+			Name:        "demo",
+			Synopsis:    "[-l] [FILE...]",
+			Description: "Lists files",
+			Extensions:  []Extension{{Name: "STRING =~ REGEX", Description: "The string matches the regular expression"}},
+			Flags:       fs,
+		}
+	}
+
+	t.Setenv("POSIXLY_CORRECT", "")
+	os.Unsetenv("POSIXLY_CORRECT")
+	ci := newCmdInfo()
+	ci.ParseArgs([]string{"--no-flagfile", "--color=always"})
+	if err := CheckExtension("=~"); err != nil {
+		t.Errorf("CheckExtension = %v without POSIXLY_CORRECT", err)
+	}
+	page, _ := ci.GenerateHelpPage()
+	for _, want := range []string{"--color: Colorize the output (This is NOT POSIX)", "-l, --long: Long format (NOT POSIX: --long)\n", "STRING =~ REGEX: The string matches", "POSIXLY_CORRECT: "} {
+		if !strings.Contains(page, want) {
+			t.Errorf("the help page lacks %q:\n%s", want, page)
+		}
+	}
+
+	t.Setenv("POSIXLY_CORRECT", "")
+	var stderr strings.Builder
+	Stderr = &stderr
+	if code := CatchExit(func() { newCmdInfo().ParseArgs([]string{"--no-flagfile", "-l", "--color=always"}) }); code != int(ExitUsage) {
+		t.Errorf("ParseArgs exited with %d, want %d", code, ExitUsage)
+	}
+	if !strings.Contains(stderr.String(), "--color is NOT POSIX") {
+		t.Errorf("diagnostic: %q", stderr.String())
+	}
+	if err := CheckExtension("=~"); ExitCodeOf(err) != ExitUsage {
+		t.Errorf("CheckExtension = %v with POSIXLY_CORRECT", err)
+	}
+
+	// Arguments that would name flag files are operands
+	ci = newCmdInfo()
+	ci.ParseArgs([]string{"--no-flagfile", "-l", "@file"})
+	if args := ci.Flags.Args(); len(args) != 1 || args[0] != "@file" {
+		t.Errorf("operands = %q", args)
+	}
+}
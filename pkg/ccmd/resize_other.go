@@ -0,0 +1,8 @@
+//go:build plan9 || windows
+
+package ccmd
+
+import "os"
+
+// notifyResize does nothing, there is no resize signal on this system.
+func notifyResize(c chan<- os.Signal) {}
@@ -0,0 +1,14 @@
+//go:build !plan9 && !windows
+
+package ccmd
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyResize relays the signals sent when the terminal is resized to c.
+func notifyResize(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGWINCH)
+}
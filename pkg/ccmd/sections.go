@@ -0,0 +1,241 @@
+package ccmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Example is a command line shown in the Examples section, along with what it does.
+type Example struct {
+	Description string // e.g: "Print file sizes and cumulative total"
+	Command     string // e.g: "walk -f mink/ | fin -s", without the prompt
+}
+
+// EnvVar documents an environment variable the command reads.
+type EnvVar struct {
+	Name        string
+	Description string
+}
+
+// extensionsNote introduces the extensions of a command in help and man pages.
+var extensionsNote = N("This is NOT POSIX, these are disabled when POSIXLY_CORRECT is set:")
+
+// section is a titled block of text of a help or man page.
+type section struct {
+	title string
+	body  string
+}
+
+// customSections returns the CustomFields in a stable order: numbered fields ("1_Examples",
+// "2_Behavior", ...) sorted by number, whatever gaps there are between them, then the other
+// fields sorted by name, then Notes. Numbering prefixes are stripped from the titles.
+func (ci *CmdInfo) customSections() []section {
+	type numbered struct {
+		n int
+		section
+	}
+	var sections []numbered
+	var others []section
+	var notes []section
+	for field, value := range ci.CustomFields {
+		s := section{title: field, body: fmt.Sprintf("%s", value)}
+		if prefix, title, ok := strings.Cut(field, "_"); ok {
+			if n, err := strconv.Atoi(prefix); err == nil {
+				s.title = title
+				if title != "Notes" {
+					sections = append(sections, numbered{n, s})
+					continue
+				}
+			}
+		}
+		if s.title == "Notes" {
+			notes = append(notes, s)
+		} else {
+			others = append(others, s)
+		}
+	}
+	sort.Slice(sections, func(i, j int) bool {
+		if sections[i].n != sections[j].n {
+			return sections[i].n < sections[j].n
+		}
+		return sections[i].title < sections[j].title
+	})
+	sort.Slice(others, func(i, j int) bool { return others[i].title < others[j].title })
+	sort.Slice(notes, func(i, j int) bool { return notes[i].body < notes[j].body })
+
+	var out []section
+	for _, s := range sections {
+		out = append(out, s.section)
+	}
+	out = append(out, others...)
+	return append(out, notes...)
+}
+
+// exitStatus returns the exit statuses of the command and their meaning, translated: the
+// standard ones, see ExitCode, overridden or completed by ExitStatus.
+func (ci *CmdInfo) exitStatus() (codes []int, meanings map[int]string) {
+	meanings = make(map[int]string)
+	for code, meaning := range exitStatuses {
+		meanings[code] = meaning
+	}
+	for code, meaning := range ci.ExitStatus {
+		meanings[code] = meaning
+	}
+	for code, meaning := range meanings {
+		meanings[code] = T(meaning)
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	return codes, meanings
+}
+
+// writeHelpSections writes the typed sections, then the custom ones, for GenerateHelpPage.
+func (ci *CmdInfo) writeHelpSections(sb *strings.Builder, width int) {
+	// writeItems writes "name: description" lines, long descriptions continuing under themselves
+	writeItems := func(title string, items [][2]string) {
+		sb.WriteString("  " + title + ":\n")
+		for _, item := range items {
+			for i, line := range wrapLine(item[0]+": "+item[1], width-6) {
+				if i == 0 {
+					sb.WriteString(fmt.Sprintf("    %s\n", line))
+				} else {
+					sb.WriteString(fmt.Sprintf("      %s\n", line))
+				}
+			}
+		}
+	}
+	writeBlock := func(title, body string) {
+		sb.WriteString("  " + title + ":\n")
+		sb.WriteString(indent(body, "    ") + "\n")
+	}
+
+	if len(ci.Examples) > 0 {
+		sb.WriteString("  " + T("Examples") + ":\n")
+		for i, ex := range ci.Examples {
+			if i > 0 {
+				sb.WriteString("\n")
+			}
+			if ex.Description != "" {
+				sb.WriteString(Wrap("    "+T(ex.Description)+":", width) + "\n")
+			}
+			sb.WriteString(fmt.Sprintf("      $ %s\n", ex.Command))
+		}
+	}
+
+	if ci.Behavior != "" {
+		sb.WriteString("  " + T("Behavior") + ":\n")
+		sb.WriteString(Wrap(indent(T(ci.Behavior), "    "), width) + "\n")
+	}
+
+	if len(ci.Extensions) > 0 {
+		sb.WriteString("  " + T("Extensions") + ":\n")
+		sb.WriteString(Wrap("    "+T(extensionsNote), width) + "\n")
+		for _, ext := range ci.Extensions {
+			for i, line := range wrapLine(ext.Name+": "+T(ext.Description), width-6) {
+				if i == 0 {
+					sb.WriteString(fmt.Sprintf("    %s\n", line))
+				} else {
+					sb.WriteString(fmt.Sprintf("      %s\n", line))
+				}
+			}
+		}
+	}
+
+	if envVars := ci.envVars(); len(envVars) > 0 {
+		var items [][2]string
+		for _, env := range envVars {
+			items = append(items, [2]string{env.Name, T(env.Description)})
+		}
+		writeItems(T("Environment"), items)
+	}
+
+	codes, meanings := ci.exitStatus()
+	var items [][2]string
+	for _, code := range codes {
+		items = append(items, [2]string{strconv.Itoa(code), meanings[code]})
+	}
+	writeItems(T("Exit status"), items)
+
+	for _, s := range ci.customSections() {
+		writeBlock(T(s.title), T(s.body))
+	}
+
+	if len(ci.SeeAlso) > 0 {
+		sb.WriteString("  " + T("See also") + ":\n")
+		sb.WriteString(Wrap("    "+strings.Join(ci.SeeAlso, ", "), width) + "\n")
+	}
+}
+
+// writeManSections writes the typed sections, then the custom ones, for GenerateManPage.
+func (ci *CmdInfo) writeManSections(sb *strings.Builder) {
+	if len(ci.Examples) > 0 {
+		sb.WriteString(".SH EXAMPLES\n")
+		for _, ex := range ci.Examples {
+			sb.WriteString(".PP\n")
+			if ex.Description != "" {
+				sb.WriteString(roffLines(T(ex.Description) + ":"))
+			}
+			sb.WriteString(".RS\n.nf\n")
+			sb.WriteString(roffLines("$ " + ex.Command))
+			sb.WriteString(".fi\n.RE\n")
+		}
+	}
+
+	if ci.Behavior != "" {
+		manSection(sb, "Behavior", T(ci.Behavior))
+	}
+
+	if len(ci.Extensions) > 0 {
+		sb.WriteString(".SH EXTENSIONS\n")
+		sb.WriteString(roffLines(T(extensionsNote)))
+		for _, ext := range ci.Extensions {
+			sb.WriteString(".TP\n")
+			sb.WriteString(".B " + roffEscape(ext.Name) + "\n")
+			sb.WriteString(roffLines(T(ext.Description)))
+		}
+	}
+
+	if envVars := ci.envVars(); len(envVars) > 0 {
+		sb.WriteString(".SH ENVIRONMENT\n")
+		for _, env := range envVars {
+			sb.WriteString(".TP\n")
+			sb.WriteString(".B " + roffEscape(env.Name) + "\n")
+			sb.WriteString(roffLines(T(env.Description)))
+		}
+	}
+
+	sb.WriteString(".SH \"EXIT STATUS\"\n")
+	codes, meanings := ci.exitStatus()
+	for _, code := range codes {
+		sb.WriteString(".TP\n")
+		sb.WriteString(fmt.Sprintf(".B %d\n", code))
+		sb.WriteString(roffLines(meanings[code]))
+	}
+
+	for _, s := range ci.customSections() {
+		manSection(sb, s.title, T(s.body))
+	}
+}
+
+// completionComment documents, as comments of a completion script, the environment variables
+// the command reads, since they can't be completed on its command line.
+func (ci *CmdInfo) completionComment() string {
+	envVars := ci.envVars()
+	if len(envVars) == 0 {
+		return ""
+	}
+	sb := &strings.Builder{}
+	sb.WriteString(fmt.Sprintf("# %s also reads these environment variables:\n", ci.Name))
+	for _, env := range envVars {
+		description := strings.Join(strings.Fields(env.Description), " ")
+		sb.WriteString(fmt.Sprintf("#   %s: %s\n", env.Name, description))
+	}
+	return sb.String()
+}
+
+// indent prefixes each line of text with prefix.
+func indent(text, prefix string) string {
+	return prefix + strings.ReplaceAll(text, "\n", "\n"+prefix)
+}
@@ -0,0 +1,88 @@
+package ccmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCustomSections(t *testing.T) {
+	ci := &CmdInfo{CustomFields: map[string]interface{}{
+		"Notes":     "n",
+		"7_Later":   "l",
+		"Zeta":      "z",
+		"2_Earlier": "e",
+		"Alpha":     "a",
+	}}
+	var got []string
+	for _, s := range ci.customSections() {
+		got = append(got, s.title+"="+s.body)
+	}
+	want := "Earlier=e Later=l Alpha=a Zeta=z Notes=n"
+	if strings.Join(got, " ") != want {
+		t.Errorf("customSections = %q, want %q", strings.Join(got, " "), want)
+	}
+}
+
+func TestTypedSections(t *testing.T) {
+	t.Setenv("COLUMNS", "80")
+	ci := &CmdInfo{
+		Name:        "demo",
+		Description: "Demonstrates sections",
+		Synopsis:    "[FILE]",
+		Examples:    []Example{{Description: "Print a file", Command: "demo file"}},
+		Behavior:    "Behaves",
+		EnvVars:     []EnvVar{{Name: "DEMO_PATH", Description: "Where to look"}},
+		ExitStatus:  map[int]string{2: "usage error", 0: "success", 1: "failure"},
+		SeeAlso:     []string{"walk(1)"},
+		CustomFields: map[string]interface{}{
+			"Notes":   "Noted",
+			"5_Extra": "More",
+		},
+		Flags: NewFlagSet("demo", 0),
+	}
+
+	help, err := ci.GenerateHelpPage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	order := []string{
+		"  Examples:\n    Print a file:\n      $ demo file\n",
+		"  Behavior:\n    Behaves\n",
+		"  Environment:\n    DEMO_PATH: Where to look\n",
+		"  Exit status:\n    0: success\n    1: failure\n    2: usage error\n",
+		"  Extra:\n    More\n",
+		"  Notes:\n    Noted\n",
+		"  See also:\n    walk(1)\n",
+	}
+	last := -1
+	for _, want := range order {
+		i := strings.Index(help, want)
+		if i < 0 {
+			t.Fatalf("help page lacks %q:\n%s", want, help)
+		}
+		if i < last {
+			t.Errorf("%q is out of order:\n%s", want, help)
+		}
+		last = i
+	}
+
+	man, err := ci.GenerateManPage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{".SH EXAMPLES\n", ".SH ENVIRONMENT\n.TP\n.B DEMO_PATH\n", ".SH \"EXIT STATUS\"\n.TP\n.B 0\n", ".SH SEE ALSO\nwalk(1)\n"} {
+		if !strings.Contains(man, want) {
+			t.Errorf("man page lacks %q:\n%s", want, man)
+		}
+	}
+
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		script, err := ci.GenerateCompletion(shell)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(script, "#   DEMO_PATH: Where to look\n") {
+			t.Errorf("%s completion doesn't document DEMO_PATH:\n%s", shell, script)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package ccmd
+
+import (
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+
+	"golang.org/x/term"
+)
+
+// Size used when it can't be determined, e.g: when no standard stream is a terminal.
+const (
+	DefaultTerminalWidth  = 80
+	DefaultTerminalHeight = 24
+)
+
+// TerminalSize is the size of a terminal in character cells.
+type TerminalSize struct {
+	Width  int
+	Height int
+}
+
+// TerminalFile returns the first of stdout, stderr and stdin that is a terminal, or nil if none is.
+func TerminalFile() *os.File {
+	for _, f := range []*os.File{os.Stdout, os.Stderr, os.Stdin} {
+		if IsTerminal(f) {
+			return f
+		}
+	}
+	return nil
+}
+
+// envSize returns the value of the environment variable name if it is a positive integer.
+func envSize(name string) int {
+	n, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || n <= 0 {
+		return 0
+	}
+	return n
+}
+
+// GetTerminalSize returns the size of the terminal. $COLUMNS and $LINES take precedence,
+// then the size of the terminal is asked (TIOCGWINSZ) through the first of stdout, stderr and
+// stdin that is one, falling back to DefaultTerminalWidth x DefaultTerminalHeight.
+func GetTerminalSize() TerminalSize {
+	return terminalSize(TerminalFile())
+}
+
+// terminalSize returns the size of the terminal f, which may be nil, see GetTerminalSize.
+func terminalSize(f *os.File) TerminalSize {
+	size := TerminalSize{Width: envSize("COLUMNS"), Height: envSize("LINES")}
+	if size.Width > 0 && size.Height > 0 {
+		return size
+	}
+	if f != nil {
+		if w, h, err := term.GetSize(int(f.Fd())); err == nil {
+			if size.Width == 0 {
+				size.Width = w
+			}
+			if size.Height == 0 {
+				size.Height = h
+			}
+		}
+	}
+	if size.Width <= 0 {
+		size.Width = DefaultTerminalWidth
+	}
+	if size.Height <= 0 {
+		size.Height = DefaultTerminalHeight
+	}
+	return size
+}
+
+// GetTerminalWidth returns the width of the terminal, see GetTerminalSize.
+func GetTerminalWidth() int {
+	return GetTerminalSize().Width
+}
+
+// WatchTerminalSize sends the current size of the terminal, and then its new size every time
+// it is resized (SIGWINCH), until stop is called. The channel is closed afterwards.
+func WatchTerminalSize() (sizes <-chan TerminalSize, stop func()) {
+	ch := make(chan TerminalSize, 1)
+	sigs := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	notifyResize(sigs)
+
+	go func() {
+		defer close(ch)
+		defer signal.Stop(sigs)
+		var last TerminalSize
+		for {
+			if size := GetTerminalSize(); size != last {
+				last = size
+				select {
+				case ch <- size:
+				case <-done:
+					return
+				}
+			}
+			select {
+			case <-sigs:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return ch, func() { once.Do(func() { close(done) }) }
+}
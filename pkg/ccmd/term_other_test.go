@@ -0,0 +1,14 @@
+//go:build !linux
+
+package ccmd
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+// openPty fails, pseudo-terminals being opened on Linux only by the tests.
+func openPty(t *testing.T, size TerminalSize) (*os.File, error) {
+	return nil, errors.New("no pseudo-terminal")
+}
@@ -0,0 +1,106 @@
+package ccmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTerminalSize(t *testing.T) {
+	ttySize := TerminalSize{Width: 132, Height: 43}
+	for _, tt := range []struct {
+		name           string
+		columns, lines string
+		file           string // "tty", "file" or "" for none
+		want           TerminalSize
+	}{
+		{name: "environment over the terminal", columns: "100", lines: "30", file: "tty", want: TerminalSize{100, 30}},
+		{name: "COLUMNS only", columns: "100", file: "tty", want: TerminalSize{100, 43}},
+		{name: "LINES only", lines: "30", file: "tty", want: TerminalSize{132, 30}},
+		{name: "invalid environment", columns: "wide", lines: "-1", file: "tty", want: ttySize},
+		{name: "terminal", file: "tty", want: ttySize},
+		{name: "environment without a terminal", columns: "100", lines: "30", want: TerminalSize{100, 30}},
+		{name: "no terminal", want: TerminalSize{DefaultTerminalWidth, DefaultTerminalHeight}},
+		{name: "regular file", file: "file", want: TerminalSize{DefaultTerminalWidth, DefaultTerminalHeight}},
+		{name: "regular file and COLUMNS", columns: "100", file: "file", want: TerminalSize{100, DefaultTerminalHeight}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("COLUMNS", tt.columns)
+			t.Setenv("LINES", tt.lines)
+			var f *os.File
+			switch tt.file {
+			case "tty":
+				pty, err := openPty(t, ttySize)
+				if err != nil {
+					t.Skipf("opening a pseudo-terminal: %v", err)
+				}
+				f = pty
+			case "file":
+				var err error
+				if f, err = os.Create(filepath.Join(t.TempDir(), "file")); err != nil {
+					t.Fatal(err)
+				}
+				defer f.Close()
+			}
+			if got := terminalSize(f); got != tt.want {
+				t.Errorf("terminalSize = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTerminfoColors(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		entry   string // in testdata/terminfo
+		data    []byte // if entry is ""
+		want    int
+		wantErr bool
+	}{
+		{name: "legacy format", entry: "l/legacy-256color", want: 256},
+		{name: "extended-number format", entry: "d/direct-color", want: 1 << 24},
+		{name: "no colors capability", entry: "f/few-numbers", want: -1},
+		{name: "truncated", entry: "t/truncated", wantErr: true},
+		{name: "header only", data: []byte{0x1a, 0x01, 0, 0, 0, 0, 14, 0, 0, 0, 0, 0}, wantErr: true},
+		{name: "shorter than the header", data: []byte{0x1a, 0x01, 0, 0}, wantErr: true},
+		{name: "bad magic", data: []byte{0x1a, 0x02, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, wantErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			data := tt.data
+			if tt.entry != "" {
+				var err error
+				if data, err = os.ReadFile(filepath.Join("testdata", "terminfo", tt.entry)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := parseTerminfoColors(data)
+			if (err != nil) != tt.wantErr || err == nil && got != tt.want {
+				t.Errorf("parseTerminfoColors = %d, %v, want %d (error: %v)", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTerminalColors(t *testing.T) {
+	t.Setenv("TERMINFO", filepath.Join("testdata", "terminfo"))
+	t.Setenv("TERMINFO_DIRS", "")
+	t.Setenv("HOME", t.TempDir())
+	for _, tt := range []struct {
+		term, colorterm string
+		want            ColorLevel
+	}{
+		{term: "legacy-256color", want: Colors256},
+		{term: "direct-color", want: TrueColor},
+		{term: "few-numbers", want: NoColors},
+		{term: "legacy-256color", colorterm: "truecolor", want: TrueColor},
+		{term: "truncated", want: Colors8}, // guessed from the name
+		{term: "unknown-16color", want: Colors16},
+		{term: "dumb", want: NoColors},
+	} {
+		t.Setenv("TERM", tt.term)
+		t.Setenv("COLORTERM", tt.colorterm)
+		if got := TerminalColors(); got != tt.want {
+			t.Errorf("TerminalColors with TERM=%s COLORTERM=%s = %v, want %v", tt.term, tt.colorterm, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package ccmd
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Magic numbers of compiled terminfo entries, see term(5).
+const (
+	terminfoMagic   = 0o432  // numbers are 16-bit
+	terminfoMagic32 = 0o1036 // numbers are 32-bit
+)
+
+// terminfoColors is the index of the "colors" (max_colors) numeric capability.
+const terminfoColors = 13
+
+// TerminalColors returns the color level of the terminal described by $TERM.
+// COLORTERM=truecolor|24bit announces 24-bit colors; otherwise the "colors" capability
+// of the terminfo entry is used, falling back to guessing from the name of the terminal.
+func TerminalColors() ColorLevel {
+	termName := os.Getenv("TERM")
+	if ct := os.Getenv("COLORTERM"); ct == "truecolor" || ct == "24bit" {
+		return TrueColor
+	}
+	if termName == "" || termName == "dumb" {
+		return NoColors
+	}
+	if colors, err := terminfoMaxColors(termName); err == nil {
+		return levelOf(colors)
+	}
+	switch {
+	case strings.HasSuffix(termName, "-direct"):
+		return TrueColor
+	case strings.Contains(termName, "256color"):
+		return Colors256
+	case strings.Contains(termName, "16color"):
+		return Colors16
+	}
+	return Colors8
+}
+
+// levelOf converts a number of colors into a ColorLevel.
+func levelOf(colors int) ColorLevel {
+	switch {
+	case colors >= 1<<24:
+		return TrueColor
+	case colors >= 256:
+		return Colors256
+	case colors >= 16:
+		return Colors16
+	case colors >= 8:
+		return Colors8
+	}
+	return NoColors
+}
+
+// terminfoDirs returns the directories searched for terminfo entries, in order.
+func terminfoDirs() []string {
+	var dirs []string
+	if dir := os.Getenv("TERMINFO"); dir != "" {
+		dirs = append(dirs, dir)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".terminfo"))
+	}
+	defaults := []string{"/etc/terminfo", "/lib/terminfo", "/usr/share/terminfo", "/usr/lib/terminfo", "/usr/share/lib/terminfo"}
+	if list, ok := os.LookupEnv("TERMINFO_DIRS"); ok {
+		for _, dir := range strings.Split(list, ":") {
+			if dir == "" {
+				dirs = append(dirs, defaults...)
+				continue
+			}
+			dirs = append(dirs, dir)
+		}
+		return dirs
+	}
+	return append(dirs, defaults...)
+}
+
+// terminfoMaxColors reads the "colors" capability of the compiled terminfo entry of termName.
+func terminfoMaxColors(termName string) (int, error) {
+	if strings.ContainsAny(termName, "/") {
+		return 0, fmt.Errorf("invalid terminal name %q", termName)
+	}
+	for _, dir := range terminfoDirs() {
+		// Entries are stored under their first letter, or its hex code on case-insensitive filesystems.
+		for _, sub := range []string{termName[:1], fmt.Sprintf("%x", termName[0])} {
+			data, err := os.ReadFile(filepath.Join(dir, sub, termName))
+			if err != nil {
+				continue
+			}
+			return parseTerminfoColors(data)
+		}
+	}
+	return 0, fmt.Errorf("no terminfo entry for %q", termName)
+}
+
+// parseTerminfoColors extracts the "colors" capability from a compiled terminfo entry.
+func parseTerminfoColors(data []byte) (int, error) {
+	if len(data) < 12 {
+		return 0, fmt.Errorf("terminfo entry too short")
+	}
+	header := make([]int, 6)
+	for i := range header {
+		header[i] = int(int16(binary.LittleEndian.Uint16(data[2*i:])))
+	}
+	numSize := 2
+	switch header[0] {
+	case terminfoMagic:
+	case terminfoMagic32:
+		numSize = 4
+	default:
+		return 0, fmt.Errorf("bad terminfo magic %#o", header[0])
+	}
+	namesSize, boolCount, numCount := header[1], header[2], header[3]
+	if numCount <= terminfoColors {
+		return -1, nil
+	}
+	offset := 12 + namesSize + boolCount
+	if offset%2 != 0 {
+		offset++ // numbers are aligned on an even byte
+	}
+	offset += terminfoColors * numSize
+	if offset+numSize > len(data) {
+		return 0, fmt.Errorf("terminfo entry too short")
+	}
+	if numSize == 4 {
+		return int(int32(binary.LittleEndian.Uint32(data[offset:]))), nil
+	}
+	return int(int16(binary.LittleEndian.Uint16(data[offset:]))), nil
+}
@@ -0,0 +1,84 @@
+package ccmd
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Notation is the way VisibleChar writes the characters that aren't printable.
+type Notation int
+
+const (
+	// CaretNotation writes control characters as ^X, DEL as ^?, and the other bytes that
+	// aren't printable as M- followed by the notation of the byte without its high bit, as
+	// cat -v does.
+	CaretNotation Notation = iota
+	// EscapeNotation writes backslashes as \\, the characters with an escape in C as \a, \b,
+	// \f, \r, \t and \v, and the other bytes that aren't printable as \ooo, three octal
+	// digits, as the l command of ed does.
+	EscapeNotation
+)
+
+// escapes are the characters with an escape in EscapeNotation, aside from their octal one.
+var escapes = map[byte]string{'\\': `\\`, '\a': `\a`, '\b': `\b`, '\f': `\f`, '\r': `\r`, '\t': `\t`, '\v': `\v`}
+
+// VisibleChar returns the notation of the character at the start of s, and its size in bytes.
+// The notation is "" for printable characters, which are written as they are: the printable
+// ASCII characters, backslash aside in EscapeNotation, and the valid UTF-8 characters that
+// are printable. The other characters are written byte by byte.
+func VisibleChar(s string, n Notation) (string, int) {
+	r, size := utf8.DecodeRuneInString(s)
+	var printable bool
+	switch {
+	case r < utf8.RuneSelf:
+		printable = r >= ' ' && r != '\x7f' && (n != EscapeNotation || r != '\\')
+	case r == utf8.RuneError && size == 1:
+		// an invalid byte
+	default:
+		printable = unicode.IsPrint(r)
+	}
+	if printable {
+		return "", size
+	}
+	var sb strings.Builder
+	for i := 0; i < size; i++ {
+		c := s[i]
+		switch {
+		case n == EscapeNotation && escapes[c] != "":
+			sb.WriteString(escapes[c])
+		case n == EscapeNotation:
+			fmt.Fprintf(&sb, `\%03o`, c)
+		default:
+			if c >= 0x80 {
+				sb.WriteString("M-")
+				c -= 0x80
+			}
+			switch {
+			case c < ' ':
+				sb.WriteString("^" + string(rune(c+'@')))
+			case c == '\x7f':
+				sb.WriteString("^?")
+			default:
+				sb.WriteByte(c)
+			}
+		}
+	}
+	return sb.String(), size
+}
+
+// Visible returns s with the characters that aren't printable written in notation n, see
+// VisibleChar.
+func Visible(s string, n Notation) string {
+	var sb strings.Builder
+	for len(s) > 0 {
+		v, size := VisibleChar(s, n)
+		if v == "" {
+			v = s[:size]
+		}
+		sb.WriteString(v)
+		s = s[size:]
+	}
+	return sb.String()
+}
@@ -0,0 +1,27 @@
+package ccmd
+
+import "testing"
+
+func TestVisible(t *testing.T) {
+	for _, tt := range []struct {
+		in       string
+		notation Notation
+		want     string
+	}{
+		{"plain text", CaretNotation, "plain text"},
+		{"a\tb\x01\x7f", CaretNotation, "a^Ib^A^?"},
+		{"\x80\x9b\xa0\xe9\xff", CaretNotation, "M-^@M-^[M- M-iM-^?"},
+		{"ñandú ☃ �", CaretNotation, "ñandú ☃ �"},
+		{"\u0085", CaretNotation, "M-BM-^E"},
+		{`back\slash`, CaretNotation, `back\slash`},
+		{`back\slash`, EscapeNotation, `back\\slash`},
+		{"\a\b\f\r\t\v", EscapeNotation, `\a\b\f\r\t\v`},
+		{"\x00\n\x1b[0m\x7f", EscapeNotation, `\000\012\033[0m\177`},
+		{"ñ\xffñ", EscapeNotation, `ñ\377ñ`},
+		{"\u0085", EscapeNotation, `\302\205`},
+	} {
+		if got := Visible(tt.in, tt.notation); got != tt.want {
+			t.Errorf("Visible(%q, %v) = %q, want %q", tt.in, tt.notation, got, tt.want)
+		}
+	}
+}
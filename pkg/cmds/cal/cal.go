@@ -0,0 +1,311 @@
+// Copyright (c) 2024-2024 xplshn						[3BSD]
+// For more details refer to https://github.com/xplshn/a-utils
+package cal
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xplshn/a-utils/pkg/ccmd"
+)
+
+var (
+	// color decides whether the current date can be highlighted
+	color = &ccmd.Color{}
+
+	months = [12]string{
+		"January", "February", "March", "April",
+		"May", "June", "July", "August",
+		"September", "October", "November", "December",
+	}
+	daysSundayFirst = [7]string{"Su", "Mo", "Tu", "We", "Th", "Fr", "Sa"}
+	daysMondayFirst = [7]string{"Mo", "Tu", "We", "Th", "Fr", "Sa", "Su"}
+)
+
+func init() {
+	ccmd.Register("cal", Main)
+}
+
+// Main runs cal, see ccmd.MainFunc.
+func Main(args []string, stdio ccmd.Stdio) int {
+	fs := ccmd.NewFlagSet("cal", flag.ContinueOnError)
+	cmdInfo := &ccmd.CmdInfo{
+		Authors:     []string{"xplshn"},
+		Repository:  "https://github.com/xplshn/a-utils",
+		Name:        "cal",
+		Synopsis:    "<|-h|-j|-m|-w|-y|--color=auto|always|never|> <month> <year>",
+		Description: "Displays a calendar",
+		CustomFields: map[string]interface{}{
+			"Notes": "This version of cal does not account for the Gregorian Reformation that happened in 1752 after the 2nd of September.\nThis implementation is pure technical debt and is NOT conformant to https://man.openbsd.org/cal.1\nThat's going to change soon I hope.",
+		},
+		Flags: fs,
+	}
+
+	julian := fs.Bool("j", false, "Use Julian dates")
+	monday := fs.Bool("m", false, "Week starts on Monday")
+	yearly := fs.Bool("y", false, "Display the entire year")
+	weekNum := fs.Bool("w", false, "Display week numbers")
+	noHighlight := fs.Bool("h", false, "Don't highlight current date")
+	colorMode := ccmd.ColorAuto
+	fs.Var(&colorMode, "color", "Highlight the current date: 'always', 'auto', 'never'")
+
+	helpPage, err := cmdInfo.GenerateHelpPage()
+	if err != nil {
+		ccmd.Fatalf("generating help page: %v", err)
+	}
+
+	fs.Usage = func() {
+		fmt.Fprint(stdio.Out, helpPage)
+	}
+
+	cmdInfo.ParseArgs(args[1:])
+	args = fs.Args()
+	color = stdio.Color(colorMode)
+
+	if *julian && *weekNum {
+		ccmd.Exitf(ccmd.ExitUsage, "-j and -w options are mutually exclusive")
+	}
+
+	if len(args) > 3 {
+		ccmd.Exitf(ccmd.ExitUsage, "too many operands, usage: cal [-jmywh] [[[DAY] MONTH] YEAR]")
+	}
+
+	var year, month, day int
+	switch len(args) {
+	case 0:
+		t := time.Now()
+		year = t.Year()
+		month = int(t.Month())
+		day = t.Day()
+	case 1:
+		year = parseInt(args[0])
+		if year < 1 || year > 9999 {
+			ccmd.Exitf(ccmd.ExitUsage, "invalid year")
+		}
+		printYear(stdio.Out, year, *julian, *monday, *weekNum, *noHighlight, day, month)
+		return 0
+	case 2:
+		month = parseMonth(args[0])
+		year = parseInt(args[1])
+		if year < 1 || year > 9999 {
+			ccmd.Exitf(ccmd.ExitUsage, "invalid year")
+		}
+		if month < 1 || month > 12 {
+			ccmd.Exitf(ccmd.ExitUsage, "invalid month")
+		}
+	case 3:
+		day = parseInt(args[0])
+		month = parseMonth(args[1])
+		year = parseInt(args[2])
+		if year < 1 || year > 9999 {
+			ccmd.Exitf(ccmd.ExitUsage, "invalid year")
+		}
+		if month < 1 || month > 12 {
+			ccmd.Exitf(ccmd.ExitUsage, "invalid month")
+		}
+		if day < 1 || day > 31 {
+			ccmd.Exitf(ccmd.ExitUsage, "invalid day")
+		}
+	}
+
+	if *yearly {
+		printYear(stdio.Out, year, *julian, *monday, *weekNum, *noHighlight, day, month)
+	} else if month == 0 {
+		printMonth(stdio.Out, int(time.Now().Month()), year, *julian, *monday, *weekNum, *noHighlight, day)
+	} else {
+		printMonth(stdio.Out, month, year, *julian, *monday, *weekNum, *noHighlight, day)
+	}
+	return 0
+}
+
+func printMonth(w io.Writer, month, year int, julian, monday, weekNum, noHighlight bool, highlightDay int) {
+	content := calculateMonthContent(month, year, julian, monday, weekNum, noHighlight, highlightDay)
+	header := fmt.Sprintf("%s %d", months[month-1], year)
+	fmt.Fprintln(w, ccmd.CFormatCenter(header, ccmd.RelativeTo(content)))
+	fmt.Fprintln(w, content)
+}
+
+func calculateMonthContent(month, year int, julian, monday, weekNum, noHighlight bool, highlightDay int) string {
+	var content strings.Builder
+	firstDayOfMonth := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Local)
+	lastDayOfMonth := firstDayOfMonth.AddDate(0, 1, -1)
+	weekDay := int(firstDayOfMonth.Weekday())
+
+	if monday {
+		weekDay = (weekDay + 6) % 7
+	}
+
+	if julian {
+		if monday {
+			content.WriteString(" Mo  Tu  We  Th  Fr  Sa  Su\n")
+		} else {
+			content.WriteString(" Su  Mo  Tu  We  Th  Fr  Sa\n")
+		}
+	} else {
+		if monday {
+			content.WriteString(strings.Join(daysMondayFirst[:], " ") + "\n")
+		} else {
+			content.WriteString(strings.Join(daysSundayFirst[:], " ") + "\n")
+		}
+	}
+
+	var lines []string
+	var currentLine string
+
+	for day := 1; day <= lastDayOfMonth.Day(); day++ {
+		if julian {
+			julianDay := firstDayOfMonth.YearDay() + day - 1
+			if !noHighlight && day == highlightDay && month == int(time.Now().Month()) {
+				currentLine += color.Paint(fmt.Sprintf("%3d", julianDay), ccmd.BgRed) + " "
+			} else {
+				currentLine += fmt.Sprintf("%3d ", julianDay)
+			}
+		} else {
+			if !noHighlight && day == highlightDay && month == int(time.Now().Month()) {
+				currentLine += color.Paint(fmt.Sprintf("%2d", day), ccmd.BgRed) + " "
+			} else {
+				currentLine += fmt.Sprintf("%2d ", day)
+			}
+		}
+
+		if (weekDay+day)%7 == 0 {
+			if weekNum {
+				weekNumber := getWeekNumber(day, month, year, monday)
+				currentLine += fmt.Sprintf(" [%2d]", weekNumber)
+			}
+			lines = append(lines, currentLine)
+			currentLine = ""
+		}
+	}
+
+	if currentLine != "" {
+		lines = append(lines, currentLine)
+	}
+
+	restOfLines := strings.Join(lines[1:], "\n")
+	content.WriteString(ccmd.CFormatRight(lines[0], ccmd.RelativeTo(restOfLines)) + "\n")
+	content.WriteString(restOfLines + "\n")
+
+	return content.String()
+}
+
+func getWeekNumber(day, month, year int, monday bool) int {
+	firstDayOfYear := time.Date(year, time.January, 1, 0, 0, 0, 0, time.Local)
+	firstDayOfMonth := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.Local)
+	currentDate := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.Local)
+
+	if monday {
+		_, week := currentDate.ISOWeek()
+		return week
+	} else {
+		daysSinceStartOfYear := currentDate.Sub(firstDayOfYear).Hours() / 24
+		weekNumber := int(daysSinceStartOfYear/7) + 1
+		if firstDayOfMonth.Weekday() == time.Sunday {
+			weekNumber++
+		}
+		return weekNumber
+	}
+}
+
+func printYear(w io.Writer, year int, julian, monday, weekNum, noHighlight bool, highlightDay, highlightMonth int) {
+	fmt.Fprintf(w, "                               %d\n\n", year)
+	if julian {
+		printTwoMonths(w, 1, year, julian, monday, weekNum, noHighlight, highlightDay, highlightMonth, true)
+		printTwoMonths(w, 3, year, julian, monday, weekNum, noHighlight, highlightDay, highlightMonth, true)
+		printTwoMonths(w, 5, year, julian, monday, weekNum, noHighlight, highlightDay, highlightMonth, true)
+		printTwoMonths(w, 7, year, julian, monday, weekNum, noHighlight, highlightDay, highlightMonth, true)
+		printTwoMonths(w, 9, year, julian, monday, weekNum, noHighlight, highlightDay, highlightMonth, true)
+		printTwoMonths(w, 11, year, julian, monday, weekNum, noHighlight, highlightDay, highlightMonth, true)
+	} else {
+		for m := 1; m <= 12; m += 3 {
+			printThreeMonths(w, m, year, julian, monday, weekNum, noHighlight, highlightDay, highlightMonth)
+		}
+	}
+}
+
+func printTwoMonths(w io.Writer, startMonth, year int, julian, monday, weekNum, noHighlight bool, highlightDay, highlightMonth int, yearly bool) {
+	monthStrings := make([][]string, 2)
+	for i := 0; i < 2; i++ {
+		monthStrings[i] = getMonthStrings(startMonth+i, year, julian, monday, weekNum, noHighlight, highlightDay, highlightMonth, yearly)
+	}
+
+	maxLines := 0
+	for i := 0; i < 2; i++ {
+		if len(monthStrings[i]) > maxLines {
+			maxLines = len(monthStrings[i])
+		}
+	}
+
+	for line := 0; line < maxLines; line++ {
+		for i := 0; i < 2; i++ {
+			if line < len(monthStrings[i]) {
+				fmt.Fprint(w, ccmd.PadRight(monthStrings[i][line], 35))
+			} else {
+				fmt.Fprint(w, strings.Repeat(" ", 35))
+			}
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w)
+}
+
+func printThreeMonths(w io.Writer, startMonth, year int, julian, monday, weekNum, noHighlight bool, highlightDay, highlightMonth int) {
+	monthStrings := make([][]string, 3)
+	for i := 0; i < 3; i++ {
+		monthStrings[i] = getMonthStrings(startMonth+i, year, julian, monday, weekNum, noHighlight, highlightDay, highlightMonth, false)
+	}
+
+	maxLines := 0
+	for i := 0; i < 3; i++ {
+		if len(monthStrings[i]) > maxLines {
+			maxLines = len(monthStrings[i])
+		}
+	}
+
+	for line := 0; line < maxLines; line++ {
+		for i := 0; i < 3; i++ {
+			if line < len(monthStrings[i]) {
+				fmt.Fprint(w, ccmd.PadRight(monthStrings[i][line], 22))
+			} else {
+				fmt.Fprint(w, strings.Repeat(" ", 22))
+			}
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+func getMonthStrings(month, year int, julian, monday, weekNum, noHighlight bool, highlightDay, highlightMonth int, yearly bool) []string {
+	var lines []string
+	content := calculateMonthContent(month, year, julian, monday, weekNum, noHighlight, highlightDay)
+	header := fmt.Sprintf("%s", months[month-1])
+	maxLength := ccmd.RelativeTo(content)
+	centeredHeader := ccmd.CFormatCenter(header, maxLength)
+	lines = append(lines, centeredHeader)
+	lines = append(lines, strings.Split(content, "\n")...)
+	return lines
+}
+
+func parseInt(str string) int {
+	val, err := strconv.Atoi(str)
+	if err != nil {
+		ccmd.Exitf(ccmd.ExitUsage, "invalid number %q", str)
+	}
+	return val
+}
+
+func parseMonth(str string) int {
+	for i, m := range months {
+		if strings.EqualFold(m, str) {
+			return i + 1
+		}
+	}
+	val, err := strconv.Atoi(str)
+	if err != nil {
+		ccmd.Exitf(ccmd.ExitUsage, "invalid month %q", str)
+	}
+	return val
+}
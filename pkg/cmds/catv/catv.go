@@ -0,0 +1,141 @@
+// Copyright (c) 2024-2024 xplshn                       [3BSD]
+// For more details refer to https://github.com/xplshn/a-utils
+package catv
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/xplshn/a-utils/pkg/ccmd"
+)
+
+// removeAnsiSequences removes ANSI escape sequences from the given content.
+func removeAnsiSequences(content string) string {
+	ansiEscape := regexp.MustCompile(`\x1b\[[0-9]*(?:;[0-9]*)*[a-zA-Z]`)
+	return ansiEscape.ReplaceAllString(content, "")
+}
+
+// makeNonPrintableVisible converts non-printable characters to a visible format.
+func makeNonPrintableVisible(content string, showTabs bool, showEndLines bool) string {
+	var visibleContent strings.Builder
+	for i := 0; i < len(content); {
+		visible, size := ccmd.VisibleChar(content[i:], ccmd.CaretNotation)
+		switch {
+		case content[i] == '\n':
+			visibleContent.WriteString("\n")
+			if showEndLines && i != len(content)-1 {
+				visibleContent.WriteString("$")
+			}
+		case content[i] == '\t' && !showTabs:
+			visibleContent.WriteString("\t")
+		case visible == "":
+			visibleContent.WriteString(content[i : i+size])
+		default:
+			visibleContent.WriteString(visible)
+		}
+		i += size
+	}
+	if showEndLines && len(content) > 0 && content[len(content)-1] == '\n' {
+		visibleContent.WriteString("$")
+	}
+	return visibleContent.String()
+}
+
+// processFile reads the file content, processes it according to the flags, and writes the result to the output.
+func processFile(reader io.Reader, writer io.Writer, removeAnsiFlag bool, showNonPrintable bool, showTabs bool, showEndLines bool) error {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("error reading input: %v", err)
+	}
+
+	var finalContent string
+	if removeAnsiFlag {
+		finalContent = removeAnsiSequences(string(content))
+	} else if showNonPrintable {
+		finalContent = makeNonPrintableVisible(string(content), showTabs, showEndLines)
+	} else {
+		finalContent = string(content)
+	}
+
+	_, err = writer.Write([]byte(finalContent))
+	return err
+}
+
+// run processes each file provided in args or reads from stdin if no files are specified.
+func run(stdin io.Reader, stdout io.Writer, args []string, removeAnsiFlag bool, showNonPrintable bool, showTabs bool, showEndLines bool) error {
+	if len(args) == 0 {
+		return processFile(stdin, stdout, removeAnsiFlag, showNonPrintable, showTabs, showEndLines)
+	}
+
+	for _, file := range args {
+		var reader io.Reader
+		if file == "-" { // Such a naughty hack, but people use it anyways...
+			reader = stdin
+		} else {
+			f, err := os.Open(file)
+			if err != nil {
+				return fmt.Errorf("failed to open file %s: %w", file, err)
+			}
+			defer f.Close()
+			reader = f
+		}
+		if err := processFile(reader, stdout, removeAnsiFlag, showNonPrintable, showTabs, showEndLines); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	ccmd.Register("catv", Main)
+}
+
+// Main runs catv, see ccmd.MainFunc.
+func Main(args []string, stdio ccmd.Stdio) int {
+	fs := ccmd.NewFlagSet("catv", flag.ContinueOnError)
+	cmdInfo := &ccmd.CmdInfo{
+		Authors:     []string{"xplshn"},
+		Repository:  "https://github.com/xplshn/a-utils",
+		Name:        "catv",
+		Synopsis:    "<|-vte|-r|-A|> [FILE/s]",
+		Description: "Provides a non-harmful way to make non-printable characters visible from the specified files",
+		Flags:       fs,
+	}
+
+	removeAnsiFlag := fs.Bool("r", false, "Remove ANSI escape sequences")
+	showNonPrintable := fs.Bool("v", false, "Show non-printing characters as ^x or M-x")
+	showTabs := fs.Bool("t", false, "Show tabs as ^I")
+	showEndLines := fs.Bool("e", false, "Show end of lines with $")
+	showAll := fs.Bool("A", false, "Same as -vte")
+
+	helpPage, err := cmdInfo.GenerateHelpPage()
+	if err != nil {
+		ccmd.Fatalf("generating help page: %v", err)
+	}
+
+	fs.Usage = func() { fmt.Fprint(stdio.Out, helpPage) }
+
+	cmdInfo.ParseArgs(args[1:])
+
+	args = fs.Args()
+
+	// If no flags are used, set showNonPrintable to true
+	if !*removeAnsiFlag && !*showTabs && !*showEndLines && !*showAll {
+		*showNonPrintable = true
+	}
+
+	if *showAll {
+		*showNonPrintable = true
+		*showTabs = true
+		*showEndLines = true
+	}
+
+	if err := run(stdio.In, stdio.Out, args, *removeAnsiFlag, *showNonPrintable, *showTabs, *showEndLines); err != nil {
+		ccmd.Fatalf("%v", err)
+	}
+	return 0
+}
@@ -0,0 +1,193 @@
+// Copyright (c) 2024-2024 xplshn                       [3BSD]
+// For more details refer to https://github.com/xplshn/a-utils
+package ccat
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/xplshn/a-utils/pkg/ccmd"
+)
+
+// color decides whether, and with how many colors, the output is highlighted
+var color = &ccmd.Color{}
+
+func init() {
+	ccmd.Register("ccat", Main)
+}
+
+// Main runs ccat, see ccmd.MainFunc.
+func Main(args []string, stdio ccmd.Stdio) int {
+	fs := ccmd.NewFlagSet("ccat", flag.ContinueOnError)
+	stylesFlag := fs.Bool("styles", false, "List available styles")
+	styleFile := fs.String("style", "", "Load custom style from file")
+	colorMode := ccmd.ColorAuto
+	fs.Var(&colorMode, "color", "Highlight the output: 'always', 'auto', 'never'")
+
+	cmdInfo := &ccmd.CmdInfo{
+		Name:        "ccat",
+		Authors:     []string{"xplshn"},
+		Repository:  "https://github.com/xplshn/a-utils",
+		Description: "Concatenates files and prints them to stdout with Syntax Highlighting",
+		Synopsis:    "<|--styles|--style [SYTHX_FILE]|--color=auto|always|never|> [FILE/s]",
+		Behavior:    "If no files are specified, read from stdin.\nWhen the output is not a terminal, files are printed as-is, unless --color=always is used.",
+		EnvVars: []ccmd.EnvVar{
+			{Name: "A_SYHX_COLOR_SCHEME", Description: "Style to use, any of the lines that `--styles` outputs"},
+			{Name: "A_SYHX_CUSTOM_COLOR_SCHEME", Description: "Style file to load, as with --style, which it overrides"},
+			{Name: "A_SYHX_FORMATTER", Description: "Formatter to use: terminal8, terminal16, terminal256 or terminal16m. By default it depends on the colors the terminal supports"},
+			{Name: "NO_COLOR", Description: "If set, don't highlight the output unless --color=always is used"},
+			{Name: "CLICOLOR_FORCE", Description: "If set to anything but 0, highlight the output even when it isn't a terminal"},
+		},
+		Completions: map[string]ccmd.Completion{
+			"style": {Files: true},
+		},
+		Flags: fs,
+	}
+
+	helpPage, err := cmdInfo.GenerateHelpPage()
+	if err != nil {
+		ccmd.Fatalf("generating help page: %v", err)
+	}
+	fs.Usage = func() {
+		fmt.Fprint(stdio.Out, helpPage)
+	}
+	cmdInfo.ParseArgs(args[1:])
+
+	if *stylesFlag {
+		fmt.Fprintln(stdio.Out, "Available styles:")
+		for _, style := range styles.Names() {
+			fmt.Fprintln(stdio.Out, style)
+		}
+		return 0
+	}
+
+	var customStyleName string
+	if envStyleFile := os.Getenv("A_SYHX_CUSTOM_COLOR_SCHEME"); envStyleFile != "" {
+		*styleFile = envStyleFile
+	}
+	if *styleFile != "" {
+		var err error
+		customStyleName, err = loadCustomStyle(*styleFile)
+		if err != nil {
+			ccmd.Fatalf("loading custom style: %v", err)
+		}
+	}
+
+	color = stdio.Color(colorMode)
+
+	args = fs.Args()
+	if err := run(stdio.In, stdio.Out, customStyleName, args...); err != nil {
+		ccmd.Fatalf("%v", err)
+	}
+	return 0
+}
+
+func loadCustomStyle(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open style file %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	style, err := chroma.NewXMLStyle(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse style file %s: %v", filePath, err)
+	}
+
+	styles.Register(style)
+	fmt.Printf("Loaded custom style: %s\n", style.Name)
+	return style.Name, nil
+}
+
+func run(stdin io.Reader, stdout io.Writer, customStyleName string, args ...string) error {
+	if len(args) == 0 {
+		return highlightCat(stdin, stdout, "stdin", customStyleName)
+	}
+
+	for _, file := range args {
+		var reader io.Reader
+		if file == "-" { // Such a naughty hack, but people use it anyways...
+			reader = stdin
+		} else {
+			f, err := os.Open(file)
+			if err != nil {
+				return fmt.Errorf("failed to open file %s: %w", file, err)
+			}
+			defer f.Close()
+			reader = f
+		}
+		if err := highlightCat(reader, stdout, file, customStyleName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func highlightCat(reader io.Reader, writer io.Writer, fileName string, customStyleName string) error {
+	contents, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	// Sanitize input
+	sanitizedContents := sanitizeInput(string(contents))
+
+	// Detect the language from content
+	lexer := lexers.Analyse(sanitizedContents)
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	lexer = chroma.Coalesce(lexer)
+
+	var style *chroma.Style
+	if customStyleName != "" {
+		style = styles.Get(customStyleName)
+	} else {
+		style = styles.Get(os.Getenv("A_SYHX_COLOR_SCHEME"))
+		if style == nil {
+			style = styles.Fallback
+		}
+	}
+
+	formatterName := os.Getenv("A_SYHX_FORMATTER")
+	if formatterName == "" || !color.Enabled() {
+		formatterName = color.ChromaFormatter()
+	}
+
+	formatter := formatters.Get(formatterName)
+	if formatter == nil {
+		formatter = formatters.Fallback
+	}
+
+	iterator, err := lexer.Tokenise(nil, string(contents))
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := formatter.Format(&buf, style, iterator); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(writer, &buf)
+	return err
+}
+
+func sanitizeInput(input string) string {
+	var sanitized strings.Builder
+	for _, r := range input {
+		if unicode.IsPrint(r) && !unicode.IsControl(r) {
+			sanitized.WriteRune(r)
+		}
+	}
+	return sanitized.String()
+}
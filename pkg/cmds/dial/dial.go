@@ -0,0 +1,231 @@
+// Copyright (c) as 2016, 2024-2024 xplshn				[3BSD]
+// For more details refer to https://github.com/xplshn/a-utils
+package dial
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os/exec"
+	"time"
+
+	"github.com/xplshn/a-utils/pkg/ccmd"
+)
+
+const (
+	Debug = false
+)
+
+func init() {
+	ccmd.Register("dial", Main)
+}
+
+// Main runs dial, see ccmd.MainFunc.
+func Main(args []string, stdio ccmd.Stdio) int {
+	fs := ccmd.NewFlagSet("dial", flag.ContinueOnError)
+	cmdInfo := &ccmd.CmdInfo{
+		Name:        "dial",
+		Authors:     []string{"as"},
+		Repository:  "https://github.com/as/dial",
+		Description: "Dial a network endpoint and optionally run a command for each new connection.",
+		Synopsis:    "dial [options] host:port [cmd ...]",
+		Examples: []ccmd.Example{
+			{Description: "Speak HTTP", Command: "echo GET / HTTP/1.1 | dial example.com:80"},
+			{Description: "RDP tunnel through port 80", Command: "listen :80 dial 10.2.64.20:3389"},
+		},
+		Behavior: `Dial establishes a connection with the listener on the
+remote host and runs cmd. Cmd's three standard file
+descriptors (stdin, stdout+stderr) are connected to the
+listener via proto (default tcp).
+
+If cmd is not given, the standard file descriptors are
+instead connected to dial's standard input, output, and
+error.`,
+		SeeAlso: []string{"listen(1)"},
+		Flags:   fs,
+	}
+
+	showHelp := fs.Bool("h", false, "Show help")
+	verbose := fs.Bool("v", false, "Verbose output")
+	keepAlive := fs.Bool("k", false, "Enable TCP keep-alive")
+	muxMode := fs.Bool("m", false, "Mux: broadcast traffic to all clients")
+	activeLimit := fs.Int("a", 4096, "Active connection limit")
+	protocol := fs.String("n", "tcp4", "Network protocol")
+
+	helpPage, err := cmdInfo.GenerateHelpPage()
+	if err != nil {
+		ccmd.Fatalf("generating help page: %v", err)
+	}
+
+	fs.Usage = func() {
+		fmt.Fprint(stdio.Out, helpPage)
+	}
+
+	cmdInfo.ParseArgs(args[1:])
+
+	if *showHelp {
+		fs.Usage()
+		return int(ccmd.ExitSuccess)
+	}
+	if len(fs.Args()) == 0 {
+		fs.Usage()
+		return int(ccmd.ExitUsage)
+	}
+
+	server := fs.Args()[0]
+	command := fs.Args()[1:]
+
+	// An interrupt closes the connection, commands run for it are killed
+	ctx := ccmd.Context()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, *protocol, server)
+	if err != nil {
+		handleFatalError(err)
+	}
+	context.AfterFunc(ctx, func() { conn.Close() })
+
+	logVerbose(stdio.Err, *verbose, "connected to:", conn.RemoteAddr())
+
+	// Enable TCP keep-alive if the flag is set
+	if tcpConn, ok := conn.(*net.TCPConn); ok && *keepAlive {
+		tcpConn.SetKeepAlive(true)
+		tcpConn.SetKeepAlivePeriod(3 * time.Minute) // Adjust the keep-alive period as needed
+	}
+
+	if *muxMode {
+		handleMultiplexedStream(ctx, stdio, conn, *activeLimit, *keepAlive, command...)
+	} else {
+		handleStream(ctx, stdio, conn, *activeLimit, *keepAlive, command...)
+	}
+	return int(ccmd.ExitCodeOf(context.Cause(ctx)))
+}
+
+func handleStream(ctx context.Context, stdio ccmd.Stdio, conn net.Conn, limit int, keepAlive bool, command ...string) {
+	var err error
+	if len(command) == 0 {
+		err = handleTerminalIO(stdio, conn)
+	} else {
+		err = executeCommand(ctx, conn, command[0], command[1:]...)
+	}
+	if err != nil && ctx.Err() == nil {
+		ccmd.Warnf("%v", err)
+	}
+}
+
+func handleMultiplexedStream(ctx context.Context, stdio ccmd.Stdio, conn net.Conn, limit int, keepAlive bool, command ...string) {
+	concurrencyLimit := make(chan bool, limit)
+	connChan := make(chan io.ReadWriter)
+
+	go multiplexer(connChan)
+
+	for {
+		select {
+		case concurrencyLimit <- true:
+		case <-ctx.Done():
+			return
+		}
+		go func() {
+			defer func() { <-concurrencyLimit }()
+			defer conn.Close()
+
+			connChan <- conn
+			muxedConn := <-connChan
+
+			if len(command) == 0 {
+				if err := handleTerminalIO(stdio, muxedConn.(net.Conn)); err != nil {
+					ccmd.Warnf("%v", err)
+				}
+			} else if err := executeCommand(ctx, muxedConn, command[0], command[1:]...); err != nil {
+				ccmd.Warnf("%v", err)
+			}
+		}()
+	}
+}
+
+func multiplexer(connChan chan io.ReadWriter) {
+	writers := []io.Writer{}
+	readers := []io.Reader{}
+
+	for conn := range connChan {
+		writers = append(writers, conn)
+		readers = append(readers, conn)
+
+		multiReader := io.MultiReader(readers...)
+		multiWriter := io.MultiWriter(writers...)
+
+		connChan <- bufio.NewReadWriter(bufio.NewReader(multiReader), bufio.NewWriter(multiWriter))
+	}
+}
+
+func handleTerminalIO(stdio ccmd.Stdio, conn net.Conn) error {
+	finishChan := make(chan error)
+	defer close(finishChan)
+
+	go func() {
+		_, err := io.Copy(stdio.Out, conn)
+		finishChan <- err
+	}()
+
+	go func() {
+		_, err := io.Copy(conn, stdio.In)
+		if err != nil {
+			ccmd.Warnf("stdin|net: %v", err)
+		}
+	}()
+
+	return <-finishChan
+}
+
+func executeCommand(ctx context.Context, rw io.ReadWriter, cmd string, args ...string) error {
+	cmdExec := exec.CommandContext(ctx, cmd, args...)
+	stdin, err := cmdExec.StdinPipe()
+	if err != nil {
+		return err
+	}
+	pipeReader, pipeWriter := io.Pipe()
+	defer pipeWriter.Close()
+
+	cmdExec.Stdout, cmdExec.Stderr = pipeWriter, pipeWriter
+
+	if err := cmdExec.Start(); err != nil {
+		return err
+	}
+
+	finishChan := make(chan error)
+	defer close(finishChan)
+
+	go func() {
+		_, err := io.Copy(stdin, rw)
+		pipeWriter.Close()
+		finishChan <- err
+	}()
+
+	go func() {
+		_, err := io.Copy(rw, pipeReader)
+		if err != nil {
+			ccmd.Warnf("command|net: %v", err)
+		}
+	}()
+
+	if err := <-finishChan; err != nil {
+		ccmd.Warnf("net|command: %v", err)
+	}
+
+	stdin.Close()
+	return cmdExec.Wait()
+}
+
+func handleFatalError(err error) {
+	if err != nil {
+		ccmd.Fatalf("%v", err)
+	}
+}
+
+func logVerbose(w io.Writer, verbose bool, args ...interface{}) {
+	if verbose {
+		fmt.Fprintln(w, args...)
+	}
+}
@@ -3,7 +3,7 @@
 // license that can be found in the LICENSE file.
 
 // address.go - contains methods for FileBuffer for line address resolution
-package main
+package ed
 
 import (
 	"fmt"
@@ -152,7 +152,7 @@ func (f *FileBuffer) ResolveAddr(cmd string) (line, cmdOffset int, e error) {
 			restr = r[0][3]
 		}
 		var re *regexp.Regexp
-		if re, e = regexp.Compile(restr); e != nil {
+		if re, e = compileRE(restr, r[0][0][0]); e != nil {
 			e = fmt.Errorf("invalid regexp: %v", e)
 			return
 		}
@@ -2,7 +2,7 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-package main
+package ed
 
 import (
 	"fmt"
@@ -54,7 +54,7 @@ func TestResolveOffset(t *testing.T) {
 	} {
 		t.Run(tt.name, func(t *testing.T) {
 			gotOffset, gotOffsetCmd, err := buffer.ResolveOffset(tt.input)
-			buffer = &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: []int{0, 1, 2, 3}, addr: 2}
+			buffer = &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: ropeOf(0, 1, 2, 3), addr: 2}
 			if err != nil {
 				if err.Error() != tt.want {
 					t.Errorf("ResolveAddr() = %q, want: %q", err.Error(), tt.want)
@@ -124,7 +124,7 @@ func TestResolveAddr(t *testing.T) {
 		},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
-			buffer = &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: []int{0, 1, 2, 3}, addr: 2, marks: map[byte]int{'t': 5}}
+			buffer = &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: ropeOf(0, 1, 2, 3), addr: 2, marks: map[byte]int{'t': 5}}
 			gotLine, gotOffset, err := buffer.ResolveAddr(tt.input)
 			if err != nil {
 				if err.Error() != tt.want {
@@ -173,7 +173,7 @@ func TestResolveAddrs(t *testing.T) {
 		},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
-			buffer = &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: []int{0, 1, 2, 3}}
+			buffer = &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: ropeOf(0, 1, 2, 3)}
 			got1, got2, err := buffer.ResolveAddrs(tt.input)
 			if err != nil {
 				if err.Error() != tt.want {
@@ -212,7 +212,7 @@ func TestAddrValue(t *testing.T) {
 		},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
-			buffer = &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: []int{0, 1, 2, 3}}
+			buffer = &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: ropeOf(0, 1, 2, 3)}
 			got, err := buffer.AddrValue(tt.input)
 			if err != nil {
 				if err != tt.err {
@@ -256,7 +256,7 @@ func TestAddrRange(t *testing.T) {
 		},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
-			buffer = &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: []int{0, 1, 2, 3}}
+			buffer = &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: ropeOf(0, 1, 2, 3)}
 			got, err := buffer.AddrRange(tt.input)
 			if err != nil {
 				if err.Error() != tt.err.Error() {
@@ -295,7 +295,7 @@ func TestAddrRangeOrLine(t *testing.T) {
 		},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
-			buffer = &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: []int{0, 1, 2, 3}}
+			buffer = &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: ropeOf(0, 1, 2, 3)}
 			got, err := buffer.AddrRangeOrLine(tt.input)
 			if err != nil {
 				if err.Error() != tt.err.Error() {
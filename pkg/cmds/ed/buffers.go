@@ -0,0 +1,215 @@
+// Copyright (c) 2024, xplshn, u-root and contributors  [3BSD]
+// For more details refer to https://github.com/xplshn/a-utils
+
+// buffers.go - the named registers, and the buffers of the multi-buffer extension
+package ed
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/xplshn/a-utils/pkg/ccmd"
+)
+
+// The lines y and d take go to the cut buffer, which x pastes, and to a named register too if
+// the command is followed by "a to "z; an upper-case name appends them to the register.
+// Registers and the cut buffer are shared by the buffers, so that lines can be moved from a
+// file to another.
+//
+// With --buffers, several files can be edited at once, each in its own buffer with its file
+// name, marks, undo history and swap file: o opens one, b switches to another and B lists
+// them. q and Q close the current buffer then, and quit once it is the last one.
+
+// registers are the named registers "a to "z: the cuts put or appended in them, whose lines
+// are read when they are pasted
+var registers [26][]cut
+
+// errNoBuffers reports a command of the multi-buffer extension without --buffers
+var errNoBuffers = fmt.Errorf("multiple buffers are off, see --buffers")
+
+// register returns the register named after the command of ctx, -1 if none is, and whether
+// lines are appended to it
+func register(ctx *Context) (reg int, appending bool, e error) {
+	arg := ctx.cmd[ctx.cmdOffset+1:]
+	arg = arg[wsOffset(arg):]
+	if !strings.HasPrefix(arg, `"`) {
+		return -1, false, nil
+	}
+	if e = ccmd.CheckExtension(`"a`); e != nil {
+		return -1, false, e
+	}
+	if len(arg) == 2 {
+		switch c := arg[1]; {
+		case c >= 'a' && c <= 'z':
+			return int(c - 'a'), false, nil
+		case c >= 'A' && c <= 'Z':
+			return int(c - 'A'), true, nil
+		}
+	}
+	return -1, false, fmt.Errorf("invalid register: %s", arg)
+}
+
+// setRegister puts the lines of c in the register reg, or appends them to it, if reg isn't -1
+func setRegister(reg int, appending bool, c cut) {
+	switch {
+	case reg < 0:
+	case appending:
+		registers[reg] = append(slices.Clip(registers[reg]), c)
+	default:
+		registers[reg] = []cut{c}
+	}
+}
+
+// registerText returns the lines of the register reg
+func registerText(reg int) (lines []string) {
+	for _, c := range registers[reg] {
+		lines = append(lines, c.text()...)
+	}
+	return
+}
+
+// An openBuffer is a buffer of the multi-buffer extension and the state that goes with it.
+// The current buffer is kept in buffer, state.fileName and swap, its openBuffer being updated
+// when another one becomes the current one.
+type openBuffer struct {
+	buf      *FileBuffer
+	fileName string
+	swap     swapFile
+}
+
+// the buffers open, and the index of the current one
+var (
+	buffers = make([]openBuffer, 1)
+	current int
+)
+
+// resetBuffers makes the current buffer the only one
+func resetBuffers() {
+	buffers, current = make([]openBuffer, 1), 0
+}
+
+// syncBuffer updates the openBuffer of the current buffer
+func syncBuffer() {
+	buffers[current] = openBuffer{buffer, state.fileName, swap}
+}
+
+// switchBuffer makes the buffer at index i the current one, the cut buffer going with it
+func switchBuffer(i int) {
+	syncBuffer()
+	cbuf := buffer.cbuf
+	current = i
+	buffer, state.fileName, swap = buffers[i].buf, buffers[i].fileName, buffers[i].swap
+	buffer.cbuf = cbuf
+	buffer.Start() // the command is in the history of the buffer it changed only
+}
+
+// eachBuffer calls fn with the number of every buffer, the buffer being the current one while
+// fn runs
+func eachBuffer(fn func(n int)) {
+	cur := current
+	for i := range buffers {
+		switchBuffer(i)
+		fn(i + 1)
+	}
+	switchBuffer(cur)
+}
+
+// checkBuffers returns an error if the buffers can't be switched
+func checkBuffers() error {
+	switch {
+	case !state.multiBuffer:
+		return errNoBuffers
+	case inGlobal:
+		return fmt.Errorf("cannot switch buffers in a global command")
+	}
+	return nil
+}
+
+// closeBuffer closes the current buffer, of several, the previous one becoming the current one
+func closeBuffer() {
+	removeSwap()
+	closed, next := current, current-1
+	if closed == 0 {
+		next = 1
+	}
+	switchBuffer(next)
+	buffers = slices.Delete(buffers, closed, closed+1)
+	if next > closed {
+		current--
+	}
+}
+
+// cmdOpen opens a file in a new buffer after the current one, which it replaces as the
+// current one (o)
+func cmdOpen(ctx *Context) (e error) {
+	if e = checkBuffers(); e != nil {
+		return
+	}
+	name := ctx.cmd[ctx.cmdOffset+1:]
+	name = name[wsOffset(name):]
+	if e = checkFileName(name); e != nil {
+		return
+	}
+	if strings.HasPrefix(name, "!") {
+		return fmt.Errorf("cannot open the output of a command")
+	}
+	b := NewFileBuffer(nil)
+	read := false
+	if name != "" {
+		if _, e = os.Stat(name); e == nil {
+			if b, e = FileToBuffer(name); e != nil {
+				return
+			}
+			read = true
+		} else if !os.IsNotExist(e) {
+			return
+		}
+	}
+	buffers = slices.Insert(buffers, current+1, openBuffer{buf: b, fileName: name})
+	switchBuffer(current + 1)
+	if read && !fsuppress {
+		fmt.Fprintf(ctx.out, "%d\n", buffer.Size())
+	}
+	return nil
+}
+
+// cmdBuffer switches to the buffer numbered, or to the next one (b)
+func cmdBuffer(ctx *Context) (e error) {
+	if e = checkBuffers(); e != nil {
+		return
+	}
+	arg := strings.TrimSpace(ctx.cmd[ctx.cmdOffset+1:])
+	i := (current + 1) % len(buffers)
+	if arg != "" {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 || n > len(buffers) {
+			return fmt.Errorf("no such buffer: %s", arg)
+		}
+		i = n - 1
+	}
+	switchBuffer(i)
+	return
+}
+
+// cmdListBuffers lists the buffers, one per line: its number, '*' for the current one, '+' if
+// it has changes that weren't written, and its file name (B)
+func cmdListBuffers(ctx *Context) (e error) {
+	if !state.multiBuffer {
+		return errNoBuffers
+	}
+	syncBuffer()
+	for i, b := range buffers {
+		cur, mod := ' ', ' '
+		if i == current {
+			cur = '*'
+		}
+		if b.buf.Dirty() {
+			mod = '+'
+		}
+		fmt.Fprintf(ctx.out, "%d %c%c %s\n", i+1, cur, mod, b.fileName)
+	}
+	return
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2024, xplshn, u-root and contributors  [3BSD]
+// For more details refer to https://github.com/xplshn/a-utils
+
+package ed
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+// Test yanking to, deleting to and pasting from the named registers
+func TestRegisters(t *testing.T) {
+	saved := buffer
+	defer func() { buffer, registers = saved, [26][]cut{} }()
+	for _, tt := range []struct {
+		cmds []string
+		want string
+		err  string
+	}{
+		{cmds: []string{`1,2y"a`, `3y"b`, `$x"b`, `0x"a`}, want: "one two one two three four three"},
+		{cmds: []string{`1y"a`, `3y"A`, `$x"a`}, want: "one two three four one three"},
+		{cmds: []string{`2,3d"c`, `1y`, `$x"c`, `0x`}, want: "one one four two three"},
+		{cmds: []string{`1d "d`, `$x "d`}, want: "two three four one"},
+		{cmds: []string{`1y"1`}, want: "one two three four", err: "invalid register"},
+		{cmds: []string{`1d"ab`}, want: "one two three four", err: "invalid register"},
+		{cmds: []string{`x"e`}, want: "one two three four", err: "register e is empty"},
+	} {
+		buffer, registers = NewFileBuffer([]string{"one", "two", "three", "four"}), [26][]cut{}
+		var err error
+		for _, cmd := range tt.cmds {
+			if err = execute(cmd, nil, &bytes.Buffer{}); err != nil {
+				break
+			}
+		}
+		if (err == nil) != (tt.err == "") || err != nil && !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%q: error %v, want %q", tt.cmds, err, tt.err)
+		}
+		lines, _ := buffer.Get([2]int{0, buffer.Len() - 1})
+		if got := strings.Join(lines, " "); got != tt.want {
+			t.Errorf("after %q, the buffer is %q, want %q", tt.cmds, got, tt.want)
+		}
+	}
+}
+
+// Test opening, switching, listing and closing buffers, each with its file name, marks and
+// undo history
+func TestBuffers(t *testing.T) {
+	defer func() { state.multiBuffer, fsuppress, registers = false, false, [26][]cut{} }()
+	fsuppress = true // o prints the size of the file otherwise, as e does
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	for name, text := range map[string]string{"a": "a1\na2\n", "b": "b1\nb2\nb3\n"} {
+		if err := os.WriteFile(name, []byte(text), 0o666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	state.multiBuffer = false
+	var out bytes.Buffer
+	buffer = NewFileBuffer(nil)
+	if err := execute("o b", nil, &out); !errors.Is(err, errNoBuffers) {
+		t.Errorf("o without --buffers = %v, want %v", err, errNoBuffers)
+	}
+
+	state.multiBuffer = true
+	cmds := []string{
+		"2ka", `1y"x`, // in a
+		"o b", "1ka", "$d", "B", // in b, after a
+		"b 1", "'ap", `$x"x`, "u", "B", // back in a, whose marks and undo are its own
+		"b", "'ap", "o", "B", // an empty buffer after b
+		"q", "$x", "wq", "B", "1,$p", "q", "q",
+	}
+	buffer = NewFileBuffer(nil)
+	if err := runEd(strings.NewReader(strings.Join(cmds, "\n")+"\n"), &out, true, "", "a"); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"1    a", "2 *+ b",
+		"a2", "1 *  a", "2  + b",
+		"b1", "1    a", "2  + b", "3 *  ",
+		"1 *  a", "a1", "a2", "?",
+	}, "\n") + "\n"
+	if out.String() != want {
+		t.Errorf("the output is\n%s\nwant\n%s", out.String(), want)
+	}
+	if b, _ := os.ReadFile("b"); string(b) != "b1\nb2\nb3\n" {
+		t.Errorf("b is %q once written, want %q", b, "b1\nb2\nb3\n")
+	}
+}
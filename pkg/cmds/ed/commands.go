@@ -3,32 +3,58 @@
 // license that can be found in the LICENSE file.
 
 // commands.go - defines editor commands
-package main
+package ed
 
 import (
-	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"regexp"
 	"strconv"
 	"strings"
-	"unicode"
 
 	"github.com/alecthomas/chroma/v2"
-	"github.com/alecthomas/chroma/v2/formatters"
-	"github.com/alecthomas/chroma/v2/lexers"
 	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/xplshn/a-utils/pkg/ccmd"
 )
 
 var errExit = fmt.Errorf("exit")
 
+// errInterrupt reports a command or an input aborted by SIGINT
+var errInterrupt = fmt.Errorf("interrupt")
+
+// errNoMatch reports a substitution that matched nothing
+var errNoMatch = fmt.Errorf("no match")
+
+// errors of the restricted mode
+var (
+	errShellRestricted     = fmt.Errorf("shell access restricted")
+	errDirectoryRestricted = fmt.Errorf("directory access restricted")
+	errShellGolden         = fmt.Errorf("shell commands aren't run with --golden")
+)
+
+// checkFileName returns an error, in restricted mode, if name is a shell command or a file
+// outside of the current directory
+func checkFileName(name string) error {
+	switch {
+	case !state.restricted:
+		return nil
+	case strings.HasPrefix(name, "!"):
+		return errShellRestricted
+	case name == ".." || strings.ContainsRune(name, '/'):
+		return errDirectoryRestricted
+	}
+	return nil
+}
+
 // A Context is passed to an invoked command
 type Context struct {
-	cmd       string // full command string
-	cmdOffset int    // start of the command after address resolution
-	addrs     []int  // resolved addresses
+	cmd       string     // full command string
+	cmdOffset int        // start of the command after address resolution
+	addrs     []int      // resolved addresses
+	in        lineReader // lines of the input mode
 	out       io.Writer
 }
 
@@ -65,13 +91,26 @@ var cmds = map[byte]Command{
 	'P': cmdPrompt,
 	's': cmdSub,
 	'u': cmdUndo,
+	'U': cmdUndo,
+	'R': cmdUndo,
+	'L': cmdHistory,
 	'D': cmdDump, // var dump the buffer for debug
 	'z': cmdScroll,
 	'!': cmdCommand,
 	'_': cmdSyntaxHighlighting,
+	'o': cmdOpen,
+	'b': cmdBuffer,
+	'B': cmdListBuffers,
 	'#': func(*Context) (e error) { return },
 }
 
+func init() {
+	// the global commands run others through cmds, they can't be in its initializer
+	for _, c := range []byte("gGvV") {
+		cmds[c] = cmdGlobal
+	}
+}
+
 //////////////////////
 // Command handlers /
 ////////////////////
@@ -81,18 +120,28 @@ func cmdDelete(ctx *Context) (e error) {
 	if r, e = buffer.AddrRangeOrLine(ctx.addrs); e != nil {
 		return
 	}
-	e = buffer.Delete(r)
+	reg, appending, e := register(ctx)
+	if e != nil {
+		return
+	}
+	if e = buffer.Delete(r); e == nil {
+		setRegister(reg, appending, buffer.cbuf)
+	}
 	return
 }
 
+// cmdQuit quits, or closes the current buffer while others are open
 func cmdQuit(ctx *Context) (e error) {
 	if ctx.cmd[ctx.cmdOffset] == 'q' && buffer.Dirty() {
 		return fmt.Errorf("warning: file modified")
 	}
+	if len(buffers) > 1 && checkBuffers() == nil {
+		closeBuffer()
+		return nil
+	}
 	return errExit
 }
 
-// --xplshn: TODO: Figure out why using syntax highlighting garbles newlines on comments sometimes, I've noticed it when EDiting .go files
 func cmdPrint(ctx *Context) (err error) {
 	var addrRange [2]int
 
@@ -102,84 +151,26 @@ func cmdPrint(ctx *Context) (err error) {
 	}
 
 	for lineNumber := addrRange[0]; lineNumber <= addrRange[1]; lineNumber++ {
+		if err = checkInterrupt(); err != nil {
+			return
+		}
 		if ctx.cmd[ctx.cmdOffset] == 'n' {
 			fmt.Fprintf(ctx.out, "%d\t", lineNumber+1)
 		}
 
 		lineContent := buffer.GetMust(lineNumber, true)
+		var list *lister
 		if ctx.cmd[ctx.cmdOffset] == 'l' {
-			lineContent += "$" // TODO: the man pages describes more escaping, but it's not clear what GNU ed actually does.
-		} // --xplshn: Ofc it is, its GNU! The code was accidentally obfuscated!
+			list = &lister{width: listWidth()}
+		}
 
-		// Detect language and highlight code
+		// Highlight the line with the tokens of the whole buffer
 		if state.syntaxHighlighting {
-			// Declare our private functions
-			sanitizeInput := func(input string) string {
-				var sanitized strings.Builder
-				for _, r := range input {
-					if unicode.IsPrint(r) && !unicode.IsControl(r) {
-						sanitized.WriteRune(r)
-					}
-				}
-				return sanitized.String()
-			}
-			// E-OF-DECLARE-OUR-PRIVATE-FUNCTIONS
-
-			// Sanitize input
-			sanitizedContents := sanitizeInput(lineContent)
-			// Detect the language from filename
-			lexer := lexers.Match(state.fileName)
-			if lexer == nil {
-				// If filename detection fails, detect the language from the file's contents
-				lexer = lexers.Analyse(sanitizedContents)
-			}
-			if lexer == nil {
-				lexer = lexers.Fallback
-			}
-			lexer = chroma.Coalesce(lexer)
-
-			var style *chroma.Style
-			if state.syntaxHighlightingStyleName != "" {
-				style = styles.Get(state.syntaxHighlightingStyleName)
-			} else {
-				style = styles.Get(os.Getenv("A_SYHX_COLOR_SCHEME"))
-				if style == nil {
-					style = styles.Fallback
-				}
-			}
-
-			formatterName := os.Getenv("A_SYHX_FORMATTER")
-			if formatterName == "" {
-				formatterName = "terminal16"
-			}
-
-			formatter := formatters.Get(formatterName)
-			if formatter == nil {
-				formatter = formatters.Fallback
-			}
-
-			// Tokenize the entire content
-			iterator, tokenizationErr := lexer.Tokenise(nil, lineContent)
-			if tokenizationErr != nil {
-				return tokenizationErr
-			}
-
-			var buf bytes.Buffer
-			formattingErr := formatter.Format(&buf, style, iterator)
-			if formattingErr != nil {
-				return formattingErr
-			}
-
-			// Write the formatted output
-			_, err = io.Copy(ctx.out, &buf)
-			if err != nil {
-				return err
-			}
-			// Add a newline character after writing the formatted output
-			_, err = io.WriteString(ctx.out, "\n")
-			if err != nil {
+			if err = printHighlighted(ctx.out, lineNumber, list); err != nil {
 				return err
 			}
+		} else if list != nil {
+			fmt.Fprintf(ctx.out, "%s%s\n", list.list(lineContent), list.end())
 		} else {
 			fmt.Fprintf(ctx.out, "%s\n", lineContent)
 		}
@@ -187,6 +178,52 @@ func cmdPrint(ctx *Context) (err error) {
 	return
 }
 
+// A lister writes lines unambiguously, as l does: the characters that aren't printable are
+// escaped, see ccmd.EscapeNotation, and so is '$', long lines are folded with a '\', and the
+// end of the line is marked with a '$'
+type lister struct {
+	width int // columns of the lines written, the '\' of folds included
+	col   int // columns written on the current line, see ccmd.StringWidth
+}
+
+// list returns s written unambiguously, continuing the current line
+func (ls *lister) list(s string) string {
+	var sb strings.Builder
+	for len(s) > 0 {
+		v, size := ccmd.VisibleChar(s, ccmd.EscapeNotation)
+		switch {
+		case v != "":
+		case s[0] == '$':
+			v = `\$`
+		default:
+			v = s[:size]
+		}
+		ls.write(&sb, v)
+		s = s[size:]
+	}
+	return sb.String()
+}
+
+// end returns the '$' marking the end of the line
+func (ls *lister) end() string {
+	var sb strings.Builder
+	ls.write(&sb, "$")
+	return sb.String()
+}
+
+// write writes the notation of a character, after a fold if it doesn't fit on the line. Wide
+// characters take two columns, and combining marks none, staying with the character they
+// follow.
+func (ls *lister) write(sb *strings.Builder, v string) {
+	n := ccmd.StringWidth(v)
+	if ls.col > 0 && ls.col+n >= ls.width {
+		sb.WriteString("\\\n")
+		ls.col = 0
+	}
+	sb.WriteString(v)
+	ls.col += n
+}
+
 /*
 func cmdPrint(ctx *Context) (e error) {
 	var r [2]int
@@ -241,6 +278,9 @@ func cmdSyntaxHighlighting(ctx *Context) (e error) {
 	if args != "" {
 		// If an  argument was provided; Extract such argument
 		arg := args
+		if e = checkFileName(arg); e != nil {
+			return
+		}
 		// Check if the argument is a valid file
 		if _, err := os.Stat(arg); err == nil {
 			// It's a valid file, load the custom style
@@ -291,7 +331,11 @@ func cmdScroll(ctx *Context) (e error) {
 		}
 		state.winSize = win
 	}
-	end := start + state.winSize - 1
+	win := state.winSize
+	if win == 0 {
+		win = scrollSize()
+	}
+	end := start + win - 1
 	if end > buffer.Len()-1 {
 		end = buffer.Len() - 1
 	}
@@ -299,7 +343,16 @@ func cmdScroll(ctx *Context) (e error) {
 	if ls, e = buffer.Get([2]int{start, end}); e != nil {
 		return
 	}
-	for _, l := range ls {
+	for i, l := range ls {
+		if e = checkInterrupt(); e != nil {
+			return
+		}
+		if state.syntaxHighlighting {
+			if e = printHighlighted(ctx.out, start+i, nil); e != nil {
+				return
+			}
+			continue
+		}
 		fmt.Fprintf(ctx.out, "%s\n", l)
 	}
 	return
@@ -323,13 +376,21 @@ func cmdErr(ctx *Context) (e error) {
 }
 
 func cmdInput(ctx *Context) (e error) {
-	scan := bufio.NewScanner(os.Stdin)
 	nbuf := []string{}
 	if len(ctx.cmd[ctx.cmdOffset+1:]) != 0 && ctx.cmd[ctx.cmdOffset] != 'c' {
 		return fmt.Errorf("%c only takes a single line addres", ctx.cmd[ctx.cmdOffset])
 	}
-	for scan.Scan() {
-		line := scan.Text()
+	readLine := ctx.in.ReadLine
+	if text, ok := ctx.in.(textReader); ok {
+		readLine = text.ReadText
+	}
+	for {
+		line, err := readLine()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
 		if line == "." {
 			break
 		}
@@ -389,6 +450,9 @@ func cmdWrite(ctx *Context) (e error) {
 	if len(m[0][3]) > 0 {
 		file = m[0][3]
 	}
+	if e = checkFileName(m[0][2] + m[0][3]); e != nil {
+		return
+	}
 	var lstr []string
 	lstr, e = buffer.Get(r)
 	if e != nil {
@@ -411,28 +475,40 @@ func cmdWrite(ctx *Context) (e error) {
 		return s.Run()
 	}
 
-	var f *os.File
 	oFlag := os.O_TRUNC
 	if ctx.cmd[ctx.cmdOffset] == 'W' {
 		oFlag = os.O_APPEND
 	}
-	if f, e = os.OpenFile(file, os.O_WRONLY|os.O_CREATE|oFlag, 0o666); e != nil {
+	// the golden mode tests the script, no file is written and the buffer stays modified
+	if state.golden == "" {
+		if e = writeLines(file, oFlag, lstr); e != nil {
+			return
+		}
+		buffer.Clean()
+	}
+	if quit {
+		e = cmdQuit(ctx)
+	}
+	return
+}
+
+// writeLines writes lines to the file name, opened with the flag oFlag
+func writeLines(name string, oFlag int, lines []string) (e error) {
+	var f *os.File
+	if f, e = os.OpenFile(name, os.O_WRONLY|os.O_CREATE|oFlag, 0o666); e != nil {
 		return e
 	}
 	defer f.Close()
 
-	for _, s := range lstr {
-		_, e = fmt.Fprintf(f, "%s\n", s)
-		if e != nil {
+	for _, s := range lines {
+		if e = checkInterrupt(); e != nil {
 			return
 		}
-	}
-	if quit {
-		if e = cmdQuit(ctx); e != nil {
+		_, e = fmt.Fprintf(f, "%s\n", s)
+		if e != nil {
 			return
 		}
 	}
-	buffer.Clean()
 	return
 }
 
@@ -474,6 +550,8 @@ func cmdEdit(ctx *Context) (e error) {
 	var fh io.Reader
 	if len(filename) == 0 {
 		filename = state.fileName
+	} else if e = checkFileName(filename); e != nil {
+		return
 	}
 	if filename[0] == '!' { // command, not filename
 		s := System{
@@ -516,6 +594,9 @@ func cmdFile(ctx *Context) (e error) {
 	newFile := ctx.cmd[ctx.cmdOffset:]
 	newFile = newFile[wsOffset(newFile):]
 	if len(newFile) > 0 {
+		if e = checkFileName(newFile); e != nil {
+			return
+		}
 		state.fileName = newFile
 		return
 	}
@@ -562,10 +643,17 @@ func cmdMove(ctx *Context) (e error) {
 	}
 	// must parse the destination
 	destStr := ctx.cmd[ctx.cmdOffset+1:]
+	if state.traditional && strings.TrimSpace(destStr) == "" {
+		return fmt.Errorf("destination expected")
+	}
 	var nctx Context
 	if nctx.addrs, nctx.cmdOffset, e = buffer.ResolveAddrs(destStr); e != nil {
 		return
 	}
+	if len(nctx.addrs) == 0 {
+		// the destination defaults to the current line
+		nctx.addrs = []int{buffer.GetAddr()}
+	}
 	// this is a bit hacky, but we're supposed to allow 0
 	offset := 1
 	last := len(nctx.addrs) - 1
@@ -605,7 +693,14 @@ func cmdCopy(ctx *Context) (e error) {
 	if r, e = buffer.AddrRangeOrLine(ctx.addrs); e != nil {
 		return
 	}
-	return buffer.Copy(r)
+	reg, appending, e := register(ctx)
+	if e != nil {
+		return
+	}
+	if e = buffer.Copy(r); e == nil {
+		setRegister(reg, appending, buffer.cbuf)
+	}
+	return
 }
 
 func cmdPaste(ctx *Context) (e error) {
@@ -620,7 +715,18 @@ func cmdPaste(ctx *Context) (e error) {
 	if addr, e = buffer.AddrValue(ctx.addrs); e != nil {
 		return
 	}
-	return buffer.Paste(addr + offset)
+	reg, _, e := register(ctx)
+	if e != nil {
+		return
+	}
+	if reg < 0 {
+		return buffer.Paste(addr + offset)
+	}
+	lines := registerText(reg)
+	if len(lines) == 0 {
+		return fmt.Errorf("register %c is empty", 'a'+reg)
+	}
+	return buffer.Insert(addr+offset, lines)
 }
 
 func cmdPrompt(ctx *Context) (e error) {
@@ -711,7 +817,7 @@ func cmdSub(ctx *Context) (e error) {
 	}
 
 	var rx *regexp.Regexp
-	if rx, e = regexp.Compile(mat); e != nil {
+	if rx, e = compileRE(mat, del); e != nil {
 		return
 	}
 
@@ -721,6 +827,9 @@ func cmdSub(ctx *Context) (e error) {
 	b, _ := buffer.Get(r)
 	// we have to do things a bit manually because we we only have ReplaceAll, and we don't necessarily want that
 	for ln, l := range b {
+		if e = checkInterrupt(); e != nil {
+			return
+		}
 		matches := rx.FindAllStringSubmatchIndex(l, -1)
 		if !(len(matches) > 0) {
 			continue // skip the rest if we don't have matches
@@ -765,23 +874,25 @@ func cmdSub(ctx *Context) (e error) {
 			oLin = m[1]
 		}
 		fLin += l[oLin:]
-		if e = buffer.Delete([2]int{ln, ln}); e != nil {
+		// ln indexes the lines of the range
+		if e = buffer.Delete([2]int{r[0] + ln, r[0] + ln}); e != nil {
 			return
 		}
-		if e = buffer.Insert(ln, []string{fLin}); e != nil {
+		if e = buffer.Insert(r[0]+ln, []string{fLin}); e != nil {
 			return
 		}
 		last = fLin
-		lastN = ln
+		lastN = r[0] + ln
 	}
 	if nMatch == 0 {
-		e = fmt.Errorf("no match")
+		e = errNoMatch
 	} else {
 		if printP {
 			fmt.Fprintf(ctx.out, "%s\n", last)
 		}
 		if printL {
-			fmt.Fprintf(ctx.out, "%s$\n", last)
+			list := &lister{width: listWidth()}
+			fmt.Fprintf(ctx.out, "%s%s\n", list.list(last), list.end())
 		}
 		if printN {
 			fmt.Fprintf(ctx.out, "%d\t%s\n", lastN+1, last)
@@ -790,8 +901,222 @@ func cmdSub(ctx *Context) (e error) {
 	return
 }
 
+// commandList gives the lines of a command list to the commands it runs, so that a, c and i
+// read their text from it
+type commandList struct {
+	lines []string
+}
+
+func (l *commandList) ReadLine() (string, error) {
+	if len(l.lines) == 0 {
+		return "", io.EOF
+	}
+	line := l.lines[0]
+	l.lines = l.lines[1:]
+	return line, nil
+}
+
+// readCommandList returns the command list starting with first, which continues on the
+// lines read from in as long as they end with a backslash
+func readCommandList(first string, in lineReader) (list []string, e error) {
+	line := first
+	for strings.HasSuffix(line, "\\") {
+		list = append(list, strings.TrimSuffix(line, "\\"))
+		if line, e = in.ReadLine(); errors.Is(e, io.EOF) {
+			return list, nil
+		} else if e != nil {
+			return
+		}
+	}
+	return append(list, line), nil
+}
+
+// inGlobal is set while a global command runs, they can't be nested
+var inGlobal bool
+
+// lastGlobalRE is the regular expression of the last global command, for g//
+var lastGlobalRE *regexp.Regexp
+
+// cmdGlobal runs g/RE/command-list, and v, on the lines that match RE (that don't, for v),
+// which are marked before the command list runs on each of them in turn. G and V print each
+// line, then run the command list typed for it: none if the line is empty, the previous one
+// if it is "&". The whole global command is undone at once.
+func cmdGlobal(ctx *Context) (e error) {
+	if inGlobal {
+		return fmt.Errorf("cannot nest global commands")
+	}
+	cmd := ctx.cmd[ctx.cmdOffset]
+	interactive := cmd == 'G' || cmd == 'V'
+	invert := cmd == 'v' || cmd == 'V'
+
+	arg := ctx.cmd[ctx.cmdOffset+1:]
+	if len(arg) == 0 || arg[0] == ' ' || arg[0] == '\\' {
+		return fmt.Errorf("invalid pattern delimiter")
+	}
+	del := arg[0]
+	// we replace escapes and their escaped characters with spaces to keep indexing
+	sane := rxSanitize.ReplaceAllString(arg, "  ")
+	end := strings.IndexByte(sane[1:], del) + 1
+	if end == 0 {
+		end = len(arg)
+	}
+	pattern := arg[1:end]
+	rest := ""
+	if end < len(arg) {
+		rest = arg[end+1:]
+	}
+
+	var rx *regexp.Regexp
+	if pattern == "" {
+		if lastGlobalRE == nil {
+			return fmt.Errorf("no previous pattern")
+		}
+		rx = lastGlobalRE
+	} else if rx, e = compileRE(pattern, del); e != nil {
+		return
+	}
+	lastGlobalRE = rx
+
+	var list []string
+	if !interactive {
+		if list, e = readCommandList(rest, ctx.in); e != nil {
+			return
+		}
+		if len(list) == 1 && list[0] == "" {
+			list = []string{"p"}
+		}
+	} else if rest != "" {
+		return fmt.Errorf("unexpected command suffix")
+	}
+
+	var r [2]int
+	if ctx.cmdOffset == 0 {
+		if buffer.Len() == 0 {
+			return
+		}
+		r = [2]int{0, buffer.Len() - 1}
+	} else if r, e = buffer.AddrRangeOrLine(ctx.addrs); e != nil {
+		return
+	}
+
+	// mark the lines first, the command lists may then add, move or delete lines
+	var marked []int
+	for l := r[0]; l <= r[1]; l++ {
+		if rx.MatchString(buffer.GetMust(l, false)) != invert {
+			marked = append(marked, buffer.LineID(l))
+		}
+	}
+
+	inGlobal = true
+	defer func() { inGlobal = false }()
+	var previous []string
+	subs, subsMatched := 0, 0
+	hint := r[0]
+	for _, id := range marked {
+		if e = checkInterrupt(); e != nil {
+			return
+		}
+		l, ok := buffer.FindLine(id, hint)
+		if !ok {
+			continue // deleted by the command list of a previous line
+		}
+		hint = l
+		if e = buffer.SetAddr(l); e != nil {
+			return
+		}
+
+		if interactive {
+			if e = run("p", nil, ctx.out); e != nil {
+				return
+			}
+			var first string
+			if first, e = ctx.in.ReadLine(); errors.Is(e, io.EOF) {
+				return nil
+			} else if e != nil {
+				return
+			}
+			switch first {
+			case "":
+				continue
+			case "&":
+				if previous == nil {
+					return fmt.Errorf("no previous command")
+				}
+				list = previous
+			default:
+				if list, e = readCommandList(first, ctx.in); e != nil {
+					return
+				}
+			}
+			previous = list
+		}
+
+		lines := &commandList{lines: list}
+		for {
+			var c string
+			if c, e = lines.ReadLine(); errors.Is(e, io.EOF) {
+				break
+			}
+			e = run(c, lines, ctx.out)
+			// a substitution is only an error if it matches no line at all
+			if errors.Is(e, errNoMatch) {
+				subs++
+				continue
+			} else if e != nil {
+				return
+			}
+			if isSub(c) {
+				subs++
+				subsMatched++
+			}
+		}
+	}
+	if subs > 0 && subsMatched == 0 {
+		return errNoMatch
+	}
+	return nil
+}
+
+// isSub reports whether the command c is a substitution
+func isSub(c string) bool {
+	_, off, e := buffer.ResolveAddrs(c)
+	return e == nil && off < len(c) && c[off] == 's'
+}
+
+// cmdUndo undoes the last change for u, which is its own undo if it was one, as POSIX says;
+// U and R undo and redo the changes one after the other
 func cmdUndo(ctx *Context) (e error) {
-	buffer.Rewind()
+	if inGlobal {
+		return fmt.Errorf("cannot undo within a global command")
+	}
+	switch ctx.cmd[ctx.cmdOffset] {
+	case 'U':
+		if e = ccmd.CheckExtension("U"); e == nil && !buffer.Undo() {
+			e = fmt.Errorf("nothing to undo")
+		}
+	case 'R':
+		if e = ccmd.CheckExtension("R"); e == nil && !buffer.Redo() {
+			e = fmt.Errorf("nothing to redo")
+		}
+	default:
+		buffer.Rewind()
+	}
+	return
+}
+
+// cmdHistory lists the changes kept for undo, marking the current one
+func cmdHistory(ctx *Context) (e error) {
+	if e = ccmd.CheckExtension("L"); e != nil {
+		return
+	}
+	cmds, current := buffer.History()
+	for i, cmd := range cmds {
+		mark := ' '
+		if i+1 == current {
+			mark = '*'
+		}
+		fmt.Fprintf(ctx.out, "%c%d\t%s\n", mark, i+1, cmd)
+	}
 	return
 }
 
@@ -803,6 +1128,9 @@ func cmdDump(ctx *Context) (e error) {
 var rxCmdSub = regexp.MustCompile(`%`)
 
 func cmdCommand(ctx *Context) (e error) {
+	if state.restricted {
+		return errShellRestricted
+	}
 	s := System{
 		Cmd:    ctx.cmd[ctx.cmdOffset+1:],
 		Stdin:  os.Stdin,
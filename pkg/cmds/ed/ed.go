@@ -0,0 +1,476 @@
+// Copyright (c) 2024, xplshn, u-root and contributors  [3BSD]
+// For more details refer to https://github.com/xplshn/a-utils
+//
+// This `ed` is intended to be a feature-complete mimick of [GNU Ed](https://www.gnu.org/software/ed//).  It is a close enough mimick that the [GNU Ed Man Page](https://www.gnu.org/software/ed/manual/ed_manual.html) should be a reliable source of documentation.  Divergence from the man page is generally considered a bug (unless it's an added feature).
+//
+// There are a few known differences:
+//
+// - regular expressions are POSIX basic ones, or extended ones with -E, translated to the syntax of `go`'s `regexp` package, with the GNU extensions.  Back-references can't be used in patterns, only in the replacement of `s` (as `\<ref>`), and `\<` and `\>` match at any word boundary.
+// - there has been little/no attempt to make particulars like error messages match `GNU Ed`.
+// - rather than being an error, the 'g' option for 's' simply overrides any specified count.
+// - "traditional" mode (-G) only affects m and t, which then need a destination address.
+//
+// The following has been implemented:
+// - Full line address parsing (including RE and markings)
+// - Implmented commands: !, #, =, E, G, H, P, Q, V, W, a, c, d, e, f, g, h, i, j, k, l, m, n, p, q, r, s, t, u, v, w, x, y, z
+// - Syntax highlighting: _
+// - Undo history: U, R, L, and multi-level undo and redo
+// - Restricted mode: -r, or invoked as red
+// - The options of GNU Ed: -E, -G, -l, -p, -r, -s, -v and their long names
+// - Large files: past 64 MiB, lines are kept in a scratch file of the temporary directory
+// - Named registers: y, x and d followed by "a to "z
+// - Multiple buffers: o, b and B, with --buffers
+// - Scripts: -e and -f, tested against a golden file with --golden
+// - Hangups: the buffer is saved to ed.hup, and with --swap to a swap file while it is edited, which -R recovers
+package ed
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/xplshn/a-utils/pkg/ccmd"
+)
+
+// flags
+var (
+	fsuppress   bool
+	frestricted bool
+	fverbose    bool
+	fprompt     string
+)
+
+func init() {
+	ccmd.Register("ed", Main)
+	ccmd.Register("red", Main)
+}
+
+// current FileBuffer
+var buffer *FileBuffer
+
+// edState is the state of ed, see state
+type edState struct {
+	fileName                    string // current filename
+	lastErr                     error
+	printErr                    bool
+	restricted                  bool // see checkFileName
+	prompt                      bool
+	syntaxHighlighting          bool
+	syntaxHighlightingStyleName string
+	winSize                     int    // lines printed by z, 0 to follow the terminal
+	extendedRE                  bool   // -E, see compileRE
+	traditional                 bool   // -G
+	loose                       bool   // -l, errors of scripts don't change the exit status
+	failed                      bool   // a command of a script failed, ed then exits with 1
+	recover                     bool   // -R, see recoverSwap
+	multiBuffer                 bool   // --buffers, see buffers
+	golden                      string // --golden, the file the buffer is compared with
+	lastRep                     string
+	lastSub                     string
+}
+
+// current ed state
+var state edState
+
+// Parse input and execute command, as a single unit for undo
+func execute(cmd string, in lineReader, output io.Writer) (e error) {
+	buffer.Start()
+	e = run(cmd, in, output)
+	buffer.End(cmd) // what was changed until an error too can be undone
+	return
+}
+
+// run parses and runs a command, the commands of a global command's command list included
+func run(cmd string, in lineReader, output io.Writer) (e error) {
+	ctx := &Context{
+		cmd: cmd,
+		in:  in,
+		out: output,
+	}
+	if ctx.addrs, ctx.cmdOffset, e = buffer.ResolveAddrs(cmd); e != nil {
+		return
+	}
+	if len(cmd) <= ctx.cmdOffset {
+		// no command, default to print
+		ctx.cmd += "p"
+	}
+	exe, ok := cmds[ctx.cmd[ctx.cmdOffset]]
+	if !ok {
+		return fmt.Errorf("invalid command: %v", cmd[ctx.cmdOffset])
+	}
+	return exe(ctx)
+}
+
+func runEd(in io.Reader, out io.Writer, suppress bool, prompt, file string) error {
+	var e error
+	// nothing is kept from a previous run but the options, set by Main
+	state.fileName, state.lastErr, state.failed = file, nil, false
+	state.prompt = len(prompt) > 0
+	state.lastRep, state.lastSub = "", ""
+	state.syntaxHighlightingStyleName = ""
+	lastGlobalRE, inGlobal = nil, false
+	highlights = highlightCache{}
+	interrupted.Store(false)
+	buffer = NewFileBuffer(nil)
+	if file != "" { // we were given a file name
+		// try to read in the file
+		if _, e = os.Stat(state.fileName); os.IsNotExist(e) && !suppress {
+			fmt.Fprintf(os.Stderr, "%s: No such file or directory", state.fileName)
+			// this is not fatal, we just start with an empty buffer
+		} else {
+			if buffer, e = FileToBuffer(state.fileName); e != nil {
+				return e
+			}
+			if !suppress {
+				fmt.Println(buffer.Size())
+			}
+		}
+	}
+	state.winSize = 0                // follow the size of the terminal until z is given one
+	state.syntaxHighlighting = false // syntax highlighting is disabled by default
+	swap = swapFile{}
+	resetBuffers()
+
+	// SIGINT aborts the command being run, or the one being typed, see input
+	sigs, stopSigs := ccmd.WatchInterrupts()
+	defer stopSigs()
+	interrupts := make(chan struct{}, 1)
+	go func() {
+		for range sigs {
+			interrupted.Store(true)
+			select {
+			case interrupts <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	// SIGHUP and SIGTERM save the buffer and make ed exit, see saveHangup
+	hangup.Store(nil)
+	hups, stopHups := ccmd.WatchHangups()
+	defer stopHups()
+	hangups := make(chan struct{})
+	go func() {
+		if sig, ok := <-hups; ok {
+			hangup.Store(&ccmd.SignalError{Signal: sig})
+			close(hangups)
+		}
+	}()
+	input := newInput(in, interrupts)
+	input.hangups = hangups
+	if swapEvery > 0 {
+		ticker := time.NewTicker(swapEvery)
+		defer ticker.Stop()
+		input.ticks = ticker.C
+	}
+	// the swap files are kept when ed is hung up
+	defer func() {
+		if hangup.Load() == nil {
+			eachBuffer(func(int) { removeSwap() })
+		}
+	}()
+	var commands lineReader = input
+	if textInput != nil {
+		text := newInput(textInput, interrupts)
+		text.hangups, text.ticks = hangups, input.ticks
+		commands = &scriptInput{input, text}
+	}
+	f, ok := in.(*os.File)
+	script := !ok || !ccmd.IsTerminal(f)
+
+	if state.fileName != "" {
+		loaded := buffer
+		if e = recoverSwap(input, out, !script); e != nil {
+			return e
+		}
+		if buffer != loaded && !suppress {
+			fmt.Println(buffer.Size())
+		}
+	}
+
+	if state.prompt {
+		fmt.Fprintf(out, "%s", prompt)
+	}
+	for {
+		cmd, e := input.ReadLine()
+		if errors.Is(e, io.EOF) {
+			break
+		} else if errors.Is(e, errInterrupt) {
+			fmt.Fprintln(out) // after the ^C the terminal echoed
+		} else if hangup.Load() != nil {
+			// the buffer is saved below
+		} else if e != nil {
+			return fmt.Errorf("error reading stdin: %v", e)
+		} else {
+			e = execute(cmd, commands, out)
+			// an interrupt received once the command is done is of no use anymore
+			interrupted.Store(false)
+			select {
+			case <-interrupts:
+			default:
+			}
+		}
+		if sig := hangup.Load(); sig != nil {
+			saveHangup()
+			return sig
+		}
+		updateSwap()
+		if e != nil && !errors.Is(e, errExit) && script && !state.loose {
+			state.failed = true
+		}
+		if e != nil {
+			state.lastErr = e
+			if !suppress && state.printErr {
+				fmt.Fprintf(out, "%s\n", e)
+			} else {
+				fmt.Fprintf(out, "?\n")
+			}
+			if errors.Is(e, errExit) {
+				return nil
+			}
+		}
+		if state.prompt {
+			fmt.Printf("%s", prompt)
+		}
+	}
+	return nil
+}
+
+// interrupted is set when SIGINT is received while a command runs, which then stops at the
+// next line it processes and fails with errInterrupt, as in GNU ed.
+var interrupted atomic.Bool
+
+// checkInterrupt returns errInterrupt if the running command was interrupted, or the
+// *ccmd.SignalError of the hangup if ed was hung up.
+func checkInterrupt() error {
+	if sig := hangup.Load(); sig != nil {
+		return sig
+	}
+	if interrupted.Load() {
+		return errInterrupt
+	}
+	return nil
+}
+
+// A lineReader gives the lines of commands, and of the input mode, one at a time
+type lineReader interface {
+	ReadLine() (string, error)
+}
+
+// input reads the lines of commands and of the input mode. Lines are read by a goroutine, one
+// at a time and only when asked for, so that waiting for one can be interrupted without taking
+// the input of the shell commands run by '!'.
+type input struct {
+	scan       *bufio.Scanner
+	lines      chan string      // closed at the end of the input
+	interrupts <-chan struct{}  // a SIGINT was received
+	hangups    <-chan struct{}  // closed once ed is hung up
+	ticks      <-chan time.Time // the swap file is updated on every tick, see updateSwap
+	waiting    bool             // a line was asked for, and not received yet
+	eof        bool
+}
+
+func newInput(r io.Reader, interrupts <-chan struct{}) *input {
+	return &input{scan: bufio.NewScanner(r), lines: make(chan string), interrupts: interrupts}
+}
+
+// ReadLine returns the next line, io.EOF at the end of the input, or errInterrupt if SIGINT
+// is received first. The line being read is then returned by the next call. Once ed is hung
+// up, it returns the *ccmd.SignalError of the hangup.
+func (in *input) ReadLine() (string, error) {
+	if in.eof {
+		return "", io.EOF
+	}
+	if !in.waiting {
+		in.waiting = true
+		go func() {
+			if in.scan.Scan() {
+				in.lines <- in.scan.Text()
+			} else {
+				close(in.lines)
+			}
+		}()
+	}
+	for {
+		select {
+		case line, ok := <-in.lines:
+			in.waiting = false
+			if !ok {
+				in.eof = true
+				if err := in.scan.Err(); err != nil {
+					return "", err
+				}
+				return "", io.EOF
+			}
+			return line, nil
+		case <-in.interrupts:
+			return "", errInterrupt
+		case <-in.hangups:
+			return "", hangup.Load()
+		case <-in.ticks:
+			updateSwap()
+		}
+	}
+}
+
+// lines and columns of the terminal, kept up to date while ed runs
+var termLines, termColumns atomic.Int32
+
+// scrollSize returns the number of lines z prints when no window size was given:
+// the height of the terminal minus two, as in GNU ed.
+func scrollSize() int {
+	if lines := int(termLines.Load()); lines > 2 {
+		return lines - 2
+	}
+	return ccmd.DefaultTerminalHeight - 2
+}
+
+// listWidth returns the length of the lines l writes, at which it folds longer ones: the
+// width of the terminal
+func listWidth() int {
+	if columns := int(termColumns.Load()); columns > 1 {
+		return columns
+	}
+	return ccmd.DefaultTerminalWidth
+}
+
+// Main runs ed, see ccmd.MainFunc.
+func Main(args []string, stdio ccmd.Stdio) int {
+	// Main may run several times in a process, as in the tests: nothing is kept from
+	// a previous run, the flags being reset as they are defined and the buffers by runEd
+	state, registers, textInput, historyDepth = edState{}, [26][]cut{}, nil, defaultHistoryDepth
+	cmdInfo := &ccmd.CmdInfo{
+		Authors:     []string{"xplshn"},
+		Repository:  "https://github.com/xplshn/a-utils",
+		Name:        "ed",
+		Synopsis:    "[-EGlRrsv] [-p <prompt>] [-e <command>]... [-f <script>]... [--golden <expected>] [--buffers] [--swap <duration>] [file]",
+		Behavior:    "In restricted mode, -r or when invoked as red, shell commands are refused, and so are file names with a '/', \"..\" and \"!command\" in place of a file name, so that only the files of the current directory can be edited. When commands aren't read from a terminal, as for scripts, an error makes ed exit with a status of 1 once done, unless -l is given. With -e and -f, ed runs the commands given, in order, rather than those of the standard input, from which a, i and c read their text instead. --golden tests them: ed writes no file and runs no shell command, the buffer staying modified (so that q warns of it, as Q doesn't), then compares the buffer with the golden file, prints their differences as a unified diff and exits with a status of 1 if there are. On SIGHUP or SIGTERM, ed writes the buffer to ed.hup, or $HOME/ed.hup, if it has changes that weren't written (buffer N of --buffers to ed.hup.N), and exits. With --swap, the buffer is also written every so often to .FILE.swp, next to the file, which is removed once the buffer is written or ed quits; when it is newer than the file, -R recovers it, and ed asks whether to when it reads a terminal.",
+		Description: "The standard Unix text editor",
+		EnvVars: []ccmd.EnvVar{
+			{Name: "A_SYHX_COLOR_SCHEME", Description: "Style of the syntax highlighting, when the '_' command doesn't name one"},
+			{Name: "A_SYHX_FORMATTER", Description: "Formatter of the syntax highlighting: terminal8, terminal16, terminal256 or terminal16m. By default it depends on the colors the terminal supports"},
+			{Name: "TMPDIR", Description: "Directory of the scratch file of large buffers"},
+			{Name: "HOME", Description: "Directory of ed.hup when it can't be written to the current directory"},
+		},
+		Extensions: []ccmd.Extension{
+			{Name: "U", Description: "Undoes the last change that wasn't undone, going further back in the history every time, while u undoes its own undo"},
+			{Name: "R", Description: "Redoes the last change undone"},
+			{Name: "L", Description: "Lists the changes kept for undo and the commands that made them. The current one is marked with '*', those after it were undone and can be redone"},
+			{Name: "\"a", Description: "y, x and d followed by \"a to \"z yank to, paste from and delete to a named register, shared by the buffers. An upper-case name appends to the register"},
+			{Name: "o", Description: "With --buffers, opens a file in a new buffer, with its own file name, marks and undo history, which becomes the current one"},
+			{Name: "b", Description: "With --buffers, switches to the buffer numbered, or to the next one"},
+			{Name: "B", Description: "With --buffers, lists the buffers: their number, '*' for the current one, '+' for those with changes that weren't written, and their file name"},
+		},
+		CustomFields: map[string]interface{}{
+			"Notes": `Known Differences:
+					 - Regular expressions are POSIX basic ones, or extended ones with -E, translated to the syntax of go's 'regexp' package, with the GNU extensions. Back-references can't be used in patterns, only in the replacement of 's' (as '\\<ref>'), and '\\<' and '\\>' match at any word boundary.
+					 - "Traditional" mode (-G) only affects m and t, which then need a destination address.
+					 - Rather than being an error, the 'g' option for 's' simply overrides any specified count.
+					
+					Implemented Features:
+					 - Full line address parsing (including RE and markings)
+					 - Implemented commands: !, #, =, E, G, H, P, Q, V, W, a, c, d, e, f, g, h, i, j, k, l, m, n, p, q, r, s, t, u, v, w, x, y, z
+					 - Syntax highlighting: _ <|pathToChromaStyle.xml|<styleName|>
+					 - Undo history: U, R, L
+					 - Restricted mode: -r, or invoked as red
+					 - The options of GNU Ed: -E, -G, -l, -p, -r, -s, -v and their long names
+					 - Large files: past 64 MiB, lines are kept in a scratch file of the temporary directory
+					 - Named registers: y, x and d followed by "a to "z
+					 - Multiple buffers: o, b and B, with --buffers
+					 - Scripts: -e and -f, tested against a golden file with --golden
+					 - Hangups: the buffer is saved to ed.hup, and with --swap to a swap file while it is edited, which -R recovers`,
+		},
+	}
+	fs := ccmd.NewFlagSet("ed", flag.ContinueOnError)
+	fs.BoolVar(&fsuppress, "s", false, "suppress counts")
+	fs.StringVar(&fprompt, "p", "*", "specify a command prompt")
+	fs.BoolVar(&frestricted, "r", false, "restricted mode: no shell commands, and only the files of the current directory")
+	fs.BoolVar(&state.extendedRE, "E", false, "use POSIX extended regular expressions")
+	fs.BoolVar(&state.traditional, "G", false, "traditional mode, for compatibility with older eds")
+	fs.BoolVar(&state.loose, "l", false, "exit with a status of 0 even if commands of a script fail")
+	fs.BoolVar(&fverbose, "v", false, "print the messages of errors, rather than '?', as H does")
+	fs.IntVar(&historyDepth, "undo-depth", defaultHistoryDepth, "keep the last N changes for undo, 0 for no limit")
+	fs.BoolVar(&state.recover, "R", false, "recover the changes of the swap file of the file, if it is newer")
+	var script strings.Builder
+	fs.Var(scriptFlag{script: &script}, "e", "run the command CMD rather than those of the standard input, from which a, i and c read their text")
+	fs.Var(scriptFlag{file: true, script: &script}, "f", "run the commands of the file SCRIPT rather than those of the standard input, from which a, i and c read their text")
+	fs.StringVar(&state.golden, "golden", "", "test the commands: compare the buffer with the file EXPECTED once they are run, printing the differences, and write no file")
+	fs.BoolVar(&state.multiBuffer, "buffers", false, "edit several files, with the o, b and B commands; q and Q then close the current buffer while others are open")
+	fs.DurationVar(&swapEvery, "swap", 0, "save the buffer to a swap file next to the file every DURATION (e.g. 30s) while it has changes that weren't written")
+	for _, alias := range [][2]string{
+		{"extended-regexp", "E"}, {"traditional", "G"}, {"loose-exit-status", "l"}, {"prompt", "p"},
+		{"restricted", "r"}, {"quiet", "s"}, {"silent", "s"}, {"verbose", "v"}, {"recover", "R"},
+		{"expression", "e"}, {"file", "f"},
+	} {
+		fs.Alias(alias[0], alias[1])
+	}
+	for _, name := range []string{"E", "G", "l", "R", "e", "f", "r", "v", "golden", "undo-depth", "buffers", "swap", "prompt", "quiet", "silent"} {
+		fs.Extension(name)
+	}
+	fs.Usage = func() {
+		helpPage, err := cmdInfo.GenerateHelpPage()
+		if err != nil {
+			ccmd.Fatalf("generating help page: %v", err)
+		}
+		fmt.Fprint(stdio.Out, helpPage)
+	}
+	cmdInfo.Flags = fs
+	cmdInfo.ParseArgs(args[1:])
+	state.restricted = frestricted || filepath.Base(args[0]) == "red"
+	state.printErr = fverbose
+
+	size := ccmd.GetTerminalSize()
+	termLines.Store(int32(size.Height))
+	termColumns.Store(int32(size.Width))
+	sizes, stopWatching := ccmd.WatchTerminalSize()
+	defer stopWatching()
+	go func() {
+		for size := range sizes {
+			termLines.Store(int32(size.Height))
+			termColumns.Store(int32(size.Width))
+		}
+	}()
+	file := ""
+	switch len(fs.Args()) {
+	case 0:
+	case 1:
+		file = fs.Args()[0]
+		if err := checkFileName(file); err != nil {
+			ccmd.Fatalf("%s: %v", file, err)
+		}
+	default:
+		fs.Usage()
+		return int(ccmd.ExitUsage)
+	}
+	commands, prompt := stdio.In, fprompt
+	if script.Len() > 0 {
+		// scripts aren't prompted for, the standard input has the text of a, i and c
+		commands, prompt, textInput = strings.NewReader(script.String()), "", stdio.In
+	}
+	if err := runEd(commands, stdio.Out, fsuppress, prompt, file); err != nil {
+		var sig *ccmd.SignalError
+		if errors.As(err, &sig) {
+			return int(sig.ExitCode())
+		}
+		ccmd.Fatalf("%v", err)
+	}
+	if state.golden != "" {
+		differ, err := compareGolden(stdio.Out)
+		if err != nil {
+			ccmd.Fatalf("%v", err)
+		}
+		if differ {
+			return int(ccmd.ExitFailure)
+		}
+	}
+	if state.failed {
+		return int(ccmd.ExitFailure)
+	}
+	return 0
+}
@@ -0,0 +1,482 @@
+// Copyright 2019 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ed
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xplshn/a-utils/pkg/ccmd"
+)
+
+var (
+	testdata = `To be fair, this is just random weirdo stuff going on.
+We learn something new every day.`
+)
+
+// TestEDCOmmandsNonInput tests ed without commands which invoke input mode.
+// Input mode requires a different, more complex test setup to work.
+func TestEdCommandsNonInput(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		cmd      string
+		prompt   string
+		suppress bool
+		wantOut  string
+	}{
+		{
+			name:    "CmdErr_Only_On",
+			cmd:     "H\nq\n",
+			wantOut: "exit\n",
+		},
+		{
+			name:    "CmdErr_On_Off",
+			cmd:     "H\nH\nq\n",
+			wantOut: "exit\n",
+		},
+		{
+			name:    "CmdErr_printErr",
+			cmd:     "4d\nh\nq\n",
+			wantOut: "line is out of bounds\nline is out of bounds\nexit\n",
+		},
+		{
+			name:    "cmdDelete_undo",
+			cmd:     "-1 d\nu\nq\n",
+			wantOut: "exit\n",
+		},
+		{
+			name:    "cmdDelete_invalidAddr",
+			cmd:     "4 d\nu\nq\n",
+			wantOut: "line is out of bounds\nexit\n",
+		},
+		{
+			name:    "CmdPrint_List",
+			cmd:     "-1 l\nq\n",
+			wantOut: "To be fair, this is just random weirdo stuff going on.$\nexit\n",
+		},
+		{
+			name:    "CmdPrint_Print_previous_line",
+			cmd:     "-1 p\nq\n",
+			wantOut: "To be fair, this is just random weirdo stuff going on.\nexit\n",
+		},
+		{
+			name:    "CmdPrint_Print_addressed line",
+			cmd:     "-1 n\nq\n",
+			wantOut: "1\tTo be fair, this is just random weirdo stuff going on.\nexit\n",
+		},
+		{
+			name:    "CmdPrint_InvalidAddr",
+			cmd:     "4 l\nq\n",
+			wantOut: "line is out of bounds\nexit\n",
+		},
+		{
+			name:    "CmdWrite_simple",
+			cmd:     "w\nq\n",
+			wantOut: "exit\n",
+		},
+		{
+			name:    "CmdWrite_simple_invalidAddr",
+			cmd:     "4 w\nq\n",
+			wantOut: "line is out of bounds\nexit\n",
+		},
+		{
+			name:    "CmdWrite_Quit",
+			cmd:     "wq\nq\n",
+			wantOut: "exit\n",
+		},
+		{
+			name:    "CmdWrite_W",
+			cmd:     "W\nq\n",
+			wantOut: "exit\n",
+		},
+		{
+			name:    "CmdMark_simple",
+			cmd:     "k 0\nq\n",
+			wantOut: "exit\n",
+		},
+		{
+			name:    "CmdMark_No_mark_character_supplied",
+			cmd:     "-1 k\nq\n",
+			wantOut: "no mark character supplied\nexit\n",
+		},
+		{
+			name:    "cmdLine_PrintLine",
+			cmd:     "- =\np\nq\n", // This equals to the input of `- =`[Enter press] `p` [Enter press]
+			wantOut: "1\nWe learn something new every day.\nexit\n",
+		},
+		{
+			name:    "cmdFile_setFile_default",
+			cmd:     "f\nq\n",
+			wantOut: "exit\n",
+		},
+		{
+			name:    "CmdMove_Move_simple",
+			cmd:     "1 m 2\nu\nq\n",
+			wantOut: "exit\n",
+		},
+		{
+			name:    "CmdCopy_Cut",
+			cmd:     "y\nq\n",
+			wantOut: "exit\n",
+		},
+		{
+			name:    "CmdScroll",
+			cmd:     "-1 z\nu\nq\n",
+			wantOut: "To be fair, this is just random weirdo stuff going on.\nWe learn something new every day.\nexit\n",
+		},
+		{
+			name:    "CmdScroll_invalidAddr",
+			cmd:     "4 z\nu\nq\n",
+			wantOut: "line is out of bounds\nexit\n",
+		},
+		{
+			name:    "CmdScroll_invalid_windowsize",
+			cmd:     "-1 z 0\nu\nq\n",
+			wantOut: "invalid window size:  0\nexit\n",
+		},
+		{
+			name:    "CmdSub_You_We_in_line2_1_p",
+			cmd:     "2 s/\\(We\\)/You/p\nu\nq\n",
+			wantOut: "You learn something new every day.\nexit\n",
+		},
+		{
+			name:    "CmdSub_You_We_in_line2_2_l",
+			cmd:     "2 s/\\(We\\)/You/l\nu\nq\n",
+			wantOut: "You learn something new every day.$\nexit\n",
+		},
+		{
+			name:    "CmdSub_You_We_in_line2_3_n",
+			cmd:     "2 s/\\(We\\)/You/n\nu\nq\n",
+			wantOut: "2\tYou learn something new every day.\nexit\n",
+		},
+		{
+			name:    "CmdSub_You_We_in_line2_3_g",
+			cmd:     "2 s/\\(We\\)/You/g\np\nu\nq\n",
+			wantOut: "You learn something new every day.\nexit\n",
+		},
+		{
+			name:    "CmdSub_invalidAddr",
+			cmd:     "4 s/\\(We\\)/You/n\nu\nq\n",
+			wantOut: "line is out of bounds\nexit\n",
+		},
+		{
+			name:    "CmdQuit_Buffer_dirty",
+			cmd:     "2 s/\\(We\\)/You/n\nq\nu\nq",
+			wantOut: "2\tYou learn something new every day.\nwarning: file modified\nexit\n",
+		},
+		{
+			name:    "CmdEdit_undo",
+			cmd:     "e\nu\nq\n",
+			wantOut: "87\nexit\n",
+		},
+		{
+			name:    "CmdPaste",
+			cmd:     "x\nq\n",
+			wantOut: "exit\n",
+		},
+		{
+			name:    "CmdJoin",
+			cmd:     "-1,2 j\np\nu\nq\n",
+			wantOut: "To be fair, this is just random weirdo stuff going on.We learn something new every day.\nexit\n",
+		},
+		{
+			name:    "CmdJoin_invalidAddr",
+			cmd:     "-3 j\nu\nq\n",
+			wantOut: "line is out of bounds\nexit\n",
+		},
+		{
+			name:    "CmdAppend",
+			cmd:     "1a\nnew\n.\n2p\nQ\n",
+			wantOut: "new\nexit\n",
+		},
+		{
+			name:    "CmdGlobal",
+			cmd:     "g/o/p\nQ\n",
+			wantOut: "To be fair, this is just random weirdo stuff going on.\nWe learn something new every day.\nexit\n",
+		},
+		{
+			name:    "CmdGlobal_v",
+			cmd:     "v/fair/n\nQ\n",
+			wantOut: "2\tWe learn something new every day.\nexit\n",
+		},
+		{
+			name:    "CmdGlobal_command_list",
+			cmd:     "g/fair/s/fair/just/\\\np\nQ\n",
+			wantOut: "To be just, this is just random weirdo stuff going on.\nexit\n",
+		},
+		{
+			name:    "CmdGlobal_input",
+			cmd:     "g/We/a\\\nadded\n3p\nQ\n",
+			wantOut: "added\nexit\n",
+		},
+		{
+			name:    "CmdGlobal_some_sub_match",
+			cmd:     "g/./s/fair/just/\n1p\nQ\n",
+			wantOut: "To be just, this is just random weirdo stuff going on.\nexit\n",
+		},
+		{
+			name:    "CmdGlobal_sub_every_line",
+			cmd:     "g/./s/e/E/g\n1p\n2p\nQ\n",
+			wantOut: "To bE fair, this is just random wEirdo stuff going on.\nWE lEarn somEthing nEw EvEry day.\nexit\n",
+		},
+		{
+			name:    "CmdGlobal_sub_second_line",
+			cmd:     "g/We/s/e/E/g\n1p\n2p\nQ\n",
+			wantOut: "To be fair, this is just random weirdo stuff going on.\nWE lEarn somEthing nEw EvEry day.\nexit\n",
+		},
+		{
+			name:    "CmdGlobal_v_delete_range",
+			cmd:     "2,2v/fair/d\n1p\n2p\nQ\n",
+			wantOut: "To be fair, this is just random weirdo stuff going on.\nline is out of bounds\nexit\n",
+		},
+		{
+			name:    "CmdGlobal_v_delete_range_none",
+			cmd:     "2,2v/We/d\n1p\n2p\nQ\n",
+			wantOut: "To be fair, this is just random weirdo stuff going on.\nWe learn something new every day.\nexit\n",
+		},
+		{
+			name:    "CmdSub_second_line",
+			cmd:     "2s/new/old/n\n1p\n2p\nQ\n",
+			wantOut: "2\tWe learn something old every day.\nTo be fair, this is just random weirdo stuff going on.\nWe learn something old every day.\nexit\n",
+		},
+		{
+			name:    "CmdGlobal_undo",
+			cmd:     "g/./s/e/E/g\nu\n1p\n2p\nQ\n",
+			wantOut: "To be fair, this is just random weirdo stuff going on.\nWe learn something new every day.\nexit\n",
+		},
+		{
+			name:    "CmdGlobal_delete",
+			cmd:     "g/./1,2d\nu\n2p\nQ\n",
+			wantOut: "We learn something new every day.\nexit\n",
+		},
+		{
+			name:    "CmdGlobal_interactive",
+			cmd:     "G/./\nn\n&\nQ\n",
+			wantOut: "To be fair, this is just random weirdo stuff going on.\n1\tTo be fair, this is just random weirdo stuff going on.\nWe learn something new every day.\n2\tWe learn something new every day.\nexit\n",
+		},
+		{
+			name:    "CmdGlobal_interactive_skip",
+			cmd:     "V/fair/\n\nQ\n",
+			wantOut: "We learn something new every day.\nexit\n",
+		},
+		{
+			name:    "CmdGlobal_nested",
+			cmd:     "g/./g/./p\nQ\n",
+			wantOut: "cannot nest global commands\nexit\n",
+		},
+		{
+			name:    "CmdUndo_undo_redo",
+			cmd:     "1d\n1d\nU\nU\n1p\n2p\nR\np\nQ\n",
+			wantOut: "To be fair, this is just random weirdo stuff going on.\nWe learn something new every day.\nWe learn something new every day.\nexit\n",
+		},
+		{
+			name:    "CmdUndo_u_undoes_undo",
+			cmd:     "1d\n1d\nU\nU\nu\n1p\nQ\n",
+			wantOut: "We learn something new every day.\nexit\n",
+		},
+		{
+			name:    "CmdUndo_nothing_to_undo",
+			cmd:     "U\nR\nQ\n",
+			wantOut: "nothing to undo\nnothing to redo\nexit\n",
+		},
+		{
+			name:    "CmdHistory",
+			cmd:     "1d\n1d\nU\nL\nQ\n",
+			wantOut: "*1\t1d\n 2\t1d\nexit\n",
+		},
+		{
+			name:    "CmdDump",
+			cmd:     "D\nq\n",
+			wantOut: "&{[] [To be fair, this is just random weirdo stuff going on. We learn something new every day.] [0 1] [{ [0 1] 1 1}] 0 0 false false false 1 1 map[]}\nexit\n",
+		},
+	} {
+		t.Run("Command:"+tt.cmd, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			tmpFile, err := os.CreateTemp(tmpDir, "testfile-")
+			if err != nil {
+				t.Errorf("os.CreateTemp(tmpdir, `testfile-`)=file, %q, want file, nil", err)
+			}
+			var in, out bytes.Buffer
+			in.WriteString(tt.cmd)
+			tmpFile.WriteString(testdata)
+			if err := runEd(&in, &out, tt.suppress, tt.prompt, tmpFile.Name()); err != nil {
+				t.Errorf(`runEd(&in, &out, tt.suppress, tt.prompt, "")=%q, want nil`, err)
+			}
+			if out.String() != tt.wantOut {
+				t.Errorf("%s failed. Got: %s, Want: %s", tt.cmd, out.String(), tt.wantOut)
+			}
+		})
+	}
+}
+
+// TestInterrupt tests that SIGINT aborts the command being run, or the input being waited for.
+func TestInterrupt(t *testing.T) {
+	saved := buffer
+	defer func() { buffer = saved }()
+	buffer = NewFileBuffer([]string{"one", "two"})
+	interrupted.Store(true)
+	defer interrupted.Store(false)
+	var out bytes.Buffer
+	if err := execute(",p", nil, &out); !errors.Is(err, errInterrupt) || out.Len() != 0 {
+		t.Errorf("execute(\",p\") = %v, printing %q, want errInterrupt", err, out.String())
+	}
+
+	interrupts := make(chan struct{}, 1)
+	interrupts <- struct{}{}
+	in := newInput(strings.NewReader(""), interrupts)
+	if _, err := in.ReadLine(); !errors.Is(err, errInterrupt) {
+		t.Errorf("ReadLine() = %v, want errInterrupt", err)
+	}
+	if _, err := in.ReadLine(); err != io.EOF {
+		t.Errorf("ReadLine() = %v once interrupted, want io.EOF", err)
+	}
+}
+
+func TestRestricted(t *testing.T) {
+	saved := buffer
+	defer func() { buffer, state.restricted = saved, false }()
+	buffer = NewFileBuffer([]string{"one", "two"})
+	state.restricted = true
+	for cmd, want := range map[string]error{
+		"!echo hi":         errShellRestricted,
+		"e !echo hi":       errShellRestricted,
+		"r !echo hi":       errShellRestricted,
+		"w !cat":           errShellRestricted,
+		"E /etc/passwd":    errDirectoryRestricted,
+		"r ../file":        errDirectoryRestricted,
+		"W dir/file":       errDirectoryRestricted,
+		"e ..":             errDirectoryRestricted,
+		"f /tmp/file":      errDirectoryRestricted,
+		"_ /tmp/theme.xml": errDirectoryRestricted,
+	} {
+		var out bytes.Buffer
+		if err := execute(cmd, nil, &out); !errors.Is(err, want) {
+			t.Errorf("execute(%q) = %v, want %v", cmd, err, want)
+		}
+	}
+	if err := checkFileName("file"); err != nil {
+		t.Errorf("checkFileName(%q) = %v, want nil", "file", err)
+	}
+}
+
+func TestScriptExitStatus(t *testing.T) {
+	defer func() { state.failed, state.loose = false, false }()
+	for _, tt := range []struct {
+		cmd   string
+		loose bool
+		want  bool
+	}{
+		{cmd: "1p\nq\n", want: false},
+		{cmd: "4d\nq\n", want: true},
+		{cmd: "4d\nq\n", loose: true, want: false},
+	} {
+		state.loose = tt.loose // runEd resets state.failed
+		file := filepath.Join(t.TempDir(), "file")
+		if err := os.WriteFile(file, []byte(testdata), 0o666); err != nil {
+			t.Fatal(err)
+		}
+		var out bytes.Buffer
+		if err := runEd(strings.NewReader(tt.cmd), &out, true, "", file); err != nil {
+			t.Fatal(err)
+		}
+		if state.failed != tt.want {
+			t.Errorf("%q with loose = %v: failed = %v, want %v", tt.cmd, tt.loose, state.failed, tt.want)
+		}
+	}
+}
+
+// Test that a run of Main keeps nothing from the previous one
+func TestMainTwice(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir()) // no flag file
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, []byte(testdata), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		args []string
+		want int
+	}{
+		{args: []string{"ed", "-s", "-E", "--undo-depth=1", "-e", `1y"a`, "-e", "4d", file}, want: 1},
+		{args: []string{"ed", "-s", "-e", "1p", file}, want: 0},
+	} {
+		var out bytes.Buffer
+		stdio := ccmd.Stdio{In: strings.NewReader(""), Out: &out, Err: io.Discard}
+		if got := Main(tt.args, stdio); got != tt.want {
+			t.Errorf("Main(%q) = %d, want %d", tt.args, got, tt.want)
+		}
+	}
+	if state.extendedRE || historyDepth != defaultHistoryDepth || len(registerText(0)) != 0 {
+		t.Errorf("the second run kept -E: %v, --undo-depth: %d, register a: %q", state.extendedRE, historyDepth, registerText(0))
+	}
+}
+
+func TestOptions(t *testing.T) {
+	saved := buffer
+	defer func() { buffer, state.extendedRE, state.traditional = saved, false, false }()
+	for _, tt := range []struct {
+		name        string
+		extendedRE  bool
+		traditional bool
+		cmd         string
+		want        string
+		err         string
+	}{
+		{name: "BRE", cmd: `1s/a\(b\)\{1\}/X/`, want: "Xc\ndef"},
+		{name: "BRE: ordinary characters", cmd: "1s/a|ab/X/", err: "no match"},
+		{name: "BRE: leftmost longest", cmd: `1s/a\|ab/X/`, want: "Xc\ndef"},
+		{name: "-E: leftmost longest", extendedRE: true, cmd: "1s/a|ab/X/", want: "Xc\ndef"},
+		{name: "-E: back-reference", extendedRE: true, cmd: `1s/(a)\1/X/`, err: errBackref.Error()},
+		{name: "m without destination", cmd: "1m", want: "def\nabc"},
+		{name: "-G: m without destination", traditional: true, cmd: "1m", err: "destination expected"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			buffer = NewFileBuffer([]string{"abc", "def"})
+			buffer.SetAddr(1)
+			state.extendedRE, state.traditional = tt.extendedRE, tt.traditional
+			var out bytes.Buffer
+			err := execute(tt.cmd, nil, &out)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("execute(%q) = %v, want %s", tt.cmd, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("execute(%q) = %v", tt.cmd, err)
+			}
+			lines, _ := buffer.Get([2]int{0, buffer.Len() - 1})
+			if got := strings.Join(lines, "\n"); got != tt.want {
+				t.Errorf("after %q, the buffer is %q, want %q", tt.cmd, got, tt.want)
+			}
+		})
+	}
+}
+
+// Test the escaping and the folding of l
+func TestList(t *testing.T) {
+	for _, tt := range []struct {
+		width int
+		line  string
+		want  string
+	}{
+		{80, "a\tb$c\\", `a\tb\$c\\$`},
+		{80, "\x01\x1b[0mé\xff\a\b\f\r\v", `\001\033[0mé\377\a\b\f\r\v$`},
+		{10, "abcdefghijklmnop", "abcdefghi\\\njklmnop$"},
+		{10, "abcdefghi", "abcdefghi\\\n$"},
+		{6, "abcd\x01", "abcd\\\n\\001$"},
+		{10, "漢字漢字漢字", "漢字漢字\\\n漢字$"},
+		{6, "abcde\u0301f", "abcde\u0301\\\nf$"},
+	} {
+		list := &lister{width: tt.width}
+		if got := list.list(tt.line) + list.end(); got != tt.want {
+			t.Errorf("l of %q in %d columns = %q, want %q", tt.line, tt.width, got, tt.want)
+		}
+	}
+}
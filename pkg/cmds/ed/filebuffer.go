@@ -0,0 +1,501 @@
+// Copyright 2019 the u-root Authors. All rights reserved
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// filebuffer.go - defines the FileBuffer object
+package ed
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+)
+
+// A FileBuffer manages a file being edited.
+// A FileBuffer never deletes/modifies anything directly until it is replaced.
+// It keeps a map of known lines to the current buffer.
+// Lines are kept in memory until they outgrow scratchSize, then in a scratch file, so that
+// files larger than memory can be edited.
+// Note: FileBuffer is 0-addressed lines, so off-by-one from what `ed` expects.
+type FileBuffer struct {
+	cbuf    cut      // cut buffer
+	buffer  []string // all lines we know about, they never get delited
+	bufSize int      // size of the lines of buffer
+	scratch *scratch // all lines we know about once they outgrew buffer, nil until then
+	file    *rope    // sequence of buffer lines
+	history []change // states of file, the oldest first, for undo and redo
+	pos     int      // current state of file in history
+	saved   int      // state of file in history last written, -1 if none is kept
+	undone  bool     // the last change was an undo, which Rewind redoes
+	dirty   bool     // tracks if the file has been modifed
+	mod     bool     // mod is like dirty, but can be reset for transactions
+	addr    int      // current file address
+	tmpAddr int      // address at the start of the transaction (for undo)
+	marks   map[byte]int
+}
+
+// A change is a state of the file in the undo history, and the command that made it
+type change struct {
+	cmd           string
+	file          *rope
+	before, after int // current address before and after the command
+}
+
+// A cut is lines of a FileBuffer, kept as their identifiers until they are pasted, so that
+// cutting lines of the scratch file doesn't read them
+type cut struct {
+	from *FileBuffer // the buffer that has the lines
+	ids  *rope
+}
+
+// Len returns the number of lines of the cut
+func (c cut) Len() int {
+	return c.ids.Len()
+}
+
+// text returns the lines of the cut
+func (c cut) text() (lines []string) {
+	c.ids.eachPiece(0, c.ids.Len(), func(start, n int) {
+		for id := start; id < start+n; id++ {
+			lines = append(lines, c.from.line(id))
+		}
+	})
+	return
+}
+
+// defaultHistoryDepth is the number of changes kept for undo unless --undo-depth says otherwise
+const defaultHistoryDepth = 100
+
+// historyDepth is the number of changes kept for undo, 0 for no limit
+var historyDepth = defaultHistoryDepth
+
+// NewFileBuffer creats a new FileBuffer object
+func NewFileBuffer(in []string) *FileBuffer {
+	f := &FileBuffer{
+		buffer: in,
+		dirty:  false,
+		mod:    false,
+		addr:   0,
+		marks:  make(map[byte]int),
+	}
+	for _, l := range in {
+		f.bufSize += len(l)
+	}
+	if len(in) > 0 {
+		f.file = newPiece(0, len(in))
+	}
+	f.resetHistory()
+	return f
+}
+
+// resetHistory forgets the changes made so far, the current file being the one on disk
+func (f *FileBuffer) resetHistory() {
+	f.history = []change{{file: f.file, before: f.addr, after: f.addr}}
+	f.pos, f.saved, f.undone = 0, 0, false
+}
+
+// line returns the line identified by id
+func (f *FileBuffer) line(id int) string {
+	if f.scratch != nil {
+		return f.scratch.line(id)
+	}
+	return f.buffer[id]
+}
+
+// lines returns the number of lines we know about, the identifier of the next one added
+func (f *FileBuffer) lines() int {
+	if f.scratch != nil {
+		return f.scratch.n
+	}
+	return len(f.buffer)
+}
+
+// add adds lines to those we know about, moving them all to a scratch file once they outgrow
+// scratchSize
+func (f *FileBuffer) add(lines []string) (e error) {
+	if f.scratch != nil {
+		return f.scratch.add(lines)
+	}
+	size := f.bufSize
+	for _, l := range lines {
+		size += len(l)
+	}
+	if size <= scratchSize {
+		f.buffer = append(f.buffer, lines...)
+		f.bufSize = size
+		return
+	}
+	var s *scratch
+	if s, e = newScratch(); e != nil {
+		return
+	}
+	if e = s.add(f.buffer); e != nil {
+		return
+	}
+	if e = s.add(lines); e != nil {
+		return
+	}
+	f.scratch, f.buffer, f.bufSize = s, nil, 0
+	return
+}
+
+// ErrOOB line is out of bounds
+var ErrOOB = fmt.Errorf("line is out of bounds")
+
+// ErrINV address is invalid
+var ErrINV = fmt.Errorf("invalid address")
+
+// OOB checks if a line is out of bounds
+func (f *FileBuffer) OOB(l int) bool {
+	if l < 0 || l >= f.Len() {
+		return true
+	}
+	return false
+}
+
+// GetMust gets a specified line, to be used when we know it's safe (no error return)
+// if "set" is true, sets the current line pointer
+func (f *FileBuffer) GetMust(line int, set bool) string {
+	if set {
+		f.addr = line
+	}
+	return f.line(f.file.At(line))
+}
+
+// Get a specified line range
+// this updates the current line pointer
+func (f *FileBuffer) Get(r [2]int) (lines []string, e error) {
+	if f.OOB(r[0]) || f.OOB(r[1]) {
+		e = ErrOOB
+		return
+	}
+	f.file.eachPiece(r[0], r[1]+1, func(start, n int) {
+		for id := start; id < start+n; id++ {
+			lines = append(lines, f.line(id))
+		}
+	})
+	if r[0] <= r[1] {
+		f.addr = r[1]
+	}
+	return
+}
+
+// cut returns the lines of the range r, which may be empty, as a cut
+func (f *FileBuffer) cut(r [2]int) cut {
+	return cut{f, f.file.slice(r[0], r[1]+1)}
+}
+
+// Copy lines into the cut buffer
+// this updates the current line pointer, as Get does
+func (f *FileBuffer) Copy(r [2]int) (e error) {
+	if f.OOB(r[0]) || f.OOB(r[1]) {
+		return ErrOOB
+	}
+	f.cbuf = f.cut(r)
+	if r[0] <= r[1] {
+		f.addr = r[1]
+	}
+	return
+}
+
+// Paste lines from cut buffer insert at line
+func (f *FileBuffer) Paste(line int) (e error) {
+	e = f.Insert(line, f.cbuf.text())
+	return
+}
+
+// Delete unmaps lines from the file
+func (f *FileBuffer) Delete(r [2]int) (e error) {
+	if r[0] <= r[1] && (f.OOB(r[0]) || f.OOB(r[1])) {
+		return ErrOOB
+	}
+	f.cbuf = f.cut(r)
+	if r[0] <= r[1] {
+		f.file = f.file.delete(r[0], r[1]+1)
+	}
+	f.Touch()
+	f.addr = r[0] + 1
+	if f.OOB(f.addr) {
+		f.addr = 0
+	}
+	return
+}
+
+// Insert adds nlines to buffer and inserts them at line
+func (f *FileBuffer) Insert(line int, nlines []string) (e error) {
+	if line != f.Len() && f.OOB(line) { // if line == f.Len() we append to the end
+		return ErrOOB
+	}
+	if len(nlines) == 0 {
+		return
+	}
+	first := f.lines()
+	if e = f.add(nlines); e != nil {
+		return
+	}
+	f.file = f.file.insert(line, newPiece(first, len(nlines)))
+	f.Touch()
+	f.addr = line + len(nlines) - 1
+	return
+}
+
+// LineID returns an identifier of line l, which follows the line as others are inserted or
+// deleted, see FindLine
+func (f *FileBuffer) LineID(l int) int {
+	return f.file.At(l)
+}
+
+// FindLine returns the line identified by id, see LineID, looking from line hint onwards
+// first, reports false if the line was deleted. Unless the line is at hint, this takes a time
+// linear in the number of pieces of the file, which is the number of lines at worst, see
+// rope.index.
+func (f *FileBuffer) FindLine(id, hint int) (int, bool) {
+	if !f.OOB(hint) && f.file.At(hint) == id {
+		return hint, true
+	}
+	return f.file.index(id)
+}
+
+// Len returns the current file length
+func (f *FileBuffer) Len() int {
+	return f.file.Len()
+}
+
+// Dirty returns whether the file has changed
+func (f *FileBuffer) Dirty() bool {
+	return f.dirty
+}
+
+// GetAddr gets the current file addr
+func (f *FileBuffer) GetAddr() int {
+	return f.addr
+}
+
+// SetAddr sets the current addr, errors if OOB
+func (f *FileBuffer) SetAddr(i int) (e error) {
+	if f.OOB(i) {
+		return ErrOOB
+	}
+	f.addr = i
+	return
+}
+
+// Clean resets the dirty flag, the current file being the one on disk
+func (f *FileBuffer) Clean() {
+	f.dirty = false
+	f.saved = f.pos
+}
+
+// FileToBuffer reads a file and creates a new FileBuffer from it
+func FileToBuffer(file string) (fb *FileBuffer, e error) {
+	fb = NewFileBuffer(nil)
+	e = fb.ReadFile(0, file)
+	if e == nil {
+		fb.dirty = false
+		fb.mod = false
+		fb.resetHistory()
+	}
+	return
+}
+
+// SetMark sets a mark (by byte name) in the FileBuffer for later use
+func (f *FileBuffer) SetMark(c byte, l int) (e error) {
+	if f.OOB(l) {
+		e = ErrOOB
+		return
+	}
+	f.marks[c] = f.file.At(l)
+	return
+}
+
+// GetMark gets a mark from the FileBuffer (by byte name)
+// marks are kept as line identifiers, found as FindLine does, in a time linear in the number
+// of pieces of the file
+func (f *FileBuffer) GetMark(c byte) (l int, e error) {
+	bl, ok := f.marks[c]
+	if !ok {
+		return -1, fmt.Errorf("no such mark: %c", c)
+	}
+	if l, ok := f.file.index(bl); ok {
+		return l, nil
+	}
+	return -1, fmt.Errorf("mark was cleared: %c", c)
+}
+
+// Size return the size (in bytes) of the current file buffer
+func (f *FileBuffer) Size() (s int) {
+	f.file.eachPiece(0, f.Len(), func(start, n int) {
+		if f.scratch != nil {
+			s += int(f.scratch.textBefore(start+n) - f.scratch.textBefore(start))
+			return
+		}
+		for _, l := range f.buffer[start : start+n] {
+			s += len(l)
+		}
+	})
+	return
+}
+
+// readBatch is the number of lines Read adds at once, so that it doesn't hold a large file
+var readBatch = 4096
+
+// Read reads in from an io.Reader interface and inserts at the current line address
+func (f *FileBuffer) Read(line int, r io.Reader) (e error) {
+	if line != f.Len() && f.OOB(line) { // if line == f.Len() we append to the end
+		return ErrOOB
+	}
+	first, n := f.lines(), 0
+	b := []string{}
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, math.MaxInt)
+	for e == nil && s.Scan() {
+		if b = append(b, s.Text()); len(b) == readBatch {
+			if e = f.add(b); e == nil {
+				n, b = n+len(b), b[:0]
+			}
+		}
+	}
+	if e == nil {
+		if e = f.add(b); e == nil {
+			n, e = n+len(b), s.Err()
+		}
+	}
+	if n == 0 {
+		return
+	}
+	// what was read before an error is inserted too
+	f.file = f.file.insert(line, newPiece(first, n))
+	f.Touch()
+	f.addr = line + n - 1
+	return
+}
+
+// ReadFile reads in a file and inserts it at the current line address
+func (f *FileBuffer) ReadFile(line int, file string) (e error) {
+	var fh *os.File
+	if fh, e = os.Open(file); e != nil {
+		e = fmt.Errorf("could not read file: %v", e)
+		return
+	}
+	defer fh.Close()
+
+	e = f.Read(line, fh)
+	return
+}
+
+// WriteTo writes the lines of the buffer to w, each followed by a newline, leaving the
+// current line address as it is
+func (f *FileBuffer) WriteTo(w io.Writer) (n int64, e error) {
+	bw := bufio.NewWriter(w)
+	f.file.eachPiece(0, f.Len(), func(start, k int) {
+		for id := start; id < start+k && e == nil; id++ {
+			var m int
+			m, e = bw.WriteString(f.line(id))
+			n += int64(m)
+			if e == nil {
+				if e = bw.WriteByte('\n'); e == nil {
+					n++
+				}
+			}
+		}
+	})
+	if e == nil {
+		e = bw.Flush()
+	}
+	return
+}
+
+// Start a transaction
+func (f *FileBuffer) Start() {
+	f.mod = false
+	f.tmpAddr = f.addr
+}
+
+// End a transaction, if the file changed it is added to the history as made by cmd. The
+// changes that were undone can't be redone anymore.
+func (f *FileBuffer) End(cmd string) {
+	if !f.mod {
+		return
+	}
+	if f.saved > f.pos {
+		f.saved = -1
+	}
+	f.history = append(f.history[:f.pos+1], change{cmd: cmd, file: f.file, before: f.tmpAddr, after: f.addr})
+	f.pos++
+	f.undone = false
+	if n := len(f.history) - 1 - historyDepth; historyDepth > 0 && n > 0 {
+		f.history = slices.Delete(f.history, 0, n)
+		f.pos -= n
+		f.saved = max(f.saved-n, -1)
+	}
+}
+
+// Undo restores the file as it was before the last change that wasn't undone, reports false if
+// there is none
+func (f *FileBuffer) Undo() bool {
+	if f.pos == 0 {
+		return false
+	}
+	f.restore(f.pos-1, f.history[f.pos].before)
+	f.undone = true
+	return true
+}
+
+// Redo makes again the last change undone, reports false if there is none
+func (f *FileBuffer) Redo() bool {
+	if f.pos >= len(f.history)-1 {
+		return false
+	}
+	f.restore(f.pos+1, f.history[f.pos+1].after)
+	f.undone = false
+	return true
+}
+
+// Rewind undoes the last change, which is the last undo if the previous change was one, as
+// POSIX u does
+func (f *FileBuffer) Rewind() {
+	if f.undone {
+		f.Redo()
+	} else {
+		f.Undo()
+	}
+}
+
+// restore makes the state pos of the history the current one
+func (f *FileBuffer) restore(pos, addr int) {
+	f.pos = pos
+	f.file = f.history[pos].file
+	f.addr = addr
+	f.dirty = pos != f.saved
+}
+
+// History returns the commands of the changes kept for undo, the oldest first, and how many
+// of them are in effect, those after were undone
+func (f *FileBuffer) History() (cmds []string, current int) {
+	for _, c := range f.history[1:] {
+		cmds = append(cmds, c.cmd)
+	}
+	return cmds, f.pos
+}
+
+// String dumps the buffer for debugging, as %v would if file was the slice of its lines
+func (f *FileBuffer) String() string {
+	history := make([]any, len(f.history))
+	for i, c := range f.history {
+		history[i] = struct {
+			cmd           string
+			file          []int
+			before, after int
+		}{c.cmd, c.file.ids(), c.before, c.after}
+	}
+	return fmt.Sprintf("&{%v %v %v %v %v %v %v %v %v %v %v %v}", f.cbuf.text(), f.buffer, f.file.ids(), history, f.pos, f.saved, f.undone, f.dirty, f.mod, f.addr, f.tmpAddr, f.marks)
+}
+
+// Touch is the correct way (even internally) to set the dirty & modified bits
+func (f *FileBuffer) Touch() {
+	f.dirty = true
+	f.mod = true
+}
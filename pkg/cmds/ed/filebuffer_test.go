@@ -2,7 +2,7 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-package main
+package ed
 
 import (
 	"bytes"
@@ -24,13 +24,13 @@ var testTableInt = []struct {
 }{
 	{
 		name:   "Test Len: Len()=0",
-		in:     &FileBuffer{file: []int{}},
+		in:     &FileBuffer{},
 		exp:    0,
 		method: (*FileBuffer).Len,
 	},
 	{
 		name:   "Test Len: Len()=4",
-		in:     &FileBuffer{file: []int{0, 1, 2, 3}},
+		in:     &FileBuffer{file: ropeOf(0, 1, 2, 3)},
 		exp:    4,
 		method: (*FileBuffer).Len,
 	},
@@ -48,7 +48,7 @@ var testTableInt = []struct {
 	},
 	{
 		name:   "Test Size: Size()=4",
-		in:     &FileBuffer{file: []int{0, 1, 2, 3}, buffer: []string{"0", "1", "2", "3"}},
+		in:     &FileBuffer{file: ropeOf(0, 1, 2, 3), buffer: []string{"0", "1", "2", "3"}},
 		exp:    4,
 		method: (*FileBuffer).Size,
 	},
@@ -112,26 +112,44 @@ var testTable = []struct {
 	},
 	{
 		name:   "Test Start",
-		in:     &FileBuffer{mod: true, tmpFile: []int{}, file: []int{0, 1, 2, 3}, tmpAddr: 0, addr: 10, tmpDirty: false, dirty: true},
-		exp:    &FileBuffer{mod: false, tmpFile: []int{0, 1, 2, 3}, file: []int{0, 1, 2, 3}, tmpAddr: 10, addr: 10, tmpDirty: true, dirty: true},
+		in:     &FileBuffer{mod: true, file: ropeOf(0, 1, 2, 3), tmpAddr: 0, addr: 10, dirty: true},
+		exp:    &FileBuffer{mod: false, file: ropeOf(0, 1, 2, 3), tmpAddr: 10, addr: 10, dirty: true},
 		method: (*FileBuffer).Start,
 	},
 	{
 		name:   "Test End",
-		in:     &FileBuffer{mod: true, lastFile: []int{}, tmpFile: []int{0, 1, 2, 3}, lastAddr: 0, tmpAddr: 10, lastDirty: false, tmpDirty: true},
-		exp:    &FileBuffer{mod: true, lastFile: []int{0, 1, 2, 3}, tmpFile: []int{0, 1, 2, 3}, lastAddr: 10, tmpAddr: 10, lastDirty: true, tmpDirty: true},
-		method: (*FileBuffer).End,
+		in:     &FileBuffer{mod: true, file: ropeOf(0, 1), history: []change{{file: ropeOf(0, 1, 2, 3)}}, tmpAddr: 3, addr: 1},
+		exp:    &FileBuffer{mod: true, file: ropeOf(0, 1), history: []change{{file: ropeOf(0, 1, 2, 3)}, {cmd: "3,4d", file: ropeOf(0, 1), before: 3, after: 1}}, pos: 1, tmpAddr: 3, addr: 1},
+		method: func(f *FileBuffer) { f.End("3,4d") },
+	},
+	{
+		name:   "Test End: no change",
+		in:     &FileBuffer{mod: false, file: ropeOf(0, 1), history: []change{{file: ropeOf(0, 1)}}, tmpAddr: 3, addr: 1},
+		exp:    &FileBuffer{mod: false, file: ropeOf(0, 1), history: []change{{file: ropeOf(0, 1)}}, tmpAddr: 3, addr: 1},
+		method: func(f *FileBuffer) { f.End("1p") },
 	},
 	{
 		name:   "Test Rewind",
-		in:     &FileBuffer{mod: false, file: []int{}, lastFile: []int{0, 1, 2, 3}, addr: 0, lastAddr: 10, dirty: true, lastDirty: false},
-		exp:    &FileBuffer{mod: true, file: []int{0, 1, 2, 3}, lastFile: []int{0, 1, 2, 3}, addr: 10, lastAddr: 10, dirty: false, lastDirty: false},
+		in:     &FileBuffer{file: ropeOf(0, 1), history: []change{{file: ropeOf(0, 1, 2, 3)}, {cmd: "3,4d", file: ropeOf(0, 1), before: 3, after: 1}}, pos: 1, addr: 1, dirty: true},
+		exp:    &FileBuffer{file: ropeOf(0, 1, 2, 3), history: []change{{file: ropeOf(0, 1, 2, 3)}, {cmd: "3,4d", file: ropeOf(0, 1), before: 3, after: 1}}, pos: 0, addr: 3, dirty: false, undone: true},
+		method: (*FileBuffer).Rewind,
+	},
+	{
+		name:   "Test Rewind: undo of the undo",
+		in:     &FileBuffer{file: ropeOf(0, 1, 2, 3), history: []change{{file: ropeOf(0, 1, 2, 3)}, {cmd: "3,4d", file: ropeOf(0, 1), before: 3, after: 1}}, pos: 0, addr: 3, undone: true},
+		exp:    &FileBuffer{file: ropeOf(0, 1), history: []change{{file: ropeOf(0, 1, 2, 3)}, {cmd: "3,4d", file: ropeOf(0, 1), before: 3, after: 1}}, pos: 1, addr: 1, dirty: true, undone: false},
+		method: (*FileBuffer).Rewind,
+	},
+	{
+		name:   "Test Rewind: nothing to undo",
+		in:     &FileBuffer{file: ropeOf(0, 1), history: []change{{file: ropeOf(0, 1)}}, addr: 1},
+		exp:    &FileBuffer{file: ropeOf(0, 1), history: []change{{file: ropeOf(0, 1)}}, addr: 1},
 		method: (*FileBuffer).Rewind,
 	},
 	{
 		name:   "Test Clean",
-		in:     &FileBuffer{dirty: true, lastDirty: true, lastFile: []int{0, 1, 2, 3}, lastAddr: 10},
-		exp:    &FileBuffer{dirty: false, lastDirty: false, lastFile: []int{}, lastAddr: 0},
+		in:     &FileBuffer{dirty: true, pos: 2, saved: 0},
+		exp:    &FileBuffer{dirty: false, pos: 2, saved: 2},
 		method: (*FileBuffer).Clean,
 	},
 }
@@ -147,6 +165,54 @@ func TestBasicFuncs(t *testing.T) {
 	}
 }
 
+func TestHistory(t *testing.T) {
+	defer func(depth int) { historyDepth = depth }(historyDepth)
+	historyDepth = 2
+	f := NewFileBuffer([]string{"0", "1", "2", "3"})
+	for _, cmd := range []string{"1d", "1d", "1d"} {
+		f.Start()
+		if err := f.Delete([2]int{0, 0}); err != nil {
+			t.Fatal(err)
+		}
+		f.End(cmd)
+	}
+	f.Clean()
+
+	// The first change was dropped, the depth being 2
+	for n := 0; n < 2; n++ {
+		if !f.Undo() {
+			t.Fatalf("Undo() = false after %d undos", n)
+		}
+	}
+	if f.Undo() {
+		t.Error("Undo() = true past the depth of the history")
+	}
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(f.file.ids(), want) || !f.Dirty() {
+		t.Errorf("after the undos, file = %v, dirty = %v, want %v, true", f.file.ids(), f.Dirty(), want)
+	}
+	if cmds, current := f.History(); !reflect.DeepEqual(cmds, []string{"1d", "1d"}) || current != 0 {
+		t.Errorf("History() = %q, %d", cmds, current)
+	}
+
+	for f.Redo() {
+	}
+	if want := []int{3}; !reflect.DeepEqual(f.file.ids(), want) || f.Dirty() {
+		t.Errorf("after the redos, file = %v, dirty = %v, want %v, false", f.file.ids(), f.Dirty(), want)
+	}
+
+	// A new change drops those undone
+	f.Undo()
+	f.Start()
+	f.Insert(0, []string{"new"})
+	f.End("0a")
+	if f.Redo() {
+		t.Error("Redo() = true after a new change")
+	}
+	if cmds, current := f.History(); !reflect.DeepEqual(cmds, []string{"1d", "0a"}) || current != 2 {
+		t.Errorf("History() = %q, %d", cmds, current)
+	}
+}
+
 // Test NewFileBuffer
 var testTableNewFileBuffer = []struct {
 	name string
@@ -156,7 +222,7 @@ var testTableNewFileBuffer = []struct {
 	{
 		name: "Test NewFileBuffer",
 		in:   []string{"0", "1", "2", "3"},
-		exp:  &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: []int{0, 1, 2, 3}, dirty: false, mod: false, addr: 0, marks: make(map[byte]int)},
+		exp:  &FileBuffer{buffer: []string{"0", "1", "2", "3"}, bufSize: 4, file: ropeOf(0, 1, 2, 3), history: []change{{file: ropeOf(0, 1, 2, 3)}}, dirty: false, mod: false, addr: 0, marks: make(map[byte]int)},
 	},
 }
 
@@ -186,7 +252,7 @@ var testTableOOB = []struct {
 	},
 	{
 		name:     "Test OOB: return false",
-		in:       &FileBuffer{file: []int{0, 1, 2, 3}},
+		in:       &FileBuffer{file: ropeOf(0, 1, 2, 3)},
 		exp:      false,
 		methodin: 2,
 	},
@@ -213,7 +279,7 @@ var testTableGetMust = []struct {
 }{
 	{
 		name:      "Test GetMust",
-		in:        &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: []int{0, 1, 2, 3}},
+		in:        &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: ropeOf(0, 1, 2, 3)},
 		exp:       "3",
 		methodin1: 3,
 		methodin2: true,
@@ -241,14 +307,14 @@ var testTableGet = []struct {
 }{
 	{
 		name:     "Test Get: with OOB error",
-		in:       &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: []int{0, 1, 2, 3}},
+		in:       &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: ropeOf(0, 1, 2, 3)},
 		exp1:     []string{},
 		exp2:     ErrOOB,
 		methodin: [2]int{0, 4},
 	},
 	{
 		name:     "Test Get: without OOB error",
-		in:       &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: []int{0, 1, 2, 3}},
+		in:       &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: ropeOf(0, 1, 2, 3)},
 		exp1:     []string{"0", "1"},
 		exp2:     fmt.Errorf(""),
 		methodin: [2]int{0, 1},
@@ -285,13 +351,13 @@ var testTableCopy = []struct {
 }{
 	{
 		name:     "Test Copy: with OOB error",
-		in:       &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: []int{0, 1, 2, 3}},
+		in:       &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: ropeOf(0, 1, 2, 3)},
 		exp:      ErrOOB,
 		methodin: [2]int{0, 4},
 	},
 	{
 		name:     "Test Copy: without OOB error",
-		in:       &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: []int{0, 1, 2, 3}},
+		in:       &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: ropeOf(0, 1, 2, 3)},
 		exp:      nil,
 		methodin: [2]int{0, 1},
 	},
@@ -320,19 +386,23 @@ var testTablePasteInsert = []struct {
 }{
 	{
 		name:     "Test Paste & Insert: with OOB error",
-		in:       &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: []int{0, 1, 2, 3}},
+		in:       &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: ropeOf(0, 1, 2, 3)},
 		exp:      ErrOOB,
 		methodin: 5,
 	},
 	{
 		name:     "Test Paste & Insert: without OOB error and nlines == 0",
-		in:       &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: []int{0, 1, 2, 3}},
+		in:       &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: ropeOf(0, 1, 2, 3)},
 		exp:      nil,
 		methodin: 1,
 	},
 	{
-		name:     "Test Paste & Insert: without OOB error and nlines != 0",
-		in:       &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: []int{0, 1, 2, 3}, cbuf: []string{"0", "1", "2", "3"}},
+		name: "Test Paste & Insert: without OOB error and nlines != 0",
+		in: func() *FileBuffer {
+			f := &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: ropeOf(0, 1, 2, 3)}
+			f.cbuf = cut{f, ropeOf(0, 1, 2, 3)}
+			return f
+		}(),
 		exp:      nil,
 		methodin: 1,
 	},
@@ -360,13 +430,13 @@ var testTableDelete = []struct {
 }{
 	{
 		name:     "Test Delete: with OOB error",
-		in:       &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: []int{0, 1, 2, 3}},
+		in:       &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: ropeOf(0, 1, 2, 3)},
 		exp:      ErrOOB,
 		methodin: [2]int{0, 4},
 	},
 	{
 		name:     "Test Delete: without OOB error and addr OOB edge case",
-		in:       &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: []int{0, 1, 2, 3}},
+		in:       &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: ropeOf(0, 1, 2, 3)},
 		exp:      nil,
 		methodin: [2]int{0, 3},
 	},
@@ -394,13 +464,13 @@ var testTableSetAddr = []struct {
 }{
 	{
 		name:     "Test SetAddr: with OOB error",
-		in:       &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: []int{0, 1, 2, 3}},
+		in:       &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: ropeOf(0, 1, 2, 3)},
 		exp:      ErrOOB,
 		methodin: 4,
 	},
 	{
 		name:     "Test SetAddr: without OOB error and addr OOB edge case",
-		in:       &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: []int{0, 1, 2, 3}},
+		in:       &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: ropeOf(0, 1, 2, 3)},
 		exp:      nil,
 		methodin: 0,
 	},
@@ -434,7 +504,7 @@ var testTableSetMark = []struct {
 }{
 	{
 		name:      "Test SetMark: with OOB error",
-		in:        &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: []int{0, 1, 2, 3}, marks: make(map[byte]int)},
+		in:        &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: ropeOf(0, 1, 2, 3), marks: make(map[byte]int)},
 		exp:       0,
 		err:       ErrOOB,
 		methodin1: 0,
@@ -442,7 +512,7 @@ var testTableSetMark = []struct {
 	},
 	{
 		name:      "Test SetMark: without OOB error",
-		in:        &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: []int{0, 1, 2, 3}, marks: make(map[byte]int)},
+		in:        &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: ropeOf(0, 1, 2, 3), marks: make(map[byte]int)},
 		exp:       3,
 		err:       ErrOOB,
 		methodin1: 0,
@@ -477,14 +547,14 @@ var testTableGetMark = []struct {
 }{
 	{
 		name:     "Test GetMark: with error",
-		in:       &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: []int{0, 1, 2, 3}, marks: make(map[byte]int)},
+		in:       &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: ropeOf(0, 1, 2, 3), marks: make(map[byte]int)},
 		exp:      0,
 		err:      -1,
 		methodin: 0x00,
 	},
 	{
 		name:     "Test GetMark: without error",
-		in:       &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: []int{0, 1, 2, 3}, marks: make(map[byte]int)},
+		in:       &FileBuffer{buffer: []string{"0", "1", "2", "3"}, file: ropeOf(0, 1, 2, 3), marks: make(map[byte]int)},
 		exp:      1,
 		err:      0,
 		methodin: 0x01,
@@ -606,7 +676,7 @@ var testTableFileToBuffer = []struct {
 	{
 		name:     "Test FileToBuffer: file exists",
 		in:       &FileBuffer{},
-		exp:      &FileBuffer{mod: true, file: []int{0}},
+		exp:      &FileBuffer{mod: true, file: ropeOf(0)},
 		err:      fmt.Errorf(""),
 		methodin: CreateFile("filetobuffer"),
 	},
@@ -634,3 +704,63 @@ func TestFileToBuffer(t *testing.T) {
 		})
 	}
 }
+
+// Test a buffer whose lines outgrow memory, and are moved to a scratch file
+func TestScratch(t *testing.T) {
+	defer func(size, batch int) { scratchSize, readBatch = size, batch }(scratchSize, readBatch)
+	scratchSize, readBatch = 100, 7
+	var in bytes.Buffer
+	var want []string
+	for i := 0; i < 1000; i++ {
+		want = append(want, fmt.Sprintf("line %d", i))
+		fmt.Fprintln(&in, want[i])
+	}
+	f := NewFileBuffer([]string{"first", "last"})
+	f.SetMark('a', 1)
+	f.Start()
+	if err := f.Read(1, &in); err != nil {
+		t.Fatal(err)
+	}
+	f.End("1r")
+	if f.scratch == nil || f.buffer != nil {
+		t.Fatalf("the lines weren't moved to a scratch file")
+	}
+	want = append(append([]string{"first"}, want...), "last")
+	if got, err := f.Get([2]int{0, f.Len() - 1}); err != nil || !reflect.DeepEqual(got, want) {
+		t.Fatalf("Get() = %q, %v, want %q", got, err, want)
+	}
+	size := 0
+	for _, l := range want {
+		size += len(l)
+	}
+	if got := f.Size(); got != size {
+		t.Errorf("Size() = %d, want %d", got, size)
+	}
+
+	// Lines added afterwards go to the scratch file too, and marks follow their lines
+	f.Start()
+	if err := f.Delete([2]int{1, 500}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Insert(1, []string{"new"}); err != nil {
+		t.Fatal(err)
+	}
+	f.End("2,501c")
+	if f.cbuf.Len() != 500 {
+		t.Errorf("the cut buffer has %d lines, want the 500 deleted", f.cbuf.Len())
+	}
+	deleted := want[1:501:501]
+	want = append(append(want[:1:1], "new"), want[501:]...)
+	if got, _ := f.Get([2]int{0, f.Len() - 1}); !reflect.DeepEqual(got, want) {
+		t.Errorf("after 2,501c, Get() = %q, want %q", got, want)
+	}
+	if l, err := f.GetMark('a'); err != nil || l != len(want)-1 {
+		t.Errorf("GetMark('a') = %d, %v, want %d, nil", l, err, len(want)-1)
+	}
+	if got := f.cbuf.text(); !reflect.DeepEqual(got, deleted) {
+		t.Errorf("the cut buffer is %q, want %q", got, deleted)
+	}
+	if !f.Undo() || f.Len() != 1002 || f.GetMust(500, false) != "line 499" {
+		t.Errorf("after the undo, Len() = %d, line 501 = %q, want %d, %q", f.Len(), f.GetMust(500, false), 1002, "line 499")
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2024, xplshn, u-root and contributors  [3BSD]
+// For more details refer to https://github.com/xplshn/a-utils
+
+// hangup.go - saving the buffer when ed is hung up, and to a swap file while it is edited
+package ed
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/xplshn/a-utils/pkg/ccmd"
+)
+
+// When ed is hung up (SIGHUP) or terminated (SIGTERM), it saves the buffer to ed.hup if it has
+// changes that weren't written, as POSIX says, and exits. With --swap, the buffer is also saved
+// every so often to a swap file next to the file, .NAME.swp, which outlives a crash of ed or of
+// the system. The swap file is removed once the buffer is written or ed quits; when ed starts
+// and finds one newer than the file, -R recovers it, and ed offers to if it reads a terminal.
+
+// hupName is the file the buffer is saved to on a hangup, in the current directory or else in
+// $HOME
+const hupName = "ed.hup"
+
+// hangup is the signal that hung up ed, nil until one is received. The running command then
+// stops as if interrupted, see checkInterrupt, and ed saves the buffer and exits.
+var hangup atomic.Pointer[ccmd.SignalError]
+
+// swapEvery is how often the buffer is saved to the swap file while it has changes that
+// weren't written, 0 for never (--swap)
+var swapEvery time.Duration
+
+// A swapFile is the swap file ed wrote, or recovered, for a buffer
+type swapFile struct {
+	name string    // "" if there is none
+	file *rope     // the lines it holds
+	when time.Time // when it was written
+}
+
+// the swap file of the current buffer
+var swap swapFile
+
+// writeBuffer writes the buffer to the file name, readable by its owner only
+func writeBuffer(name string) error {
+	f, e := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if e != nil {
+		return e
+	}
+	if _, e = buffer.WriteTo(f); e != nil {
+		f.Close()
+		return e
+	}
+	return f.Close()
+}
+
+// saveHangup writes the buffers that have changes that weren't written to ed.hup, the first
+// one, and ed.hup.N for buffer N of the multi-buffer extension
+func saveHangup() {
+	eachBuffer(func(n int) {
+		if !buffer.Dirty() {
+			return
+		}
+		name := hupName
+		if n > 1 {
+			name = fmt.Sprintf("%s.%d", hupName, n)
+		}
+		names := []string{name}
+		if home := os.Getenv("HOME"); home != "" && !state.restricted {
+			names = append(names, filepath.Join(home, name))
+		}
+		var e error
+		for _, name := range names {
+			if e = writeBuffer(name); e == nil {
+				return
+			}
+		}
+		ccmd.Warnf("saving the buffer: %v", e)
+	})
+}
+
+// swapName returns the name of the swap file of the file name: .NAME.swp, next to it
+func swapName(name string) string {
+	dir, base := filepath.Split(name)
+	return filepath.Join(dir, "."+base+".swp")
+}
+
+// removeSwap removes the swap file, if ed wrote or recovered one
+func removeSwap() {
+	if swap.name != "" {
+		os.Remove(swap.name)
+		swap.name, swap.file = "", nil
+	}
+}
+
+// updateSwap saves the buffer to the swap file if it changed since the swap file was written,
+// at most every swapEvery, and removes the swap file once the buffer is written. The swap
+// file is replaced at once, so that a crash while it is written leaves the previous one.
+func updateSwap() {
+	if !buffer.Dirty() || swap.name != swapName(state.fileName) {
+		removeSwap()
+	}
+	if swapEvery <= 0 || !buffer.Dirty() || state.fileName == "" ||
+		buffer.file == swap.file || time.Since(swap.when) < swapEvery {
+		return
+	}
+	name := swapName(state.fileName)
+	swap.when = time.Now() // a swap file that can't be written is retried later only
+	dir, base := filepath.Split(name)
+	f, e := os.CreateTemp(dir, base+"-")
+	if e == nil {
+		_, e = buffer.WriteTo(f)
+		if e2 := f.Close(); e == nil {
+			e = e2
+		}
+		if e == nil {
+			e = os.Rename(f.Name(), name)
+		}
+		if e != nil {
+			os.Remove(f.Name())
+		}
+	}
+	if e != nil {
+		ccmd.Warnf("writing the swap file: %v", e)
+		return
+	}
+	swap.name, swap.file = name, buffer.file
+}
+
+// recoverSwap replaces the buffer with the swap file of the file, if it is newer than the
+// file, when -R is given, or when the user agrees to if ask is set. Otherwise, ed warns of it.
+func recoverSwap(in lineReader, out io.Writer, ask bool) error {
+	name := swapName(state.fileName)
+	si, e := os.Stat(name)
+	if e != nil {
+		return nil
+	}
+	if fi, e := os.Stat(state.fileName); e == nil && !si.ModTime().After(fi.ModTime()) {
+		return nil
+	}
+	switch {
+	case state.recover:
+	case ask:
+		fmt.Fprintf(out, "%s has changes to %s that weren't written, recover them? ", name, state.fileName)
+		answer, e := in.ReadLine()
+		if e != nil {
+			fmt.Fprintln(out)
+			return nil
+		}
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			return nil
+		}
+	default:
+		ccmd.Warnf("%s has changes to %s that weren't written, -R recovers them", name, state.fileName)
+		return nil
+	}
+	b, e := FileToBuffer(name)
+	if e != nil {
+		return e
+	}
+	// the buffer differs from the file, up to its first state in the history
+	b.dirty, b.saved = true, -1
+	buffer = b
+	swap.name, swap.file, swap.when = name, buffer.file, si.ModTime()
+	return nil
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2024, xplshn, u-root and contributors  [3BSD]
+// For more details refer to https://github.com/xplshn/a-utils
+
+package ed
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xplshn/a-utils/pkg/ccmd"
+)
+
+// readFile returns the content of the file name, "" if it can't be read
+func readFile(name string) string {
+	b, _ := os.ReadFile(name)
+	return string(b)
+}
+
+// Test that a hangup stops the running command, and that the buffer is saved to ed.hup, or
+// $HOME/ed.hup when the current directory can't have it
+func TestHangup(t *testing.T) {
+	saved := buffer
+	defer func() { buffer = saved }()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	dir, home := t.TempDir(), t.TempDir()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+
+	resetBuffers()
+	buffer = NewFileBuffer([]string{"one", "two"})
+	hangup.Store(&ccmd.SignalError{Signal: os.Interrupt})
+	defer hangup.Store(nil)
+	var out bytes.Buffer
+	var sig *ccmd.SignalError
+	if err := execute(",p", nil, &out); !errors.As(err, &sig) || out.Len() != 0 {
+		t.Errorf("execute(\",p\") = %v, printing %q, want the *ccmd.SignalError", err, out.String())
+	}
+
+	saveHangup()
+	if _, err := os.Stat(hupName); err == nil {
+		t.Errorf("%s written for a buffer without changes", hupName)
+	}
+	buffer.Touch()
+	saveHangup()
+	if got := readFile(hupName); got != "one\ntwo\n" {
+		t.Errorf("%s = %q, want %q", hupName, got, "one\ntwo\n")
+	}
+
+	if err := os.Remove(hupName); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(hupName, 0o777); err != nil {
+		t.Fatal(err)
+	}
+	saveHangup()
+	if got := readFile(filepath.Join(home, hupName)); got != "one\ntwo\n" {
+		t.Errorf("$HOME/%s = %q, want %q", hupName, got, "one\ntwo\n")
+	}
+
+	// a buffer emptied is saved too, to an empty ed.hup
+	if err := buffer.Delete([2]int{0, buffer.Len() - 1}); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(filepath.Join(home, hupName))
+	saveHangup()
+	if fi, err := os.Stat(filepath.Join(home, hupName)); err != nil || fi.Size() != 0 {
+		t.Errorf("$HOME/%s isn't written empty for an empty buffer: %v", hupName, err)
+	}
+
+	// the other buffers go to ed.hup.N
+	buffers = append(buffers, openBuffer{buf: NewFileBuffer([]string{"three"})})
+	buffers[1].buf.Touch()
+	saveHangup()
+	if got := readFile(hupName + ".2"); got != "three\n" {
+		t.Errorf("%s.2 = %q, want %q", hupName, got, "three\n")
+	}
+	resetBuffers()
+}
+
+// Test that the swap file follows the changes of the buffer, and is recovered with -R only
+func TestSwap(t *testing.T) {
+	saved, savedName := buffer, state.fileName
+	defer func() { buffer, state.fileName, swapEvery, state.recover = saved, savedName, 0, false }()
+	file := filepath.Join(t.TempDir(), "file")
+	swapFile := swapName(file)
+	if want := filepath.Join(filepath.Dir(file), ".file.swp"); swapFile != want {
+		t.Errorf("swapName(%q) = %q, want %q", file, swapFile, want)
+	}
+	if err := os.WriteFile(file, []byte("a\nb\n"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+
+	var err error
+	if buffer, err = FileToBuffer(file); err != nil {
+		t.Fatal(err)
+	}
+	state.fileName, swapEvery = file, time.Nanosecond
+	swap.name, swap.file, swap.when = "", nil, time.Time{}
+	updateSwap()
+	if _, err := os.Stat(swapFile); err == nil {
+		t.Errorf("swap file written for a buffer without changes")
+	}
+	buffer.Insert(2, []string{"c"})
+	updateSwap()
+	if got := readFile(swapFile); got != "a\nb\nc\n" {
+		t.Errorf("the swap file is %q, want %q", got, "a\nb\nc\n")
+	}
+	buffer.Clean()
+	updateSwap()
+	if _, err := os.Stat(swapFile); err == nil {
+		t.Errorf("swap file kept once the buffer is written")
+	}
+
+	// a swap file newer than the file is recovered with -R only
+	if err := os.WriteFile(swapFile, []byte("b\nc\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(swapFile, later, later); err != nil {
+		t.Fatal(err)
+	}
+	swapEvery = 0
+	for _, r := range []bool{false, true} {
+		state.recover = r
+		var out bytes.Buffer
+		if err := runEd(strings.NewReader("1p\n2p\n"), &out, true, "", file); err != nil {
+			t.Fatal(err)
+		}
+		want := "a\nb\n"
+		if r {
+			want = "b\nc\n"
+		}
+		if out.String() != want {
+			t.Errorf("with -R %v, the buffer is %q, want %q", r, out.String(), want)
+		}
+		if _, err := os.Stat(swapFile); (err == nil) == r {
+			t.Errorf("with -R %v, the swap file is kept: %v, want %v", r, err == nil, !r)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2024, xplshn, u-root and contributors  [3BSD]
+// For more details refer to https://github.com/xplshn/a-utils
+
+// highlight.go - syntax highlighting of the lines of the buffer
+package ed
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/xplshn/a-utils/pkg/ccmd"
+)
+
+// Lines are highlighted with the tokens of the whole buffer, rather than each on its own, so
+// that multi-line comments and strings keep their state. The tokens are cached by line, along
+// with the sequence of lines they were made from: when the buffer changes, those of the lines
+// before the first one that changed are kept. Lexers can't resume from the middle of a text,
+// so the other lines are lexed again from the start of the buffer, and only as far as the
+// lines printed.
+
+// analyseSize is the size of the start of the buffer the language is guessed from, when the
+// file name doesn't tell it
+const analyseSize = 64 << 10
+
+// A highlightCache holds the tokens of the first lines of a buffer
+type highlightCache struct {
+	buf      *FileBuffer
+	file     *rope  // the sequence of lines the tokens were made from
+	fileName string // the file name the lexer was chosen for
+	lexer    chroma.Lexer
+	lines    [][]chroma.Token // tokens of the first lines of file
+}
+
+var highlights highlightCache
+
+// tokens returns the tokens of the line l of the buffer, lexing it if it isn't cached
+func (h *highlightCache) tokens(l int) ([]chroma.Token, error) {
+	if h.buf != buffer || h.fileName != state.fileName {
+		*h = highlightCache{buf: buffer, file: buffer.file, fileName: state.fileName}
+	}
+	if h.file != buffer.file {
+		h.lines = h.lines[:min(len(h.lines), commonPrefix(h.file, buffer.file))]
+		h.file = buffer.file
+	}
+	if l < len(h.lines) {
+		return h.lines[l], nil
+	}
+
+	// Lex at least a line past l, and twice as far as before, so that printing the buffer
+	// through lexes it a few times only. The last line is left out of the cache unless it
+	// ends the buffer, its tokens depending on those of the lines after it.
+	end := min(max(l+2, 2*len(h.lines)), buffer.Len())
+	var text strings.Builder
+	buffer.file.eachPiece(0, end, func(start, n int) {
+		for id := start; id < start+n; id++ {
+			text.WriteString(buffer.line(id))
+			text.WriteByte('\n')
+		}
+	})
+	if h.lexer == nil {
+		h.lexer = chooseLexer(text.String())
+	}
+	iterator, e := h.lexer.Tokenise(nil, text.String())
+	if e != nil {
+		return nil, e
+	}
+	lines := make([][]chroma.Token, 0, end)
+	var line []chroma.Token
+	for t := iterator(); t != chroma.EOF; t = iterator() {
+		for {
+			value, rest, newline := strings.Cut(t.Value, "\n")
+			if value != "" {
+				line = append(line, chroma.Token{Type: t.Type, Value: value})
+			}
+			if !newline {
+				break
+			}
+			lines, line = append(lines, line), nil
+			t.Value = rest
+		}
+	}
+	for len(lines) < end {
+		lines = append(lines, nil)
+	}
+	if end < buffer.Len() {
+		end--
+	}
+	h.lines = lines[:end]
+	return lines[l], nil
+}
+
+// chooseLexer returns the lexer of the language of the file, named by its name or guessed
+// from text, the start of the buffer
+func chooseLexer(text string) chroma.Lexer {
+	lexer := lexers.Match(state.fileName)
+	if lexer == nil {
+		// If filename detection fails, detect the language from the file's contents
+		lexer = lexers.Analyse(strings.Map(func(r rune) rune {
+			if unicode.IsPrint(r) || r == '\n' {
+				return r
+			}
+			return -1
+		}, text[:min(len(text), analyseSize)]))
+	}
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	return chroma.Coalesce(lexer)
+}
+
+// highlightFormat returns the style and the formatter of the syntax highlighting
+func highlightFormat() (*chroma.Style, chroma.Formatter) {
+	var style *chroma.Style
+	if state.syntaxHighlightingStyleName != "" {
+		style = styles.Get(state.syntaxHighlightingStyleName)
+	} else {
+		style = styles.Get(os.Getenv("A_SYHX_COLOR_SCHEME"))
+		if style == nil {
+			style = styles.Fallback
+		}
+	}
+
+	formatterName := os.Getenv("A_SYHX_FORMATTER")
+	if formatterName == "" {
+		// Highlighting was asked for explicitly, only the amount of colors is detected
+		formatterName = ccmd.NewColor(os.Stdout, ccmd.ColorAlways).ChromaFormatter()
+	}
+	formatter := formatters.Get(formatterName)
+	if formatter == nil {
+		formatter = formatters.Fallback
+	}
+	return style, formatter
+}
+
+// printHighlighted writes the line l of the buffer highlighted, and a newline. The line is
+// written by list if it isn't nil, as l does.
+func printHighlighted(out io.Writer, l int, list *lister) error {
+	tokens, e := highlights.tokens(l)
+	if e != nil {
+		return e
+	}
+	if list != nil {
+		listed := make([]chroma.Token, 0, len(tokens)+1)
+		for _, t := range tokens {
+			listed = append(listed, chroma.Token{Type: t.Type, Value: list.list(t.Value)})
+		}
+		tokens = append(listed, chroma.Token{Type: chroma.Text, Value: list.end()})
+	}
+	style, formatter := highlightFormat()
+	var buf bytes.Buffer
+	if e = formatter.Format(&buf, style, chroma.Literator(tokens...)); e != nil {
+		return e
+	}
+	buf.WriteByte('\n')
+	_, e = buf.WriteTo(out)
+	return e
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2024, xplshn, u-root and contributors  [3BSD]
+// For more details refer to https://github.com/xplshn/a-utils
+
+package ed
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/alecthomas/chroma/v2"
+)
+
+// lineTypes returns the types of the tokens of line l, lexing it if needed
+func lineTypes(t *testing.T, l int) (types []chroma.TokenType) {
+	t.Helper()
+	tokens, err := highlights.tokens(l)
+	if err != nil {
+		t.Fatalf("tokens(%d) = %v", l, err)
+	}
+	for _, tok := range tokens {
+		types = append(types, tok.Type)
+	}
+	return
+}
+
+// Test that the lines of comments spanning several lines are highlighted as comments, and that
+// the tokens cached are those of the lines before the first that changed
+func TestHighlightCache(t *testing.T) {
+	defer func(b *FileBuffer, name string) { buffer, state.fileName = b, name }(buffer, state.fileName)
+	lines := []string{"package main", "/* a comment", "on two lines */", "var x = 1"}
+	for i := 0; i < 100; i++ {
+		lines = append(lines, fmt.Sprintf("var x%d = %d", i, i))
+	}
+	buffer, state.fileName = NewFileBuffer(lines), "main.go"
+	highlights = highlightCache{}
+
+	if got := lineTypes(t, 2); len(got) != 1 || got[0] != chroma.CommentMultiline {
+		t.Errorf("the tokens of the end of the comment are %v, want a %v", got, chroma.CommentMultiline)
+	}
+	if len(highlights.lines) >= buffer.Len() {
+		t.Errorf("%d lines lexed to highlight the third, want fewer than %d", len(highlights.lines), buffer.Len())
+	}
+
+	// Changing the last line keeps the others, and is lexed
+	buffer.Delete([2]int{103, 103})
+	buffer.Insert(103, []string{"// a comment"})
+	if got := lineTypes(t, 103); len(got) != 1 || got[0] != chroma.CommentSingle {
+		t.Errorf("the tokens of the new last line are %v, want a %v", got, chroma.CommentSingle)
+	}
+	cached := len(highlights.lines)
+	buffer.Delete([2]int{103, 103})
+	lineTypes(t, 0)
+	if len(highlights.lines) != cached-1 {
+		t.Errorf("%d lines cached after deleting the last one, want %d", len(highlights.lines), cached-1)
+	}
+
+	// Ending the comment on its first line makes the second one code again
+	buffer.Delete([2]int{1, 1})
+	buffer.Insert(1, []string{"/* a comment */"})
+	if got := lineTypes(t, 2); len(got) == 0 || got[0] == chroma.CommentMultiline {
+		t.Errorf("the tokens of the line after the comment are %v, want code", got)
+	}
+	if len(highlights.lines) < 3 {
+		t.Errorf("%d lines cached, want at least 3", len(highlights.lines))
+	}
+}
@@ -0,0 +1,308 @@
+// Copyright (c) 2024, xplshn, u-root and contributors  [3BSD]
+// For more details refer to https://github.com/xplshn/a-utils
+
+// regex.go translates POSIX regular expressions to the syntax of go's regexp
+package ed
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Regular expressions are POSIX basic ones (BRE), or extended ones (ERE) with -E, which
+// translateRE turns into the RE2 syntax of go's regexp, matching the leftmost-longest text as
+// POSIX says. The GNU extensions are understood too: \+, \? and \| in BREs, and \<, \>, \b,
+// \B, \w, \W, \s, \S, \` and \' in both; \< and \> match at any word boundary.
+// Back-references in patterns (\1 to \9) can't be expressed in RE2 and are reported as
+// unsupported; they remain available in the replacement of s.
+
+// errBackref reports a back-reference in a pattern
+var errBackref = fmt.Errorf("back-references in patterns are not supported")
+
+// posixClasses are the character classes of bracket expressions, e.g: [[:alpha:]]
+var posixClasses = map[string]bool{
+	"alnum": true, "alpha": true, "blank": true, "cntrl": true, "digit": true, "graph": true,
+	"lower": true, "print": true, "punct": true, "space": true, "upper": true, "xdigit": true,
+}
+
+// reDupMax is the largest count of an interval, RE_DUP_MAX
+const reDupMax = 255
+
+// compileRE compiles the regular expression of an address or a command delimited by delim,
+// a basic one or an extended one with -E, see translateRE
+func compileRE(re string, delim byte) (*regexp.Regexp, error) {
+	translated, e := translateRE(re, delim, state.extendedRE)
+	if e != nil {
+		return nil, e
+	}
+	rx, e := regexp.Compile(translated)
+	if e != nil {
+		return nil, e
+	}
+	rx.Longest()
+	return rx, nil
+}
+
+// translateRE returns the RE2 syntax of re, a POSIX basic regular expression, or an extended
+// one. An escaped delim stands for the delimiter itself.
+func translateRE(re string, delim byte, extended bool) (string, error) {
+	var out strings.Builder
+	atom := -1        // start in out of the last atom, -1 if there is none to repeat
+	repeated := false // the last atom is already followed by a repetition
+	start := true     // at the start of the expression or a subexpression, for the anchors of BREs
+	var groups []int  // starts in out of the open subexpressions
+
+	literal := func(s string) {
+		atom, repeated, start = out.Len(), false, false
+		out.WriteString(regexp.QuoteMeta(s))
+	}
+	repeat := func(op string) {
+		if atom < 0 {
+			// nothing to repeat, as in "*a" or "\(*a\)": the operator is an ordinary character
+			literal(op[:1])
+			return
+		}
+		if repeated {
+			// "a**" is "a*", RE2 wants it grouped
+			s := out.String()
+			out.Reset()
+			out.WriteString(s[:atom] + "(?:" + s[atom:] + ")")
+		}
+		out.WriteString(op)
+		repeated, start = true, false
+	}
+	open := func() {
+		groups = append(groups, out.Len())
+		out.WriteByte('(')
+		atom, repeated, start = -1, false, true
+	}
+	closeGroup := func() bool {
+		if len(groups) == 0 {
+			return false
+		}
+		atom, groups = groups[len(groups)-1], groups[:len(groups)-1]
+		out.WriteByte(')')
+		repeated, start = false, false
+		return true
+	}
+	alternate := func() {
+		out.WriteByte('|')
+		atom, repeated, start = -1, false, true
+	}
+	// interval parses the "m", "m," or "m,n" of an interval at re[i], then its end, and
+	// returns its RE2 syntax and the index after it
+	interval := func(i int) (string, int, bool) {
+		end := "}"
+		if !extended {
+			end = `\}`
+		}
+		j := strings.Index(re[i:], end)
+		if j < 0 {
+			return "", 0, false
+		}
+		lo, hi, comma := strings.Cut(re[i:i+j], ",")
+		min, e := strconv.Atoi(lo)
+		if e != nil || min > reDupMax || lo[0] == '+' {
+			return "", 0, false
+		}
+		if comma && hi != "" {
+			max, e := strconv.Atoi(hi)
+			if e != nil || max < min || max > reDupMax || hi[0] == '+' {
+				return "", 0, false
+			}
+		}
+		return "{" + re[i:i+j] + "}", i + j + len(end), true
+	}
+
+	for i := 0; i < len(re); {
+		c := re[i]
+		switch {
+		case c == '\\':
+			if i+1 == len(re) {
+				return "", fmt.Errorf("trailing backslash")
+			}
+			c = re[i+1]
+			i += 2
+			switch {
+			case c == delim:
+				literal(string(c))
+			case c >= '1' && c <= '9':
+				return "", errBackref
+			case !extended && c == '(':
+				open()
+			case !extended && c == ')':
+				if !closeGroup() {
+					return "", fmt.Errorf(`unmatched \)`)
+				}
+			case !extended && c == '{':
+				s, next, ok := interval(i)
+				if !ok {
+					return "", fmt.Errorf("invalid interval")
+				}
+				repeat(s)
+				i = next
+			case !extended && c == '|':
+				alternate()
+			case !extended && (c == '+' || c == '?'):
+				repeat(string(c))
+			case c == '<' || c == '>':
+				out.WriteString(`\b`)
+				atom, repeated, start = -1, false, false
+			case strings.IndexByte("bBwWsS", c) >= 0:
+				if c == 'b' || c == 'B' {
+					atom = -1
+				} else {
+					atom = out.Len()
+				}
+				out.WriteString(`\` + string(c))
+				repeated, start = false, false
+			case c == '`':
+				out.WriteString(`\A`)
+				atom, repeated, start = -1, false, false
+			case c == '\'':
+				out.WriteString(`\z`)
+				atom, repeated, start = -1, false, false
+			case c == 'n':
+				literal("\n")
+			default:
+				r, size := utf8.DecodeRuneInString(re[i-1:])
+				literal(string(r))
+				i += size - 1
+			}
+			continue
+		case c == '[':
+			s, next, e := translateBracket(re, i)
+			if e != nil {
+				return "", e
+			}
+			atom, repeated, start = out.Len(), false, false
+			out.WriteString(s)
+			i = next
+			continue
+		case c == '.':
+			atom, repeated, start = out.Len(), false, false
+			out.WriteByte('.')
+		case c == '*':
+			repeat("*")
+		case c == '^' && (extended || start):
+			out.WriteByte('^')
+			atom, repeated = -1, false
+		case c == '$' && (extended || i+1 == len(re) || strings.HasPrefix(re[i+1:], `\)`) || strings.HasPrefix(re[i+1:], `\|`)):
+			out.WriteByte('$')
+			atom, repeated, start = -1, false, false
+		case extended && c == '(':
+			open()
+		case extended && c == ')':
+			if !closeGroup() {
+				literal(")")
+			}
+		case extended && c == '|':
+			alternate()
+		case extended && (c == '+' || c == '?'):
+			repeat(string(c))
+		case extended && c == '{':
+			// a brace that doesn't start an interval is an ordinary character, as in GNU
+			if s, next, ok := interval(i + 1); ok {
+				repeat(s)
+				i = next
+				continue
+			}
+			literal("{")
+		default:
+			r, size := utf8.DecodeRuneInString(re[i:])
+			literal(string(r))
+			i += size
+			continue
+		}
+		i++
+	}
+	if len(groups) > 0 {
+		if extended {
+			return "", fmt.Errorf("unmatched (")
+		}
+		return "", fmt.Errorf(`unmatched \(`)
+	}
+	return out.String(), nil
+}
+
+// translateBracket returns the RE2 syntax of the bracket expression at re[i], which is '[',
+// and the index after it. In bracket expressions backslashes are ordinary characters.
+func translateBracket(re string, i int) (string, int, error) {
+	var out strings.Builder
+	out.WriteByte('[')
+	j := i + 1
+	if j < len(re) && re[j] == '^' {
+		out.WriteByte('^')
+		j++
+	}
+
+	// element parses the character, collating symbol or equivalence class at re[j], or
+	// reports a character class, which it writes
+	element := func() (r rune, class bool, e error) {
+		if re[j] == '[' && j+1 < len(re) && strings.IndexByte(":=.", re[j+1]) >= 0 {
+			kind := re[j+1]
+			end := strings.Index(re[j+2:], string(kind)+"]")
+			if end < 0 {
+				return 0, false, fmt.Errorf("unterminated [%c", kind)
+			}
+			name := re[j+2 : j+2+end]
+			j += 2 + end + 2
+			if kind == ':' {
+				if !posixClasses[name] {
+					return 0, false, fmt.Errorf("invalid character class: %s", name)
+				}
+				out.WriteString("[:" + name + ":]")
+				return 0, true, nil
+			}
+			// only single characters are collating elements, and their own equivalence class
+			if utf8.RuneCountInString(name) != 1 {
+				return 0, false, fmt.Errorf("unsupported collating element: %s", name)
+			}
+			r, _ = utf8.DecodeRuneInString(name)
+			return r, false, nil
+		}
+		r, size := utf8.DecodeRuneInString(re[j:])
+		j += size
+		return r, false, nil
+	}
+	quote := func(r rune) string {
+		if r < utf8.RuneSelf && strings.ContainsRune(`\]-^[`, r) {
+			return `\` + string(r)
+		}
+		return string(r)
+	}
+
+	for first := true; ; first = false {
+		if j >= len(re) {
+			return "", 0, fmt.Errorf("unterminated bracket expression")
+		}
+		if re[j] == ']' && !first {
+			out.WriteByte(']')
+			return out.String(), j + 1, nil
+		}
+		lo, class, e := element()
+		if e != nil {
+			return "", 0, e
+		}
+		if class {
+			continue
+		}
+		// a '-' is a range unless it is the last character of the expression
+		if j+1 < len(re) && re[j] == '-' && re[j+1] != ']' {
+			j++
+			hi, class, e := element()
+			if e != nil {
+				return "", 0, e
+			}
+			if class || hi < lo {
+				return "", 0, fmt.Errorf("invalid range end")
+			}
+			out.WriteString(quote(lo) + "-" + quote(hi))
+			continue
+		}
+		out.WriteString(quote(lo))
+	}
+}
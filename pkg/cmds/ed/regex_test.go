@@ -0,0 +1,114 @@
+// Copyright (c) 2024, xplshn, u-root and contributors  [3BSD]
+// For more details refer to https://github.com/xplshn/a-utils
+
+package ed
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+)
+
+// Test translateRE, with examples of the POSIX chapter on regular expressions and of GNU ed
+var testTableTranslateRE = []struct {
+	re       string
+	extended bool
+	delim    byte
+	in       string
+	want     string // the text matched, leftmost-longest
+	noMatch  bool
+}{
+	// BRE
+	{re: `a.c`, in: "xabcx", want: "abc"},
+	{re: `*ab`, in: "x*ab", want: "*ab"},
+	{re: `^*ab`, in: "*ab", want: "*ab"},
+	{re: `\(*a\)`, in: "b*a", want: "*a"},
+	{re: `a\{2\}`, in: "aaa", want: "aa"},
+	{re: `a\{2,\}`, in: "baaaab", want: "aaaa"},
+	{re: `a\{1,2\}b`, in: "aaab", want: "aab"},
+	{re: `\(ab\)*c`, in: "xababc", want: "ababc"},
+	{re: `a|b`, in: "a|b", want: "a|b"},
+	{re: `a+?`, in: "aa+?", want: "a+?"},
+	{re: `(x){2}`, in: "(x){2}", want: "(x){2}"},
+	{re: `a^b`, in: "a^b", want: "a^b"},
+	{re: `a$b`, in: "a$b", want: "a$b"},
+	{re: `\(^a\)`, in: "ab", want: "a"},
+	{re: `\(^a\)`, in: "ba", noMatch: true},
+	{re: `\(a$\)`, in: "ba", want: "a"},
+	{re: `[[:digit:]]\{3\}`, in: "ab1234", want: "123"},
+	{re: `[]a]*`, in: "]a]b", want: "]a]"},
+	{re: `[^]a]`, in: "]ab", want: "b"},
+	{re: `[\]`, in: `a\b`, want: `\`},
+	{re: `[a-c]*`, in: "abcd", want: "abc"},
+	{re: `[[.-.]a]*`, in: "-a-b", want: "-a-"},
+	{re: `[[=e=]]`, in: "hello", want: "e"},
+	{re: `a**`, in: "aaa", want: "aaa"},
+	{re: `x*`, in: "xxxy", want: "xxx"},
+	{re: `\.`, in: "a.b", want: "."},
+	{re: `\(wee\|week\)\(knights\|night\)`, in: "weeknights", want: "weeknights"},
+	{re: `\(a\|b\)*`, in: "abbac", want: "abba"},
+	{re: `a\+`, in: "caaat", want: "aaa"},
+	{re: `ab\?c`, in: "ac", want: "ac"},
+	{re: `\<the\>`, in: "other the", want: "the"},
+	{re: `a\/b`, delim: '/', in: "a/b", want: "a/b"},
+	{re: `a\?b`, delim: '?', in: "a?b", want: "a?b"},
+
+	// ERE
+	{re: `(wee|week)(knights|night)`, extended: true, in: "weeknights", want: "weeknights"},
+	{re: `a+`, extended: true, in: "caaat", want: "aaa"},
+	{re: `a{2,3}`, extended: true, in: "aaaa", want: "aaa"},
+	{re: `a|ab`, extended: true, in: "abc", want: "ab"},
+	{re: `x(a|b)*y`, extended: true, in: "xabay", want: "xabay"},
+	{re: `\(a\)`, extended: true, in: "(a)", want: "(a)"},
+	{re: `[\n]`, extended: true, in: `a\b`, want: `\`},
+	{re: `a{`, extended: true, in: "a{", want: "a{"},
+	{re: `a{x}`, extended: true, in: "a{x}", want: "a{x}"},
+	{re: `*a`, extended: true, in: "*a", want: "*a"},
+	{re: `a**`, extended: true, in: "aa", want: "aa"},
+	{re: `a)`, extended: true, in: "a)", want: "a)"},
+	{re: `^a|b$`, extended: true, in: "ab", want: "a"},
+}
+
+func TestTranslateRE(t *testing.T) {
+	for _, tt := range testTableTranslateRE {
+		translated, err := translateRE(tt.re, tt.delim, tt.extended)
+		if err != nil {
+			t.Errorf("translateRE(%q, %v) = %v", tt.re, tt.extended, err)
+			continue
+		}
+		rx, err := regexp.Compile(translated)
+		if err != nil {
+			t.Errorf("translateRE(%q, %v) = %q, which doesn't compile: %v", tt.re, tt.extended, translated, err)
+			continue
+		}
+		rx.Longest()
+		got := rx.FindStringIndex(tt.in)
+		switch {
+		case tt.noMatch && got != nil:
+			t.Errorf("%q (%q) matches %q in %q", tt.re, translated, tt.in[got[0]:got[1]], tt.in)
+		case !tt.noMatch && got == nil:
+			t.Errorf("%q (%q) doesn't match %q", tt.re, translated, tt.in)
+		case !tt.noMatch && tt.in[got[0]:got[1]] != tt.want:
+			t.Errorf("%q (%q) matches %q in %q, want %q", tt.re, translated, tt.in[got[0]:got[1]], tt.in, tt.want)
+		}
+	}
+}
+
+// Test the expressions that translateRE reports, back-references among them
+func TestTranslateREErrors(t *testing.T) {
+	for _, tt := range []struct {
+		re       string
+		extended bool
+	}{
+		{re: `\(a\)\1`}, {re: `\(a`}, {re: `a\)`}, {re: `[a`}, {re: `a\{1`}, {re: `a\{2,1\}`},
+		{re: `a\{256\}`}, {re: `[[:foo:]]`}, {re: `[z-a]`}, {re: `[[.ch.]]`}, {re: `a\`},
+		{re: `(a)\1`, extended: true}, {re: `(a`, extended: true},
+	} {
+		if translated, err := translateRE(tt.re, 0, tt.extended); err == nil {
+			t.Errorf("translateRE(%q, %v) = %q, want an error", tt.re, tt.extended, translated)
+		}
+	}
+	if _, err := translateRE(`\(a\)\1`, 0, false); !errors.Is(err, errBackref) {
+		t.Errorf("translateRE of a back-reference = %v, want %v", err, errBackref)
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright (c) 2024, xplshn, u-root and contributors  [3BSD]
+// For more details refer to https://github.com/xplshn/a-utils
+
+// rope.go - defines the rope, the sequence of lines of a FileBuffer
+package ed
+
+// A rope is a sequence of line identifiers, kept as a tree of pieces: runs of consecutive
+// identifiers, as a file read or lines inserted make them. Finding, inserting and deleting
+// lines takes a time logarithmic in the number of pieces, whatever the number of lines.
+//
+// Ropes are persistent: operations return new ropes sharing their nodes with the old ones,
+// which are never modified, so that the undo history keeps every state of the file for
+// free. The tree is a treap whose priorities are a hash of the pieces, and adjacent pieces
+// are coalesced when they are consecutive, so ropes of the same identifiers have the same
+// shape, and compare equal with reflect.DeepEqual. The nil rope is empty.
+type rope struct {
+	left, right *rope
+	start, n    int    // the piece: identifiers start to start+n-1
+	len         int    // number of identifiers of the tree
+	prio        uint64 // heap order of the treap, parents have the highest
+}
+
+// newPiece returns the rope of the n identifiers from start
+func newPiece(start, n int) *rope {
+	// splitmix64, to spread the priorities of nearby pieces
+	x := uint64(start)*0x9e3779b97f4a7c15 + uint64(n)
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return &rope{start: start, n: n, len: n, prio: x ^ x>>31}
+}
+
+// ropeOf returns the rope of the identifiers ids
+func ropeOf(ids ...int) (r *rope) {
+	for _, id := range ids {
+		r = concat(r, newPiece(id, 1))
+	}
+	return
+}
+
+// Len returns the number of identifiers of the rope
+func (r *rope) Len() int {
+	if r == nil {
+		return 0
+	}
+	return r.len
+}
+
+// with returns a copy of the node r with the children left and right
+func (r *rope) with(left, right *rope) *rope {
+	c := *r
+	c.left, c.right = left, right
+	c.len = left.Len() + c.n + right.Len()
+	return &c
+}
+
+// At returns the identifier at index i, which must be in the rope
+func (r *rope) At(i int) int {
+	for {
+		ll := r.left.Len()
+		switch {
+		case i < ll:
+			r = r.left
+		case i < ll+r.n:
+			return r.start + i - ll
+		default:
+			i -= ll + r.n
+			r = r.right
+		}
+	}
+}
+
+// index returns the index of the identifier id, reports false if it isn't in the rope. It
+// visits the pieces in order, identifiers having no order in the rope.
+func (r *rope) index(id int) (int, bool) {
+	if r == nil {
+		return -1, false
+	}
+	if i, ok := r.left.index(id); ok {
+		return i, true
+	}
+	if id >= r.start && id < r.start+r.n {
+		return r.left.Len() + id - r.start, true
+	}
+	if i, ok := r.right.index(id); ok {
+		return r.left.Len() + r.n + i, true
+	}
+	return -1, false
+}
+
+// eachPiece calls fn, in order, with the pieces of the identifiers from index i to j-1
+func (r *rope) eachPiece(i, j int, fn func(start, n int)) {
+	if r == nil || i >= j {
+		return
+	}
+	ll := r.left.Len()
+	if i < ll {
+		r.left.eachPiece(i, min(j, ll), fn)
+	}
+	if from, to := max(i-ll, 0), min(j-ll, r.n); from < to {
+		fn(r.start+from, to-from)
+	}
+	if j > ll+r.n {
+		r.right.eachPiece(max(i-ll-r.n, 0), j-ll-r.n, fn)
+	}
+}
+
+// ids returns the identifiers of the rope
+func (r *rope) ids() []int {
+	ids := make([]int, 0, r.Len())
+	r.eachPiece(0, r.Len(), func(start, n int) {
+		for id := start; id < start+n; id++ {
+			ids = append(ids, id)
+		}
+	})
+	return ids
+}
+
+// split returns the ropes of the first i identifiers of r and of the others
+func (r *rope) split(i int) (*rope, *rope) {
+	if i <= 0 {
+		return nil, r
+	}
+	if i >= r.Len() {
+		return r, nil
+	}
+	ll := r.left.Len()
+	switch {
+	case i <= ll:
+		a, b := r.left.split(i)
+		return a, r.with(b, r.right)
+	case i >= ll+r.n:
+		a, b := r.right.split(i - ll - r.n)
+		return r.with(r.left, a), b
+	}
+	// the piece itself is cut, in two pieces that aren't consecutive with their neighbors
+	k := i - ll
+	return merge(r.left, newPiece(r.start, k)), merge(newPiece(r.start+k, r.n-k), r.right)
+}
+
+// first returns the first piece of a rope that isn't empty
+func (r *rope) first() *rope {
+	for r.left != nil {
+		r = r.left
+	}
+	return r
+}
+
+// last returns the last piece of a rope that isn't empty
+func (r *rope) last() *rope {
+	for r.right != nil {
+		r = r.right
+	}
+	return r
+}
+
+// merge returns the concatenation of a and b, the last piece of a not being followed by the
+// first of b
+func merge(a, b *rope) *rope {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case a.prio >= b.prio:
+		return a.with(a.left, merge(a.right, b))
+	default:
+		return b.with(merge(a, b.left), b.right)
+	}
+}
+
+// concat returns the concatenation of a and b, coalescing the last piece of a with the first
+// of b if they are consecutive
+func concat(a, b *rope) *rope {
+	if a == nil || b == nil {
+		return merge(a, b)
+	}
+	la, fb := a.last(), b.first()
+	if la.start+la.n != fb.start {
+		return merge(a, b)
+	}
+	a, _ = a.split(a.len - la.n)
+	_, b = b.split(fb.n)
+	return merge(merge(a, newPiece(la.start, la.n+fb.n)), b)
+}
+
+// insert returns the rope with s inserted before index i
+func (r *rope) insert(i int, s *rope) *rope {
+	a, b := r.split(i)
+	return concat(concat(a, s), b)
+}
+
+// slice returns the rope of the identifiers from index i to j-1
+func (r *rope) slice(i, j int) *rope {
+	_, rest := r.split(i)
+	s, _ := rest.split(j - i)
+	return s
+}
+
+// delete returns the rope without the identifiers from index i to j-1
+func (r *rope) delete(i, j int) *rope {
+	a, rest := r.split(i)
+	_, b := rest.split(j - i)
+	return concat(a, b)
+}
+
+// commonPrefix returns the number of identifiers a and b start with in common. Pieces being
+// coalesced, the ropes differ past the first pieces that do.
+func commonPrefix(a, b *rope) int {
+	if a == b {
+		return a.Len()
+	}
+	var pieces [][2]int
+	a.eachPiece(0, a.Len(), func(start, n int) {
+		pieces = append(pieces, [2]int{start, n})
+	})
+	common, i, done := 0, 0, false
+	b.eachPiece(0, b.Len(), func(start, n int) {
+		if done || i == len(pieces) || pieces[i][0] != start {
+			done = true
+			return
+		}
+		common += min(n, pieces[i][1])
+		done = n != pieces[i][1]
+		i++
+	})
+	return common
+}
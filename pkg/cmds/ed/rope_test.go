@@ -0,0 +1,65 @@
+// Copyright (c) 2024, xplshn, u-root and contributors  [3BSD]
+// For more details refer to https://github.com/xplshn/a-utils
+
+package ed
+
+import (
+	"math/rand"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// Test the rope against a slice, inserting, slicing and deleting at random
+func TestRope(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	var r *rope
+	var want []int
+	next := 0
+	for i := 0; i < 2000; i++ {
+		if len(want) > 0 && rnd.Intn(3) == 0 {
+			from := rnd.Intn(len(want))
+			to := from + 1 + rnd.Intn(min(len(want)-from, 20))
+			if got := r.slice(from, to).ids(); !slices.Equal(got, want[from:to]) {
+				t.Fatalf("slice(%d, %d) = %v, want %v", from, to, got, want[from:to])
+			}
+			r, want = r.delete(from, to), slices.Delete(want, from, to)
+		} else {
+			at, n := rnd.Intn(len(want)+1), 1+rnd.Intn(10)
+			r = r.insert(at, newPiece(next, n))
+			for id := next + n - 1; id >= next; id-- {
+				want = slices.Insert(want, at, id)
+			}
+			next += n
+		}
+		if r.Len() != len(want) {
+			t.Fatalf("after %d operations, Len() = %d, want %d", i+1, r.Len(), len(want))
+		}
+	}
+	if got := r.ids(); !slices.Equal(got, want) {
+		t.Fatalf("ids() = %v, want %v", got, want)
+	}
+	for i, id := range want {
+		if got := r.At(i); got != id {
+			t.Errorf("At(%d) = %d, want %d", i, got, id)
+		}
+		if got, ok := r.index(id); !ok || got != i {
+			t.Errorf("index(%d) = %d, %v, want %d, true", id, got, ok, i)
+		}
+	}
+	if _, ok := r.index(next); ok {
+		t.Errorf("index(%d) = true for an identifier not in the rope", next)
+	}
+}
+
+// Test that ropes of the same identifiers have the same shape, however they were made
+func TestRopeShape(t *testing.T) {
+	a := newPiece(0, 10).delete(3, 5).insert(3, newPiece(3, 2))
+	b := ropeOf(0, 1, 2).insert(3, ropeOf(7, 8, 9)).insert(3, newPiece(3, 4))
+	if want := newPiece(0, 10); !reflect.DeepEqual(a, want) || !reflect.DeepEqual(b, want) {
+		t.Errorf("the ropes of 0 to 9 differ: %v and %v", a.ids(), b.ids())
+	}
+	if a.delete(0, 10) != nil {
+		t.Errorf("deleting every identifier doesn't leave the empty rope")
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2024, xplshn, u-root and contributors  [3BSD]
+// For more details refer to https://github.com/xplshn/a-utils
+
+// scratch.go - defines the scratch file, where large buffers keep their lines
+package ed
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// scratchSize is the size of the lines a FileBuffer keeps in memory, past which it moves
+// them to a scratch file
+var scratchSize = 64 << 20
+
+const (
+	scratchPage  = 256 // lines of a page, the unit the scratch file is read and cached in
+	scratchCache = 256 // pages kept in memory
+)
+
+// A scratch is a temporary file holding lines, identified by the order they were added in, as
+// GNU ed does with its buffer file. Each line is written as its length, a uvarint, followed by
+// its text. Only the offset of every page is kept in memory, and the last pages read. Failing
+// to read it back panics, the lines being lost.
+type scratch struct {
+	file  *os.File
+	w     *bufio.Writer
+	size  int64      // bytes written to file
+	text  int64      // bytes of the text of the lines
+	n     int        // number of lines
+	pages []pageInfo // where every page starts
+	cache map[int][]string
+}
+
+// A pageInfo locates a page of the scratch file
+type pageInfo struct {
+	off  int64 // offset of the page in the file
+	text int64 // bytes of the text of the lines before the page
+}
+
+// newScratch creates a scratch file in the temporary directory
+func newScratch() (*scratch, error) {
+	f, e := os.CreateTemp("", "ed-")
+	if e != nil {
+		return nil, fmt.Errorf("cannot create the scratch file: %v", e)
+	}
+	// systems that can't remove open files have it removed by close
+	os.Remove(f.Name())
+	s := &scratch{file: f, w: bufio.NewWriter(f), cache: make(map[int][]string)}
+	runtime.SetFinalizer(s, (*scratch).close)
+	return s, nil
+}
+
+// close closes and removes the scratch file
+func (s *scratch) close() {
+	s.file.Close()
+	os.Remove(s.file.Name())
+}
+
+// add writes lines to the scratch file, identified from s.n onwards
+func (s *scratch) add(lines []string) error {
+	// the last page is cached as it was, if it isn't full
+	delete(s.cache, s.n/scratchPage)
+	var n [binary.MaxVarintLen64]byte
+	for _, l := range lines {
+		if s.n%scratchPage == 0 {
+			s.pages = append(s.pages, pageInfo{off: s.size, text: s.text})
+		}
+		k := binary.PutUvarint(n[:], uint64(len(l)))
+		if _, e := s.w.Write(n[:k]); e != nil {
+			return fmt.Errorf("cannot write the scratch file: %v", e)
+		}
+		if _, e := s.w.WriteString(l); e != nil {
+			return fmt.Errorf("cannot write the scratch file: %v", e)
+		}
+		s.size += int64(k + len(l))
+		s.text += int64(len(l))
+		s.n++
+	}
+	return nil
+}
+
+// page returns the lines of page p, reading it if it isn't cached
+func (s *scratch) page(p int) []string {
+	if lines, ok := s.cache[p]; ok {
+		return lines
+	}
+	end := s.size
+	if p+1 < len(s.pages) {
+		end = s.pages[p+1].off
+	}
+	buf := make([]byte, end-s.pages[p].off)
+	if e := s.w.Flush(); e != nil {
+		panic(fmt.Errorf("cannot write the scratch file: %v", e))
+	}
+	if _, e := s.file.ReadAt(buf, s.pages[p].off); e != nil {
+		panic(fmt.Errorf("cannot read the scratch file: %v", e))
+	}
+	text := string(buf)
+	lines := make([]string, 0, scratchPage)
+	for i := 0; i < len(buf); {
+		l, k := binary.Uvarint(buf[i:])
+		i += k
+		lines = append(lines, text[i:i+int(l)])
+		i += int(l)
+	}
+	if len(s.cache) >= scratchCache {
+		for old := range s.cache {
+			delete(s.cache, old)
+			break
+		}
+	}
+	s.cache[p] = lines
+	return lines
+}
+
+// line returns the line id
+func (s *scratch) line(id int) string {
+	return s.page(id / scratchPage)[id%scratchPage]
+}
+
+// textBefore returns the size of the text of the lines before id
+func (s *scratch) textBefore(id int) int64 {
+	if id == s.n {
+		return s.text
+	}
+	t := s.pages[id/scratchPage].text
+	for _, l := range s.page(id / scratchPage)[:id%scratchPage] {
+		t += int64(len(l))
+	}
+	return t
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2024, xplshn, u-root and contributors  [3BSD]
+// For more details refer to https://github.com/xplshn/a-utils
+
+// script.go - the commands of -e and -f, and the golden file they are tested against
+package ed
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// With -e and -f, ed runs the commands given, in order, rather than those of the standard
+// input, from which a, i and c read their text instead. With --golden, ed then compares the
+// buffer with a golden file, the result the script should have, and prints their differences
+// as a unified diff: the script is tested, no file being written and no shell command run,
+// see System. As w writes nothing then, the buffer stays modified, and q and e warn of it:
+// the scripts tested end with Q, or without quitting.
+
+// diffContext is the number of lines unchanged around the changes of a diff
+const diffContext = 3
+
+// textInput is the reader of the text of a, i and c with -e and -f, nil if they read it with
+// the commands
+var textInput io.Reader
+
+// A scriptFlag adds the commands of -e, or of the script files of -f, to the script
+type scriptFlag struct {
+	file   bool
+	script *strings.Builder
+}
+
+func (f scriptFlag) String() string {
+	return ""
+}
+
+func (f scriptFlag) Set(s string) error {
+	if f.file {
+		b, e := os.ReadFile(s)
+		if e != nil {
+			return e
+		}
+		s = strings.TrimSuffix(string(b), "\n")
+	}
+	f.script.WriteString(s + "\n")
+	return nil
+}
+
+// A textReader gives the lines of the input mode apart from the commands
+type textReader interface {
+	ReadText() (string, error)
+}
+
+// scriptInput reads the commands of a script, and the text of the input mode from text
+type scriptInput struct {
+	*input
+	text *input
+}
+
+func (in *scriptInput) ReadText() (string, error) {
+	return in.text.ReadLine()
+}
+
+// compareGolden writes the differences between the golden file and the buffer to out, as a
+// unified diff, and reports whether there are
+func compareGolden(out io.Writer) (bool, error) {
+	f, e := os.Open(state.golden)
+	if e != nil {
+		return false, e
+	}
+	defer f.Close()
+	var want []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		want = append(want, s.Text())
+	}
+	if e = s.Err(); e != nil {
+		return false, e
+	}
+	got := make([]string, 0, buffer.Len())
+	buffer.file.eachPiece(0, buffer.Len(), func(start, n int) {
+		for id := start; id < start+n; id++ {
+			got = append(got, buffer.line(id))
+		}
+	})
+	name := state.fileName
+	if name == "" {
+		name = "buffer"
+	}
+	return unifiedDiff(out, state.golden, name, want, got), nil
+}
+
+// A diffOp is a line of a diff: kept as it is (' '), deleted ('-') or inserted ('+')
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// diffLines returns the shortest edit script turning a into b, with the algorithm of Myers
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1) // furthest x reached on every diagonal k = x - y
+	var trace [][]int
+	// prev returns the diagonal the furthest path to diagonal k comes from, with d changes
+	prev := func(v []int, d, k int) int {
+		if k == -d || k != d && v[offset+k-1] < v[offset+k+1] {
+			return k + 1 // an insertion
+		}
+		return k - 1 // a deletion
+	}
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, slices.Clone(v))
+		for k := -d; k <= d; k += 2 {
+			x := v[offset+k-1] + 1
+			if pk := prev(v, d, k); pk > k {
+				x = v[offset+pk]
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	var ops []diffOp
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		pk := prev(v, d, x-y)
+		px := v[offset+pk]
+		py := px - pk
+		for x > px && y > py {
+			ops = append(ops, diffOp{' ', a[x-1]})
+			x, y = x-1, y-1
+		}
+		switch {
+		case d == 0:
+		case x == px:
+			ops = append(ops, diffOp{'+', b[y-1]})
+			y--
+		default:
+			ops = append(ops, diffOp{'-', a[x-1]})
+			x--
+		}
+	}
+	slices.Reverse(ops)
+	return ops
+}
+
+// unifiedDiff writes the differences between the lines a of the file nameA and b of nameB to
+// out, as diff -u does, and reports whether there are
+func unifiedDiff(out io.Writer, nameA, nameB string, a, b []string) bool {
+	ops := diffLines(a, b)
+	if !slices.ContainsFunc(ops, func(op diffOp) bool { return op.kind != ' ' }) {
+		return false
+	}
+	fmt.Fprintf(out, "--- %s\n+++ %s\n", nameA, nameB)
+	// lineA[i] and lineB[i] are the numbers of the lines of a and b before ops[i]
+	lineA, lineB := make([]int, len(ops)+1), make([]int, len(ops)+1)
+	for i, op := range ops {
+		lineA[i+1], lineB[i+1] = lineA[i], lineB[i]
+		if op.kind != '+' {
+			lineA[i+1]++
+		}
+		if op.kind != '-' {
+			lineB[i+1]++
+		}
+	}
+	// hunkRange returns the range of a hunk in the @@ line, from the lines before its start
+	// and its end
+	hunkRange := func(start, end int) string {
+		if end-start == 0 {
+			return fmt.Sprintf("%d,0", start)
+		}
+		if end-start == 1 {
+			return fmt.Sprint(start + 1)
+		}
+		return fmt.Sprintf("%d,%d", start+1, end-start)
+	}
+	for i := 0; i < len(ops); {
+		for i < len(ops) && ops[i].kind == ' ' {
+			i++
+		}
+		if i == len(ops) {
+			break
+		}
+		// changes separated by at most twice the context go in the same hunk
+		end := i
+		for j := i; j < len(ops) && j-end <= 2*diffContext; j++ {
+			if ops[j].kind != ' ' {
+				end = j + 1
+			}
+		}
+		start, stop := max(i-diffContext, 0), min(end+diffContext, len(ops))
+		fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(lineA[start], lineA[stop]), hunkRange(lineB[start], lineB[stop]))
+		for _, op := range ops[start:stop] {
+			fmt.Fprintf(out, "%c%s\n", op.kind, op.line)
+		}
+		i = stop
+	}
+	return true
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2024, xplshn, u-root and contributors  [3BSD]
+// For more details refer to https://github.com/xplshn/a-utils
+
+package ed
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Test the unified diffs of the golden mode
+func TestUnifiedDiff(t *testing.T) {
+	lines := func(s string) []string { return strings.Fields(s) }
+	for _, tt := range []struct {
+		a, b string
+		want string
+	}{
+		{"a b c", "a b c", ""},
+		{"", "a", "@@ -0,0 +1 @@\n+a\n"},
+		{"a b c", "a c", "@@ -1,3 +1,2 @@\n a\n-b\n c\n"},
+		{"1 2 3 4 5 6 7 8 9 10 11 12", "1 2 3 4 x 6 7 8 9 10 11 12",
+			"@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+x\n 6\n 7\n 8\n"},
+		// changes separated by more than twice the context are in hunks of their own
+		{"1 2 3 4 5 6 7 8 9 10", "x 2 3 4 5 6 7 8 9 y",
+			"@@ -1,4 +1,4 @@\n-1\n+x\n 2\n 3\n 4\n@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+y\n"},
+		{"1 2 3 4 5 6 7 8", "x 2 3 4 5 6 7 y",
+			"@@ -1,8 +1,8 @@\n-1\n+x\n 2\n 3\n 4\n 5\n 6\n 7\n-8\n+y\n"},
+	} {
+		var out bytes.Buffer
+		differ := unifiedDiff(&out, "a", "b", lines(tt.a), lines(tt.b))
+		want := tt.want
+		if want != "" {
+			want = "--- a\n+++ b\n" + want
+		}
+		if differ != (tt.want != "") || out.String() != want {
+			t.Errorf("diff of %q and %q = %v,\n%s\nwant\n%s", tt.a, tt.b, differ, out.String(), want)
+		}
+	}
+}
+
+// Test the scripts of testdata: NAME.ed runs on a copy of NAME.in, reading the text of a, i
+// and c from NAME.txt, and must leave it as NAME.golden, nothing being written
+func TestScripts(t *testing.T) {
+	defer func() { state.golden, textInput = "", nil }()
+	scripts, err := filepath.Glob(filepath.Join("testdata", "*.ed"))
+	if err != nil || len(scripts) == 0 {
+		t.Fatalf("no scripts in testdata: %v", err)
+	}
+	for _, script := range scripts {
+		name := strings.TrimSuffix(script, ".ed")
+		t.Run(filepath.Base(name), func(t *testing.T) {
+			commands, err := os.Open(script)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer commands.Close()
+			text, err := os.Open(name + ".txt")
+			if err != nil {
+				text, _ = os.Open(os.DevNull)
+			}
+			defer text.Close()
+			input, err := os.ReadFile(name + ".in")
+			if err != nil {
+				t.Fatal(err)
+			}
+			file := filepath.Join(t.TempDir(), filepath.Base(name))
+			if err := os.WriteFile(file, input, 0o666); err != nil {
+				t.Fatal(err)
+			}
+
+			state.golden, textInput = name+".golden", text
+			var out, diff bytes.Buffer
+			if err := runEd(commands, &out, true, "", file); err != nil {
+				t.Fatal(err)
+			}
+			if differ, err := compareGolden(&diff); err != nil || differ {
+				t.Errorf("the result of %s differs from %s: %v\n%s", script, state.golden, err, diff.String())
+			}
+			if out.Len() != 0 {
+				t.Errorf("%s printed %q", script, out.String())
+			}
+			if written, _ := os.ReadFile(file); !bytes.Equal(written, input) {
+				t.Errorf("%s was written in the golden mode", file)
+			}
+			if !buffer.Dirty() {
+				t.Errorf("the buffer of %s isn't modified, though nothing was written", script)
+			}
+		})
+	}
+}
+
+// Test that the golden mode runs no shell command
+func TestGoldenShell(t *testing.T) {
+	defer func() { state.golden = "" }()
+	mark := filepath.Join(t.TempDir(), "ran")
+	for _, cmd := range []string{"!touch " + mark, "w !touch " + mark} {
+		buffer = NewFileBuffer([]string{"line"})
+		state.golden = "x.golden"
+		if err := execute(cmd, nil, io.Discard); err != errShellGolden {
+			t.Errorf("%q in the golden mode: %v, want %v", cmd, err, errShellGolden)
+		}
+		if _, err := os.Stat(mark); err == nil {
+			t.Fatalf("%q ran in the golden mode", cmd)
+		}
+	}
+}
@@ -4,7 +4,7 @@
 
 // system.go implements the "System" wrapper class to exec.Cmd
 
-package main
+package ed
 
 import (
 	"io"
@@ -28,6 +28,12 @@ type System struct {
 
 // Run a command (using the shell for arg processing)
 func (s *System) Run() (e error) {
+	if state.restricted {
+		return errShellRestricted
+	}
+	if state.golden != "" {
+		return errShellGolden
+	}
 	s.cmdSane = rxSanitize.ReplaceAllString(s.Cmd, "..")
 	idx := rxCmdSub.FindAllStringIndex(s.cmdSane, -1)
 	fCmd := ""
@@ -0,0 +1,106 @@
+// Copyright (c) 2024-2024 xplshn                       [3BSD]
+// For more details refer to https://github.com/xplshn/a-utils
+package envsubst
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/xplshn/a-utils/pkg/ccmd"
+)
+
+func init() {
+	ccmd.Register("envsubst", Main)
+}
+
+// Main runs envsubst, see ccmd.MainFunc.
+func Main(args []string, stdio ccmd.Stdio) int {
+	fs := ccmd.NewFlagSet("envsubst", flag.ContinueOnError)
+	cmdInfo := &ccmd.CmdInfo{
+		Name:        "envsubst",
+		Authors:     []string{"xplshn"},
+		Repository:  "https://github.com/xplshn/a-utils",
+		Description: "Substitutes environment variables in shell format strings",
+		Synopsis:    "[SHELL_FORMAT]",
+		Behavior:    "By default, reads stdin and substitutes environment variables.",
+		Examples: []ccmd.Example{
+			{Command: "echo 'Hello $USER' | envsubst"},
+		},
+		Flags: fs,
+	}
+
+	var variablesFlag bool
+
+	fs.BoolVar(&variablesFlag, "variables", false, "output the variables occurring in SHELL-FORMAT")
+	fs.Alias("v", "variables")
+
+	helpPage, err := cmdInfo.GenerateHelpPage()
+	if err != nil {
+		ccmd.Fatalf("generating help page: %v", err)
+	}
+
+	fs.Usage = func() {
+		fmt.Fprint(stdio.Out, helpPage)
+	}
+
+	cmdInfo.ParseArgs(args[1:])
+
+	args = fs.Args()
+
+	// Check if no arguments and no stdin
+	if f, ok := stdio.In.(*os.File); ok && len(args) == 0 && ccmd.IsTerminal(f) {
+		// If there are no args and no stdin, print the help page
+		fs.Usage()
+		return 0
+	}
+
+	// If variables flag is set, output the variables found in SHELL-FORMAT
+	if variablesFlag {
+		if len(args) > 0 {
+			fmt.Fprintln(stdio.Out, extractVariables(args[0]))
+		}
+		return 0
+	}
+
+	// Read from stdin if no shell format is provided
+	input := ""
+	if len(args) == 0 {
+		inputBytes, err := io.ReadAll(stdio.In)
+		if err != nil {
+			ccmd.Fatalf("reading input: %v", err)
+		}
+		input = string(inputBytes)
+	} else {
+		input = args[0]
+	}
+
+	// Substitute environment variables
+	output := substituteEnvVariables(input)
+	fmt.Fprint(stdio.Out, output)
+	return 0
+}
+
+// Extracts environment variable names from a shell format string
+func extractVariables(shellFormat string) string {
+	re := regexp.MustCompile(`\$\{?([A-Za-z_][A-Za-z0-9_]*)\}?`)
+	matches := re.FindAllStringSubmatch(shellFormat, -1)
+
+	var vars []string
+	for _, match := range matches {
+		vars = append(vars, match[1])
+	}
+	return strings.Join(vars, "\n")
+}
+
+// Substitutes environment variables in the input string
+func substituteEnvVariables(input string) string {
+	re := regexp.MustCompile(`\$\{?([A-Za-z_][A-Za-z0-9_]*)\}?`)
+	return re.ReplaceAllStringFunc(input, func(v string) string {
+		varName := strings.Trim(v, "${}")
+		return os.Getenv(varName)
+	})
+}
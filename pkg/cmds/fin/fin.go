@@ -0,0 +1,398 @@
+package fin
+
+import (
+	"bufio"
+	"flag"
+	"strings"
+	"fmt"
+	"io"
+	"os"
+	"time"
+	"syscall"
+	"path/filepath"
+
+	"github.com/xplshn/a-utils/pkg/ccmd"
+)
+
+// FileData represents the information gathered for each file
+type FileData struct {
+	Path       string
+	Mode       os.FileMode
+	Inode      uint64
+	Blocks     int64
+	Size       uint64
+	Nlink      uint64
+	UID        uint32
+	GID        uint32
+	Mtime      time.Time
+	Ctime      time.Time
+	Atime      time.Time
+	IsDir      bool
+	IsSymlink  bool
+}
+
+// fileRecord converts the information gathered for a file into a record of the fields documented in main.
+func fileRecord(fd FileData) ccmd.Record {
+	return ccmd.Record{
+		"path":    fd.Path,
+		"name":    filepath.Base(fd.Path),
+		"mode":    fd.Mode.String(),
+		"inode":   fd.Inode,
+		"blocks":  fd.Blocks,
+		"size":    fd.Size,
+		"nlink":   fd.Nlink,
+		"uid":     fd.UID,
+		"gid":     fd.GID,
+		"mtime":   fd.Mtime,
+		"ctime":   fd.Ctime,
+		"atime":   fd.Atime,
+		"dir":     fd.IsDir,
+		"symlink": fd.IsSymlink,
+	}
+}
+
+// gatherFileData collects file information based on the provided path
+func gatherFileData(path string) (FileData, error) {
+	var fileInfo os.FileInfo
+	var err error
+
+	fileInfo, err = os.Stat(path)
+
+	if err != nil {
+		return FileData{}, err
+	}
+
+	sysStat, ok := fileInfo.Sys().(*syscall.Stat_t)
+	if !ok {
+		return FileData{}, fmt.Errorf("failed to get raw syscall.Stat_t for file: %s", path)
+	}
+
+	return FileData{
+		Path:       path,
+		Mode:       fileInfo.Mode(),
+		Inode:      sysStat.Ino,
+		Blocks:     sysStat.Blocks,
+		Size:       uint64(fileInfo.Size()),
+		Nlink:      sysStat.Nlink,
+		UID:        sysStat.Uid,
+		GID:        sysStat.Gid,
+		Mtime:      timespecToTime(sysStat.Mtim),
+		Ctime:      timespecToTime(sysStat.Ctim),
+		Atime:      timespecToTime(sysStat.Atim),
+		IsDir:      fileInfo.IsDir(),
+		IsSymlink:  fileInfo.Mode()&os.ModeSymlink != 0,
+	}, nil
+}
+
+// Function to determine the color and attribute based on the file mode
+func getColorAndAttr(mode os.FileMode) (string, string) {
+	switch {
+	case mode.IsDir():
+		return ccmd.FgBlue, ccmd.Bold
+	case mode&os.ModeSymlink != 0:
+		return ccmd.FgCyan, ccmd.Bold
+	case mode&os.ModeNamedPipe != 0:
+		return ccmd.FgYellow, ccmd.Bold
+	case mode&os.ModeSocket != 0:
+		return ccmd.FgGreen, ccmd.Bold
+	case mode&os.ModeCharDevice != 0:
+		return ccmd.FgYellow, ccmd.Bold
+	case mode&os.ModeDevice != 0:
+		return ccmd.FgYellow, ccmd.Bold
+	case mode&os.ModeSetuid != 0:
+		return ccmd.FgRed, ccmd.Bold
+	case mode&os.ModeSetgid != 0:
+		return ccmd.FgRed, ccmd.Bold
+	case mode&os.ModeSticky != 0:
+		return ccmd.FgRed, ccmd.Bold
+	case mode.IsRegular() && mode&0111 != 0:
+		return ccmd.FgGreen, ccmd.Bold
+	default:
+		return ccmd.FgDefault, ccmd.Bold
+	}
+}
+
+// Function to handle color options
+func getColorizedOutput(fd FileData, color *ccmd.Color) string {
+	fg, attr := getColorAndAttr(fd.Mode)
+	return color.Paint(filepath.Base(fd.Path), attr, fg) + "\n"
+}
+
+// isCharDevice reports whether r is a terminal or another character device, fin only reads
+// names from stdin when it isn't.
+func isCharDevice(r io.Reader) (bool, error) {
+	f, ok := r.(*os.File)
+	if !ok {
+		return false, nil
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+	return stat.Mode()&os.ModeCharDevice != 0, nil
+}
+
+func init() {
+	ccmd.Register("fin", Main)
+}
+
+// Main runs fin, see ccmd.MainFunc.
+func Main(args []string, stdio ccmd.Stdio) int {
+	fs := ccmd.NewFlagSet("fin", flag.ContinueOnError)
+	showAll := fs.Bool("a", false, "Include names starting with .")
+	showAlmostAll := fs.Bool("A", false, "Like -a, but exclude . and ..")
+	appendSlash := fs.Bool("p", false, "Append / to directory names")
+	appendIndicator := fs.Bool("F", false, "Append indicator (one of */=@|) to names")
+	longFormat := fs.Bool("l", false, "Long format")
+	showInode := fs.Bool("i", false, "Show inode numbers")
+	showNumeric := fs.Bool("n", false, "Show numeric UIDs and GIDs instead of names")
+	showBlocks := fs.Bool("s", false, "Show allocated blocks")
+	showCtime := fs.Bool("c", false, "Show ctime (-lc)")
+	showAtime := fs.Bool("u", false, "Show atime (-lu)")
+	fullTime := fs.Bool("full-time", false, "List full date/time")
+	humanReadable := fs.Bool("h", false, "Human readable sizes (1K 243M 2G)")
+	colorMode := ccmd.ColorNever
+	fs.Var(&colorMode, "color", "Colorize the output: 'always', 'auto', 'never'")
+	fs.Extension("color")
+	fs.Extension("full-time")
+	format := ccmd.FormatText
+	fs.Var(&format, "format", "Output format: 'text', 'json', 'ndjson', 'tsv'")
+
+	cmdInfo := &ccmd.CmdInfo{
+		Name:        "fin",
+		Authors:     []string{"as", "xplshn"},
+		Repository:  "https://github.com/xplshn/a-utils",
+		Description: "Prints file information for files read from stdin or arguments",
+		Synopsis:    "[-aAipFlcunsh] [--full-time] [--color=auto|always|never] [--format=FORMAT] [file1 [file2 ...]]",
+		Examples: []ccmd.Example{
+			{Description: "Print file sizes and cumulative total", Command: "walk -f mink/ | fin -s"},
+			{Description: "Print the long format along with ctime", Command: "fin -lc file1 file2"},
+			{Description: "Print the size of every file, as TSV", Command: "walk -f mink/ | fin --format=tsv"},
+		},
+		Behavior: "With --format=json|ndjson|tsv, every field is printed; the flags only affect the text output.",
+		SeeAlso:  []string{"walk(1)"},
+		Fields: []ccmd.Field{
+			{Name: "path", Description: "Path of the file, as given"},
+			{Name: "name", Description: "Base name of the file"},
+			{Name: "mode", Description: "Type and permissions, e.g: \"drwxr-xr-x\""},
+			{Name: "inode", Description: "Inode number"},
+			{Name: "blocks", Description: "Allocated 512-byte blocks"},
+			{Name: "size", Description: "Size in bytes"},
+			{Name: "nlink", Description: "Number of hard links"},
+			{Name: "uid", Description: "Numeric user ID of the owner"},
+			{Name: "gid", Description: "Numeric group ID of the owner"},
+			{Name: "mtime", Description: "Modification time, RFC 3339"},
+			{Name: "ctime", Description: "Status change time, RFC 3339"},
+			{Name: "atime", Description: "Access time, RFC 3339"},
+			{Name: "dir", Description: "Whether the file is a directory"},
+			{Name: "symlink", Description: "Whether the file is a symbolic link"},
+		},
+		Flags: fs,
+	}
+	helpPage, err := cmdInfo.GenerateHelpPage()
+	if err != nil {
+		ccmd.Fatalf("generating help page: %v", err)
+	}
+	// Set usage to print the CCMD-generated help page
+	fs.Usage = func() {
+		fmt.Fprint(stdio.Out, helpPage)
+	}
+
+	// Parse flags
+	cmdInfo.ParseArgs(args[1:])
+
+	color := stdio.Color(colorMode)
+
+	var printFuncs []func(FileData) string
+
+	// Build the list of printing functions based on the flags
+	printFuncs = append(printFuncs, func(fd FileData) string {
+		return getColorizedOutput(fd, color)
+	})
+
+	if *showInode {
+		printFuncs = append(printFuncs, func(fd FileData) string {
+			return fmt.Sprintf("%d\t", fd.Inode)
+		})
+	}
+	if *showBlocks {
+		printFuncs = append(printFuncs, func(fd FileData) string {
+			return fmt.Sprintf("%d\t", fd.Blocks)
+		})
+	}
+	if *longFormat {
+		printFuncs = append(printFuncs, func(fd FileData) string {
+			perm := fd.Mode.Perm().String()
+			if fd.IsDir {
+				perm = "d" + perm
+			}
+			if *showNumeric {
+				return fmt.Sprintf("%s %d %d %d %d %s\t", perm, fd.Nlink, fd.UID, fd.GID, fd.Size, fd.Mtime.Format(time.RFC3339))
+			} else {
+				return fmt.Sprintf("%s %d %d %d %d %s\t", perm, fd.Nlink, fd.UID, fd.GID, fd.Size, fd.Mtime.Format(time.RFC3339))
+			}
+		})
+	}
+	if *showCtime {
+		printFuncs = append(printFuncs, func(fd FileData) string {
+			return fmt.Sprintf("%s\t", fd.Ctime.Format(time.RFC3339))
+		})
+	}
+	if *showAtime {
+		printFuncs = append(printFuncs, func(fd FileData) string {
+			return fmt.Sprintf("%s\t", fd.Atime.Format(time.RFC3339))
+		})
+	}
+	if *fullTime {
+		printFuncs = append(printFuncs, func(fd FileData) string {
+			return fmt.Sprintf("%s\t", fd.Mtime.Format(time.RFC3339))
+		})
+	}
+	if *humanReadable {
+		printFuncs = append(printFuncs, func(fd FileData) string {
+			return fmt.Sprintf("%s\t", humanReadableSize(fd.Size))
+		})
+	}
+	if *appendSlash {
+		printFuncs = append(printFuncs, func(fd FileData) string {
+			if fd.IsDir {
+				return "/"
+			}
+			return ""
+		})
+	}
+	if *appendIndicator {
+		printFuncs = append(printFuncs, func(fd FileData) string {
+			if fd.IsDir {
+				return "/"
+			} else if fd.IsSymlink {
+				return "@"
+			} else if fd.Mode&os.ModeNamedPipe != 0 {
+				return "|"
+			} else if fd.Mode&os.ModeSocket != 0 {
+				return "="
+			} else if fd.Mode&os.ModeCharDevice != 0 {
+				return "*"
+			} else if fd.Mode&os.ModeDevice != 0 {
+				return "="
+			}
+			return ""
+		})
+	}
+
+	out := cmdInfo.NewOutput(stdio.Out, format)
+	emit := func(fd FileData) {
+		if format.Structured() {
+			out.Write(fileRecord(fd))
+			return
+		}
+		output := ""
+		for i := len(printFuncs) - 1; i >= 0; i-- {
+			output += printFuncs[i](fd)
+		}
+		fmt.Fprint(stdio.Out, output)
+	}
+
+	totalSize := int64(0)
+	status := ccmd.ExitSuccess
+
+	// Check if any arguments are provided
+	if len(fs.Args()) == 0 {
+		// Check if stdin is being used
+		tty, err := isCharDevice(stdio.In)
+		if err != nil {
+			ccmd.Fatalf("checking stdin: %v", err)
+		}
+		if tty {
+			// No arguments and stdin is not being used, show help page & exit
+			fmt.Fprint(stdio.Out, helpPage)
+			ccmd.Exitf(ccmd.ExitUsage, "no input files and stdin is not being used")
+		}
+	}
+
+	// Process each file from arguments
+	for _, fileName := range fs.Args() {
+		fileData, err := gatherFileData(fileName)
+		if err != nil {
+			ccmd.Warnf("%v", err)
+			status = ccmd.ExitCodeOf(err)
+			continue
+		}
+
+		if !*showAll && !*showAlmostAll && strings.HasPrefix(filepath.Base(fileName), ".") {
+			continue
+		}
+		if *showAlmostAll && (filepath.Base(fileName) == "." || filepath.Base(fileName) == "..") {
+			continue
+		}
+
+		emit(fileData)
+		totalSize += int64(fileData.Size)
+	}
+
+	// Check if stdin is being used
+	tty, err := isCharDevice(stdio.In)
+	if err != nil {
+		ccmd.Fatalf("checking stdin: %v", err)
+	}
+	if !tty {
+		// Process each file from stdin
+		scanner := bufio.NewScanner(stdio.In)
+		for scanner.Scan() {
+			fileName := scanner.Text()
+			fileData, err := gatherFileData(fileName)
+			if err != nil {
+				ccmd.Warnf("%v", err)
+				status = ccmd.ExitCodeOf(err)
+				continue
+			}
+
+			if !*showAll && !*showAlmostAll && strings.HasPrefix(filepath.Base(fileName), ".") {
+				continue
+			}
+			if *showAlmostAll && (filepath.Base(fileName) == "." || filepath.Base(fileName) == "..") {
+				continue
+			}
+
+			emit(fileData)
+			totalSize += int64(fileData.Size)
+		}
+		if err := scanner.Err(); err != nil {
+			ccmd.Warnf("%v", err)
+			status = ccmd.ExitCodeOf(err)
+		}
+	}
+
+	out.Close()
+
+	// Print cumulative total if the flag is set
+	if *showBlocks && !format.Structured() {
+		fmt.Fprintf(stdio.Out, "%d total\n", totalSize)
+	}
+	return int(status)
+}
+
+// humanReadableSize converts a size in bytes to a human-readable format.
+func humanReadableSize(size uint64) string {
+	const (
+		KB = 1024
+		MB = KB * 1024
+		GB = MB * 1024
+	)
+
+	switch {
+	case size >= GB:
+		return fmt.Sprintf("%.2f GB", float64(size)/GB)
+	case size >= MB:
+		return fmt.Sprintf("%.2f MB", float64(size)/MB)
+	case size >= KB:
+		return fmt.Sprintf("%.2f KB", float64(size)/KB)
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
+
+// timespecToTime converts syscall.Timespec to time.Time
+func timespecToTime(ts syscall.Timespec) time.Time {
+	return time.Unix(int64(ts.Sec), int64(ts.Nsec))
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2024, xplshn [3BSD]
+// For more details refer to https://github.com/xplshn/a-utils
+package fortune
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/liamg/tml"
+	"github.com/xplshn/a-utils/pkg/ccmd"
+)
+
+/* | fortune is a minimalistic implementation of the `fortune` program we all know
+   | This version does not use an index file. Instead, it loads the entire fortune file into memory,
+   | parses it, and randomly selects a fortune.
+*/
+
+// Version of the fortune program
+const Version = "2.1"
+
+// readFortuneFile reads and parses the fortune file into an array of fortunes
+func readFortuneFile(fortuneFile string) ([]string, error) {
+	content, err := ioutil.ReadFile(fortuneFile)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(string(content), "\n%\n"), nil
+}
+
+// findAndPrint selects and prints a random fortune from the given file
+func findAndPrint(w io.Writer, fortuneFile string) error {
+	fortunes, err := readFortuneFile(fortuneFile)
+	if err != nil {
+		return err
+	}
+	rand.Seed(time.Now().UTC().UnixNano())
+	fortune := fortunes[rand.Intn(len(fortunes))]
+	fmt.Fprintln(w, tml.Sprintf(fortune))
+	return nil
+}
+
+// getRandomFortuneFile selects a random fortune file from the directories in the specified path
+func getRandomFortuneFile(fortunePath string) (string, error) {
+	paths := strings.Split(fortunePath, ":")
+	var files []string
+
+	for _, dir := range paths {
+		dirFiles, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, file := range dirFiles {
+			if !file.IsDir() {
+				files = append(files, filepath.Join(dir, file.Name()))
+			}
+		}
+	}
+
+	if len(files) == 0 {
+		return "", fmt.Errorf("no valid fortune files found in the specified paths")
+	}
+
+	rand.Seed(time.Now().UTC().UnixNano())
+	return files[rand.Intn(len(files))], nil
+}
+
+func init() {
+	ccmd.Register("fortune", Main)
+}
+
+// Main runs fortune, see ccmd.MainFunc.
+func Main(args []string, stdio ccmd.Stdio) int {
+	fs := ccmd.NewFlagSet("fortune", flag.ContinueOnError)
+	cmdInfo := ccmd.CmdInfo{
+		Authors:     []string{"xplshn"},
+		Repository:  "https://github.com/xplshn/a-utils",
+		Name:        "fortune",
+		Usage:       "<--file|--path|--version>",
+		Description: "Provide a quote from a \"cookie file\"",
+		Behavior:    "If no directory or file is provided, fortune uses the FORTUNE_FILE or FORTUNE_PATH environment variables. It will fail if neither arguments nor these variables are set.",
+		EnvVars: []ccmd.EnvVar{
+			{Name: "FORTUNE_FILE", Description: "Fortune file to use when neither --file nor --path is given"},
+			{Name: "FORTUNE_PATH", Description: "Directory of fortune files to pick from when neither --file nor --path is given"},
+		},
+		Completions: map[string]ccmd.Completion{
+			"file": {Files: true},
+			"path": {Dirs: true},
+		},
+		Flags: fs,
+	}
+
+	helpPage, err := cmdInfo.GenerateHelpPage()
+	if err != nil {
+		ccmd.Fatalf("generating help page: %v", err)
+	}
+
+	fs.Usage = func() {
+		fmt.Fprint(stdio.Out, helpPage)
+	}
+
+	// Define flags
+	fortuneFile := fs.String("file", "", "Path to the fortune file")
+	fortunePath := fs.String("path", "", "Colon-separated list of directories containing fortune files")
+	displayVersion := fs.Bool("version", false, "Display the version of this implementation")
+
+	// Parse command-line arguments
+	cmdInfo.ParseArgs(args[1:])
+
+	// Markup is only rendered when the output can be colored
+	if !stdio.Color(ccmd.ColorAuto).Enabled() {
+		tml.DisableFormatting()
+	}
+
+	// Check for version flag
+	if *displayVersion {
+		fmt.Fprintln(stdio.Out, "a-utils's Fortune implementation is currently at version:", Version)
+		return int(ccmd.ExitSuccess)
+	}
+
+	// Determine fortune file to use
+	if *fortuneFile != "" && *fortunePath != "" {
+		ccmd.Exitf(ccmd.ExitUsage, "cannot use both --file and --path options at the same time")
+	}
+
+	if *fortuneFile == "" && *fortunePath == "" {
+		*fortuneFile = os.Getenv("FORTUNE_FILE")
+		*fortunePath = os.Getenv("FORTUNE_PATH")
+	}
+
+	if *fortuneFile != "" {
+		err := findAndPrint(stdio.Out, *fortuneFile)
+		if err != nil {
+			ccmd.Fatalf("%v", err)
+		}
+		return 0
+	}
+
+	if *fortunePath != "" {
+		file, err := getRandomFortuneFile(*fortunePath)
+		if err != nil {
+			ccmd.Fatalf("%v", err)
+		}
+		err = findAndPrint(stdio.Out, file)
+		if err != nil {
+			ccmd.Fatalf("%v", err)
+		}
+		return 0
+	}
+
+	ccmd.Warnf("no fortune file specified and no FORTUNE_FILE or FORTUNE_PATH environment variable set")
+	return int(ccmd.ExitUsage)
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2024-2024 xplshn						[3BSD]
+// For more details refer to https://github.com/xplshn/a-utils
+package hpwd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/xplshn/a-utils/pkg/ccmd"
+)
+
+func init() {
+	ccmd.Register("hpwd", Main)
+}
+
+// Main runs hpwd, see ccmd.MainFunc.
+func Main(args []string, stdio ccmd.Stdio) int {
+	fs := ccmd.NewFlagSet("hpwd", flag.ContinueOnError)
+	cmdInfo := ccmd.CmdInfo{
+		Authors:     []string{"xplshn"},
+		Repository:  "https://github.com/xplshn/a-utils",
+		Name:        "hpwd",
+		Usage:       "<|-h>",
+		Description: "Stylized `pwd` command",
+		Behavior:    "If in the home directory, display '~' or the value of COOLHOME if set. If inside the home directory but not in the home itself, display the relative path prefixed with COOLHOME_DEPTH if set. Otherwise, display the full path.",
+		EnvVars: []ccmd.EnvVar{
+			{Name: "COOLHOME", Description: "Displayed instead of '~' in the home directory"},
+			{Name: "COOLHOME_DEPTH", Description: "Prefix of the relative path, when inside the home directory"},
+		},
+		Flags: fs,
+	}
+
+	helpPage, err := cmdInfo.GenerateHelpPage()
+	if err != nil {
+		ccmd.Fatalf("generating help page: %v", err)
+	}
+	fs.Usage = func() {
+		fmt.Fprint(stdio.Out, helpPage)
+	}
+	cmdInfo.ParseArgs(args[1:])
+
+	// Main logic of the command
+	pwd, err := os.Getwd()
+	if err != nil {
+		ccmd.Fatalf("getting current directory: %v", err)
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		ccmd.Fatalf("getting home directory: %v", err)
+	}
+
+	coolHome := os.Getenv("COOLHOME")
+	coolHomeDepth := os.Getenv("COOLHOME_DEPTH")
+
+	if pwd == home {
+		if coolHome != "" {
+			fmt.Fprint(stdio.Out, coolHome)
+		} else {
+			fmt.Fprint(stdio.Out, "~") // Display '~' if in home directory
+		}
+	} else if strings.HasPrefix(pwd, home+"/") { // Adjusted condition
+		// Calculate the relative path from home
+		relativePath := pwd[len(home)+1:]
+		if coolHomeDepth != "" {
+			fmt.Fprintf(stdio.Out, "%s/%s ", coolHomeDepth, relativePath)
+		} else {
+			fmt.Fprintf(stdio.Out, "~/%s ", relativePath) // Display '~/...' for directories inside home if COOLHOME_DEPTH is not set
+		}
+	} else {
+		fmt.Fprint(stdio.Out, pwd+" ") // Display the full path if outside home
+	}
+	return 0
+}
@@ -0,0 +1,71 @@
+package importenv
+
+import (
+	"flag"
+	"fmt"
+	"os/exec"
+	"strconv"
+
+	"github.com/shirou/gopsutil/v4/process"
+	"github.com/xplshn/a-utils/pkg/ccmd"
+)
+
+func init() {
+	ccmd.Register("importenv", Main)
+}
+
+// Main runs importenv, see ccmd.MainFunc.
+func Main(args []string, stdio ccmd.Stdio) int {
+	fs := ccmd.NewFlagSet("importenv", flag.ContinueOnError)
+	cmdInfo := &ccmd.CmdInfo{
+		Name:        "importenv",
+		Authors:     []string{"xplshn"},
+		Repository:  "https://github.com/xplshn/a-utils",
+		Description: "Run a given command, with the ENV of a PID",
+		Synopsis:    "[[PID] [COMMAND]]",
+		Flags:       fs,
+	}
+
+	helpPage, err := cmdInfo.GenerateHelpPage()
+	if err != nil {
+		ccmd.Fatalf("generating help page: %v", err)
+	}
+	fs.Usage = func() {
+		fmt.Fprint(stdio.Out, helpPage)
+	}
+
+	cmdInfo.ParseArgs(args[1:])
+
+	args = fs.Args()
+
+	if len(args) < 2 {
+		fs.Usage()
+		return int(ccmd.ExitUsage)
+	}
+
+	pid, err := strconv.Atoi(args[0])
+	if err != nil {
+		ccmd.Exitf(ccmd.ExitUsage, "invalid PID: %v", err)
+	}
+
+	command := args[1]
+	args = args[2:]
+
+	proc, err := process.NewProcess(int32(pid))
+	if err != nil {
+		ccmd.Fatalf("failed to get process: %v", err)
+	}
+
+	env, err := proc.Environ()
+	if err != nil {
+		ccmd.Fatalf("failed to get environment variables: %v", err)
+	}
+
+	cmd := exec.Command(command, args...)
+	cmd.Env = env
+
+	output, _ := cmd.CombinedOutput()
+
+	fmt.Fprint(stdio.Out, string(output))
+	return 0
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2024-2024 xplshn                       [3BSD]
+// For more details refer to https://github.com/xplshn/a-utils
+package isainfo
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+
+	"github.com/shirou/gopsutil/v4/cpu"
+	"github.com/xplshn/a-utils/pkg/ccmd"
+)
+
+// labels are the names under which fields are printed in the text output.
+var labels = map[string]string{
+	"bits":        "Bits",
+	"model":       "Instruction Set",
+	"flags":       "Flags",
+	"vendor":      "Vendor",
+	"cores":       "Cores",
+	"mhz":         "MHz",
+	"isa_version": "ISA Version",
+}
+
+// fields documents the output of isainfo, in the order its lines are printed.
+var fields = []ccmd.Field{
+	{Name: "bits", Description: "Number of bits of the architecture (32 or 64), or \"unknown\""},
+	{Name: "model", Description: "Instruction set (model name)"},
+	{Name: "flags", Description: "CPU flags; a list in JSON, space-separated otherwise"},
+	{Name: "vendor", Description: "CPU vendor ID"},
+	{Name: "cores", Description: "Number of CPU cores"},
+	{Name: "mhz", Description: "CPU clock speed, in MHz"},
+	{Name: "isa_version", Description: "x86-64 microarchitecture level (x86-64-v1 to v4), or \"unknown\""},
+}
+
+// displayCPUInfo prints CPU info based on the selected options.
+func displayCPUInfo(out *ccmd.Output, showBits, showInstSet, showFlags, showVendor, showCores, showMhz, showISAVersion bool) {
+	cpuInfo, err := cpu.Info()
+	if err != nil {
+		ccmd.Fatalf("retrieving CPU info: %v", err)
+	}
+
+	// Use information from the first CPU (since all cores are typically the same)
+	info := cpuInfo[0]
+	record := ccmd.Record{}
+
+	if showBits {
+		bits := "unknown"
+		if runtime.GOARCH == "amd64" || runtime.GOARCH == "arm64" {
+			bits = "64"
+		} else if runtime.GOARCH == "386" || runtime.GOARCH == "arm" {
+			bits = "32"
+		}
+		record["bits"] = bits
+	}
+
+	if showInstSet {
+		record["model"] = info.ModelName
+	}
+
+	if showFlags {
+		record["flags"] = info.Flags
+	}
+
+	if showVendor {
+		record["vendor"] = info.VendorID
+	}
+
+	if showCores {
+		// Print total number of cores across all CPUs
+		totalCores, err := cpu.Counts(true)
+		if err != nil {
+			ccmd.Fatalf("retrieving CPU cores count: %v", err)
+		}
+		record["cores"] = totalCores
+	}
+
+	if showMhz {
+		record["mhz"] = info.Mhz
+	}
+
+	if showISAVersion {
+		record["isa_version"] = determineISAVersion(info.Flags)
+	}
+
+	out.Write(record)
+}
+
+// printText prints one "Label: value" line per field of the record.
+func printText(w io.Writer, r ccmd.Record) error {
+	for _, f := range fields {
+		value, ok := r[f.Name]
+		if !ok {
+			continue
+		}
+		switch v := value.(type) {
+		case []string:
+			value = strings.Join(v, " ")
+		case float64:
+			value = fmt.Sprintf("%.2f", v)
+		}
+		if _, err := fmt.Fprintf(w, "%s: %v\n", labels[f.Name], value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// determineISAVersion checks the CPU flags and returns the ISA version.
+func determineISAVersion(flags []string) string {
+	flagSet := make(map[string]bool)
+	for _, flag := range flags {
+		flagSet[flag] = true
+	}
+
+	if hasFlags(flagSet, "avx512f", "avx512bw", "avx512cd", "avx512dq", "avx512vl") {
+		return "x86-64-v4"
+	}
+	if hasFlags(flagSet, "avx", "avx2", "bmi1", "bmi2", "f16c", "fma", "abm", "movbe", "xsave") {
+		return "x86-64-v3"
+	}
+	if hasFlags(flagSet, "cx16", "lahf_lm", "popcnt", "sse4_1", "sse4_2", "ssse3") {
+		return "x86-64-v2"
+	}
+	if hasFlags(flagSet, "lm", "cmov", "cx8", "fpu", "fxsr", "mmx", "syscall", "sse2") {
+		return "x86-64-v1"
+	}
+	return "unknown"
+}
+
+// hasFlags checks if all the given flags are present in the flag set.
+func hasFlags(flagSet map[string]bool, flags ...string) bool {
+	for _, flag := range flags {
+		if !flagSet[flag] {
+			return false
+		}
+	}
+	return true
+}
+
+// printHelp prints a custom help message for invalid options.
+func printHelp(option string) {
+	fmt.Printf("isainfo: illegal option -%s\nusage: isainfo [-b|-k|-x|-v|-c|-m|-i]\n", option)
+}
+
+// main function processes command-line arguments and displays CPU information based on user options.
+func init() {
+	ccmd.Register("isainfo", Main)
+}
+
+// Main runs isainfo, see ccmd.MainFunc.
+func Main(args []string, stdio ccmd.Stdio) int {
+	fs := ccmd.NewFlagSet("isainfo", flag.ContinueOnError)
+	bitsFlag := fs.Bool("b", false, "Print the number of bits (32 or 64)")
+	instSetFlag := fs.Bool("k", false, "Print the instruction set (model name)")
+	flagsFlag := fs.Bool("x", false, "Print the CPU flags")
+	vendorFlag := fs.Bool("v", false, "Print the CPU vendor ID")
+	coresFlag := fs.Bool("c", false, "Print the number of CPU cores")
+	mhzFlag := fs.Bool("m", false, "Print the CPU clock speed (MHz)")
+	isaVersionFlag := fs.Bool("i", false, "Print the ISA version")
+	format := ccmd.FormatText
+	fs.Var(&format, "format", "Output format: 'text', 'json', 'ndjson', 'tsv'")
+
+	cmdInfo := &ccmd.CmdInfo{
+		Authors:     []string{"xplshn"},
+		Repository:  "https://github.com/xplshn/a-utils",
+		Name:        "isainfo",
+		Synopsis:    "<|-b|-k|-x|-v|-c|-m|-iv|>",
+		Description: "Prints detailed CPU architecture and flags.",
+		Fields:      fields,
+		Behavior:    "With --format=json|ndjson|tsv and no other option, every field is printed.",
+		Flags:       fs,
+	}
+
+	helpPage, err := cmdInfo.GenerateHelpPage()
+	if err != nil {
+		ccmd.Fatalf("generating help page: %v", err)
+	}
+
+	fs.Usage = func() { fmt.Fprint(stdio.Out, helpPage) }
+	cmdInfo.ParseArgs(args[1:])
+
+	// If no flags are provided, print usage, or every field for structured output
+	if !*bitsFlag && !*instSetFlag && !*flagsFlag && !*vendorFlag && !*coresFlag && !*mhzFlag && !*isaVersionFlag {
+		if !format.Structured() {
+			fs.Usage()
+			return int(ccmd.ExitSuccess)
+		}
+		*bitsFlag, *instSetFlag, *flagsFlag, *vendorFlag, *coresFlag, *mhzFlag, *isaVersionFlag = true, true, true, true, true, true, true
+	}
+
+	out := cmdInfo.NewOutput(stdio.Out, format)
+	out.Text = printText
+	defer out.Close()
+
+	// Display the CPU information based on flags
+	displayCPUInfo(out, *bitsFlag, *instSetFlag, *flagsFlag, *vendorFlag, *coresFlag, *mhzFlag, *isaVersionFlag)
+	return 0
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2024-2024 xplshn						[3BSD]
+// For more details refer to https://github.com/xplshn/a-utils
+package issue
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"syscall"
+	"time"
+
+	"github.com/liamg/tml"
+	"github.com/xplshn/a-utils/pkg/ccmd"
+)
+
+// Define functions to generate dynamic replacements
+func getOSName() string {
+	return os.Getenv("GOOS")
+}
+
+func getHostName() string {
+	hostName, err := os.Hostname()
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	return hostName
+}
+
+func getCurrentTTYorTERM() string {
+	determineIfTerm := func() string {
+		// Attempt to use tput to get the terminal type
+		if _, err := exec.Command("tput", "-T", "try", "setaf", "0").Output(); err == nil {
+			cmd := exec.Command("tput", "term")
+			output, err := cmd.Output()
+			if err != nil {
+				return ""
+			}
+			return fmt.Sprintf("%s", output)
+		}
+		// Fallback to $TERM environment variable
+		return os.Getenv("TERM")
+	}
+	determineIfTTY := func(stdout io.Writer) string {
+		fi, err := os.Stdin.Stat()
+		if err != nil {
+			return ""
+		}
+
+		s, ok := fi.Sys().(*syscall.Stat_t)
+		if !ok {
+			return ""
+		}
+
+		var ttyPath string
+		filepath.WalkDir("/dev", func(path string, dir os.DirEntry, _ error) error {
+			if dir.IsDir() {
+				return nil
+			}
+
+			if fi, err := os.Stat(path); err == nil {
+				stat, ok := fi.Sys().(*syscall.Stat_t)
+				if ok {
+					if stat.Ino == s.Ino && stat.Dev == s.Dev {
+						ttyPath = path
+					}
+				}
+			}
+			return nil
+		})
+		return ttyPath
+	}
+
+	currentTTY := determineIfTTY(os.Stdout)
+	if currentTTY != "" {
+		return currentTTY
+	}
+	return determineIfTerm()
+}
+
+func getCurrentTime() string {
+	currentTime := time.Now().Format("15:04:05")
+	return currentTime
+}
+func getCurrentDate() string {
+	// Get the current time
+	currentDate := time.Now().Format("Monday, 02 Jan 2006")
+	return currentDate
+}
+func getWorkingDirectory() string {
+	workingDir, err := os.Getwd()
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	return workingDir
+}
+func getNumberOfCPUs() string {
+	return fmt.Sprintf("%d", runtime.NumCPU())
+}
+
+// Define a map to hold the sequence patterns and their replacements
+var issueSequences = map[string]string{
+	"\\l": getCurrentTTYorTERM(),
+	"\\t": getCurrentTime(),
+	"\\d": getCurrentDate(),
+	"\\H": getHostName(),
+	"\\w": getWorkingDirectory(),
+	"\\c": getNumberOfCPUs(),
+	"\\n": "\n",
+}
+
+func init() {
+	ccmd.Register("issue", Main)
+}
+
+// Main runs issue, see ccmd.MainFunc.
+func Main(args []string, stdio ccmd.Stdio) int {
+	fs := ccmd.NewFlagSet("issue", flag.ContinueOnError)
+	cmdInfo := &ccmd.CmdInfo{
+		Authors:     []string{"xplshn"},
+		Repository:  "https://github.com/xplshn/a-utils",
+		Name:        "issue",
+		Synopsis:    "[FILE]",
+		Description: "Prints an issue file (/etc/issue by default), expanding its escape sequences",
+		Behavior: `The following sequences are replaced:
+  \l: the current TTY or TERM    \t: the current time
+  \d: the current date           \H: the hostname
+  \w: the working directory      \c: the number of CPUs
+  \n: a newline`,
+		Flags: fs,
+	}
+
+	helpPage, err := cmdInfo.GenerateHelpPage()
+	if err != nil {
+		ccmd.Fatalf("generating help page: %v", err)
+	}
+	fs.Usage = func() {
+		fmt.Fprint(stdio.Out, helpPage)
+	}
+	cmdInfo.ParseArgs(args[1:])
+
+	// Markup is only rendered when the output can be colored
+	if !stdio.Color(ccmd.ColorAuto).Enabled() {
+		tml.DisableFormatting()
+	}
+
+	// Check if there is a command-line argument
+	args = fs.Args()
+	var filePath string
+	if len(args) > 0 {
+		filePath = args[0]
+	} else {
+		filePath = "/etc/issue"
+	}
+
+	// Open the file
+	file, err := os.Open(filePath)
+	if err != nil {
+		ccmd.Fatalf("%v", err)
+	}
+	defer file.Close()
+
+	// Create a scanner to read the file line by line
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		// Replace special sequences with actual values
+		for seq, replacement := range issueSequences {
+			// Use raw string literals for regex patterns
+			regexPattern := regexp.QuoteMeta(seq)
+			regex, err := regexp.Compile(regexPattern)
+			if err != nil {
+				ccmd.Warnf("ignoring unknown sequence '%s': %v", seq, err)
+				continue
+			}
+			line = regex.ReplaceAllString(line, replacement)
+		}
+		fmt.Fprintln(stdio.Out, tml.Sprintf(line))
+	}
+
+	if err := scanner.Err(); err != nil {
+		ccmd.Fatalf("reading %s: %v", filePath, err)
+	}
+	return 0
+}
@@ -0,0 +1,82 @@
+// Copyright 2009-2018 the u-root Authors               [3BSD]
+// Copyright 2024 xplshn
+// For more details refer to https://github.com/xplshn/a-utils
+package lddfiles
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+
+	"github.com/u-root/u-root/pkg/ldd"
+	"github.com/xplshn/a-utils/pkg/ccmd"
+)
+
+func init() {
+	ccmd.Register("lddfiles", Main)
+}
+
+// Main runs lddfiles, see ccmd.MainFunc.
+func Main(args []string, stdio ccmd.Stdio) int {
+	fs := ccmd.NewFlagSet("lddfiles", flag.ContinueOnError)
+	// Setup CCMD library for help and command information
+	cmdInfo := &ccmd.CmdInfo{
+		Authors:     []string{"u-root", "xplshn"},
+		Repository:  "https://github.com/xplshn/a-utils",
+		Name:        "lddfiles",
+		Synopsis:    "[FILE/s]",
+		Description: "Display all dynamic dependencies of the provided files using the  u-root ldd package.",
+		Fields: []ccmd.Field{
+			{Name: "path", Description: "Absolute path of a dependency, or of one of the files given"},
+		},
+		Flags: fs,
+	}
+
+	format := ccmd.FormatText
+	fs.Var(&format, "format", "Output format: 'text', 'json', 'ndjson', 'tsv'")
+
+	helpPage, err := cmdInfo.GenerateHelpPage()
+	if err != nil {
+		ccmd.Fatalf("generating help page: %v", err)
+	}
+
+	fs.Usage = func() { fmt.Fprint(stdio.Out, helpPage) }
+
+	cmdInfo.ParseArgs(args[1:])
+
+	args = fs.Args()
+	if len(args) == 0 {
+		fs.Usage()
+		ccmd.Exitf(ccmd.ExitUsage, "no files provided")
+	}
+
+	out := cmdInfo.NewOutput(stdio.Out, format)
+	if err := run(out, args); err != nil {
+		ccmd.Fatalf("%v", err)
+	}
+	out.Close()
+	return 0
+}
+
+func run(out *ccmd.Output, args []string) error {
+	l, err := ldd.FList(args...)
+	if err != nil {
+		return fmt.Errorf("ldd: %w", err)
+	}
+
+	for _, p := range args {
+		a, err := filepath.Abs(p)
+		if err != nil {
+			return fmt.Errorf("ldd: %w", err)
+		}
+		l = append(l, a)
+	}
+
+	for _, dep := range l {
+		if err := out.Write(ccmd.Record{"path": dep}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
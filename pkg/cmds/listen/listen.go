@@ -0,0 +1,269 @@
+// Copyright (c) as 2016, 2024-2024 xplshn				[3BSD]
+// For more details refer to https://github.com/xplshn/a-utils
+package listen
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os/exec"
+
+	"github.com/xplshn/a-utils/pkg/ccmd"
+)
+
+const (
+	Debug = false
+)
+
+func init() {
+	ccmd.Register("listen", Main)
+}
+
+// Main runs listen, see ccmd.MainFunc.
+func Main(args []string, stdio ccmd.Stdio) int {
+	fs := ccmd.NewFlagSet("listen", flag.ContinueOnError)
+	cmdInfo := &ccmd.CmdInfo{
+		Name:        "listen",
+		Authors:     []string{"as", "xplshn"},
+		Repository:  "https://github.com/as/listen",
+		Description: "Listen on a network port and optionally run a command for each new connection.",
+		Synopsis:    "listen [options] port [cmd ...]",
+		Examples: []ccmd.Example{
+			{Description: "Serve index.html over HTTP", Command: "listen :80 cat index.html"},
+			{Description: "Forward connections to google.com", Command: "listen :80 dial google.com:80"},
+		},
+		SeeAlso: []string{"dial(1)"},
+		Flags:   fs,
+	}
+
+	showHelp := fs.Bool("h", false, "Show help")
+	verbose := fs.Bool("v", false, "Verbose output")
+	keepAlive := fs.Int("k", 0, "Terminate after n calls")
+	muxMode := fs.Bool("m", false, "Mux: broadcast traffic to all clients")
+	activeLimit := fs.Int("a", 4096, "Active connection limit")
+	protocol := fs.String("n", "tcp4", "Network protocol")
+
+	helpPage, err := cmdInfo.GenerateHelpPage()
+	if err != nil {
+		ccmd.Fatalf("generating help page: %v", err)
+	}
+
+	fs.Usage = func() {
+		fmt.Fprint(stdio.Out, helpPage)
+	}
+
+	cmdInfo.ParseArgs(args[1:])
+
+	if *showHelp {
+		fs.Usage()
+		return int(ccmd.ExitSuccess)
+	}
+	if len(fs.Args()) == 0 {
+		fs.Usage()
+		return int(ccmd.ExitUsage)
+	}
+
+	server := fs.Args()[0]
+	command := fs.Args()[1:]
+
+	listener, err := net.Listen(*protocol, server)
+	if err != nil {
+		handleFatalError(err)
+	}
+	// An interrupt stops accepting connections, commands run for them are killed; so does
+	// reaching the number of calls of -k
+	ctx, stop := context.WithCancel(ccmd.Context())
+	defer stop()
+	context.AfterFunc(ctx, func() { listener.Close() })
+
+	logVerbose(stdio.Err, *verbose, "announce:", listener.Addr())
+
+	if *muxMode {
+		handleMultiplexedStream(ctx, stop, stdio, listener, *activeLimit, *keepAlive, command...)
+	} else {
+		handleStream(ctx, stop, stdio, listener, *activeLimit, *keepAlive, command...)
+	}
+	return int(ccmd.ExitCodeOf(context.Cause(ccmd.Context())))
+}
+
+func handleStream(ctx context.Context, stop context.CancelFunc, stdio ccmd.Stdio, listener net.Listener, limit, keepAlive int, command ...string) {
+	concurrencyLimit := make(chan bool, limit)
+	callCount := 0
+
+	for {
+		select {
+		case concurrencyLimit <- true:
+		case <-ctx.Done():
+			return
+		}
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			ccmd.Warnf("%v", err)
+			<-concurrencyLimit
+			continue
+		}
+
+		go func() {
+			defer func() { <-concurrencyLimit }()
+			defer conn.Close()
+
+			if len(command) == 0 {
+				if err := handleTerminalIO(stdio, conn); err != nil {
+					ccmd.Warnf("%v", err)
+				}
+			} else if err := executeCommand(ctx, conn, command[0], command[1:]...); err != nil {
+				ccmd.Warnf("%v", err)
+			}
+
+			// Increment and check keepAlive condition
+			if keepAlive > 0 {
+				callCount++
+				if callCount >= keepAlive {
+					fmt.Fprintln(stdio.Out, "Terminating after", keepAlive, "calls")
+					stop()
+				}
+			}
+		}()
+	}
+}
+
+func handleMultiplexedStream(ctx context.Context, stop context.CancelFunc, stdio ccmd.Stdio, listener net.Listener, limit, keepAlive int, command ...string) {
+	concurrencyLimit := make(chan bool, limit)
+	connChan := make(chan io.ReadWriter)
+	callCount := 0
+
+	go multiplexer(connChan)
+
+	for {
+		select {
+		case concurrencyLimit <- true:
+		case <-ctx.Done():
+			return
+		}
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			ccmd.Warnf("%v", err)
+			<-concurrencyLimit
+			continue
+		}
+
+		go func() {
+			defer func() { <-concurrencyLimit }()
+			defer conn.Close()
+
+			connChan <- conn
+			muxedConn := <-connChan
+
+			if len(command) == 0 {
+				if err := handleTerminalIO(stdio, muxedConn.(net.Conn)); err != nil {
+					ccmd.Warnf("%v", err)
+				}
+			} else if err := executeCommand(ctx, muxedConn, command[0], command[1:]...); err != nil {
+				ccmd.Warnf("%v", err)
+			}
+
+			// Increment and check keepAlive condition
+			if keepAlive > 0 {
+				callCount++
+				if callCount >= keepAlive {
+					fmt.Fprintln(stdio.Out, "Terminating after", keepAlive, "calls")
+					stop()
+				}
+			}
+		}()
+	}
+}
+
+func multiplexer(connChan chan io.ReadWriter) {
+	writers := []io.Writer{}
+	readers := []io.Reader{}
+
+	for conn := range connChan {
+		writers = append(writers, conn)
+		readers = append(readers, conn)
+
+		multiReader := io.MultiReader(readers...)
+		multiWriter := io.MultiWriter(writers...)
+
+		connChan <- bufio.NewReadWriter(bufio.NewReader(multiReader), bufio.NewWriter(multiWriter))
+	}
+}
+
+func handleTerminalIO(stdio ccmd.Stdio, conn net.Conn) error {
+	finishChan := make(chan error)
+	defer close(finishChan)
+
+	go func() {
+		_, err := io.Copy(stdio.Out, conn)
+		finishChan <- err
+	}()
+
+	go func() {
+		_, err := io.Copy(conn, stdio.In)
+		if err != nil {
+			ccmd.Warnf("stdin|net: %v", err)
+		}
+	}()
+
+	return <-finishChan
+}
+
+func executeCommand(ctx context.Context, rw io.ReadWriter, cmd string, args ...string) error {
+	cmdExec := exec.CommandContext(ctx, cmd, args...)
+	stdin, err := cmdExec.StdinPipe()
+	if err != nil {
+		return err
+	}
+	pipeReader, pipeWriter := io.Pipe()
+	defer pipeWriter.Close()
+
+	cmdExec.Stdout, cmdExec.Stderr = pipeWriter, pipeWriter
+
+	if err := cmdExec.Start(); err != nil {
+		return err
+	}
+
+	finishChan := make(chan error)
+	defer close(finishChan)
+
+	go func() {
+		_, err := io.Copy(stdin, rw)
+		pipeWriter.Close()
+		finishChan <- err
+	}()
+
+	go func() {
+		_, err := io.Copy(rw, pipeReader)
+		if err != nil {
+			ccmd.Warnf("command|net: %v", err)
+		}
+	}()
+
+	if err := <-finishChan; err != nil {
+		ccmd.Warnf("net|command: %v", err)
+	}
+
+	stdin.Close()
+	return cmdExec.Wait()
+}
+
+func handleFatalError(err error) {
+	if err != nil {
+		ccmd.Fatalf("%v", err)
+	}
+}
+
+func logVerbose(w io.Writer, verbose bool, args ...interface{}) {
+	if verbose {
+		fmt.Fprintln(w, args...)
+	}
+}
@@ -0,0 +1,94 @@
+package ntpdate
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/xplshn/a-utils/pkg/ccmd"
+	"github.com/u-root/u-root/pkg/ntpdate"
+)
+
+const fallback = "pool.ntp.org"
+
+func init() {
+	ccmd.Register("ntpdate", Main)
+}
+
+// Main runs ntpdate, see ccmd.MainFunc.
+func Main(args []string, stdio ccmd.Stdio) int {
+	fs := ccmd.NewFlagSet("ntpdate", flag.ContinueOnError)
+	cmdInfo := &ccmd.CmdInfo{
+		Name:        "ntpdate",
+		Authors:     []string{"u-root Authors", "xplshn"},
+		Repository:  "https://github.com/xplshn/a-utils",
+		Description: "queries NTP server(s) for time and updates system time.",
+		Synopsis:    "<options> <server domain>",
+		Examples: []ccmd.Example{
+			{Description: "Update time and set hardware clock", Command: "ntpdate --rtc"},
+			{Description: "Use specific NTP server", Command: "ntpdate pool.ntp.org"},
+		},
+		Behavior: `ntpdate queries the provided NTP server(s) and adjusts the system time.
+If --rtc is specified, it updates the hardware clock (RTC) as well.
+By default, the servers are read from /etc/ntp.conf. If servers are passed on
+the command line, those are tried first. As a fallback, pool.ntp.org is used.`,
+		Completions: map[string]ccmd.Completion{
+			"config": {Files: true},
+		},
+		Flags: fs,
+	}
+
+	config := fs.String("config", ntpdate.DefaultNTPConfig, "NTP config file")
+	setRTC := fs.Bool("rtc", false, "Set RTC time as well")
+	verbose := fs.Bool("verbose", false, "Verbose output")
+
+	helpPage, err := cmdInfo.GenerateHelpPage()
+	if err != nil {
+		ccmd.Fatalf("generating help page: %v", err)
+	}
+
+	fs.Usage = func() {
+		fmt.Fprint(stdio.Out, helpPage)
+	}
+
+	cmdInfo.ParseArgs(args[1:])
+
+	if len(fs.Args()) == 0 {
+		fmt.Fprint(stdio.Out, helpPage)
+		return int(ccmd.ExitSuccess)
+	}
+
+	if *verbose {
+		ntpdate.Debug = log.Printf
+	}
+
+	// The servers are queried in the background, so that an interrupt doesn't wait for their timeouts
+	type result struct {
+		server string
+		offset float64
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		server, offset, err := ntpdate.SetTime(fs.Args(), *config, fallback, *setRTC)
+		done <- result{server, offset, err}
+	}()
+	ctx := ccmd.Context()
+	var r result
+	select {
+	case r = <-done:
+	case <-ctx.Done():
+		return int(ccmd.ExitCodeOf(context.Cause(ctx)))
+	}
+	if r.err != nil {
+		ccmd.Fatalf("%v", r.err)
+	}
+
+	plus := ""
+	if r.offset > 0 {
+		plus = "+"
+	}
+	log.Printf("adjust time server %s offset %s%f sec", r.server, plus, r.offset)
+	return 0
+}
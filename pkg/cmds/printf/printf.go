@@ -0,0 +1,313 @@
+// Copyright (c) 2024-2024 xplshn, sweetbbak, u-root and contributors  [3BSD]
+// For more details refer to https://github.com/xplshn/a-utils
+package printf
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/xplshn/a-utils/pkg/ccmd"
+)
+
+func usage() {
+	ccmd.Exitf(ccmd.ExitUsage, "usage: printf format [arg ...]")
+}
+
+// printf formats args according to format. \e, \xHH and %z aren't POSIX, they are rejected
+// with a *ccmd.ExtensionError when POSIXLY_CORRECT is set.
+func printf(format string, args []string) (string, error) {
+	var output strings.Builder
+	argi := 0
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			if format[i] == '\\' && i+1 < len(format) {
+				switch format[i+1] {
+				case 'n':
+					output.WriteString("\n")
+				case 't':
+					output.WriteString("\t")
+				case '\\':
+					output.WriteString("\\")
+				case 'a':
+					output.WriteString("\a")
+				case 'b':
+					output.WriteString("\b")
+				case 'e':
+					if err := ccmd.CheckExtension(`\e`); err != nil {
+						return "", err
+					}
+					output.WriteString("\033")
+				case 'f':
+					output.WriteString("\f")
+				case 'r':
+					output.WriteString("\r")
+				case 'v':
+					output.WriteString("\v")
+				case 'c':
+					return output.String(), nil
+				case 'x':
+					if i+3 < len(format) && isHexDigit(format[i+2]) && isHexDigit(format[i+3]) {
+						if err := ccmd.CheckExtension(`\x`); err != nil {
+							return "", err
+						}
+						hex := format[i+2 : i+4]
+						num, _ := strconv.ParseInt(hex, 16, 32)
+						output.WriteByte(byte(num))
+						i += 3
+						continue
+					}
+				case '0', '1', '2', '3', '4', '5', '6', '7':
+					if i+3 < len(format) && isOctalDigit(format[i+2]) && isOctalDigit(format[i+3]) {
+						oct := format[i+1 : i+4]
+						num, _ := strconv.ParseInt(oct, 8, 32)
+						output.WriteByte(byte(num))
+						i += 3
+						continue
+					}
+				default:
+					output.WriteByte(format[i])
+				}
+				i++
+				continue
+			}
+			output.WriteByte(format[i])
+			continue
+		}
+
+		i++
+		if i >= len(format) {
+			break
+		}
+
+		width := -1
+		precision := -1
+
+		// Parse width
+		if format[i] == '*' {
+			if argi < len(args) {
+				width, _ = strconv.Atoi(args[argi])
+				argi++
+			}
+			i++
+		} else {
+			j := i
+			for ; j < len(format) && format[j] >= '0' && format[j] <= '9'; j++ {
+			}
+			if j > i {
+				width, _ = strconv.Atoi(format[i:j])
+				i = j
+			}
+		}
+
+		// Parse precision
+		if i < len(format) && format[i] == '.' {
+			i++
+			if i < len(format) && format[i] == '*' {
+				if argi < len(args) {
+					precision, _ = strconv.Atoi(args[argi])
+					argi++
+				}
+				i++
+			} else {
+				j := i
+				for ; j < len(format) && format[j] >= '0' && format[j] <= '9'; j++ {
+				}
+				if j > i {
+					precision, _ = strconv.Atoi(format[i:j])
+					i = j
+				}
+			}
+		}
+
+		switch format[i] {
+		case 's':
+			if argi < len(args) {
+				s := args[argi]
+				if precision >= 0 && precision < len(s) {
+					s = s[:precision]
+				}
+				if width > 0 {
+					output.WriteString(fmt.Sprintf(fmt.Sprintf("%%%ds", width), s))
+				} else {
+					output.WriteString(s)
+				}
+				argi++
+			} else {
+				output.WriteString("")
+			}
+		case 'z':
+			if err := ccmd.CheckExtension("%z"); err != nil {
+				return "", err
+			}
+			indicator := "...>"
+			if argi < len(args) {
+				s := args[argi]
+				availableSpace := ccmd.GetTerminalWidth() - len(indicator)
+		
+				if availableSpace > 0 && len(s) > availableSpace {
+					s = s[:availableSpace] + indicator
+				}
+		
+				if width > 0 {
+					output.WriteString(fmt.Sprintf(fmt.Sprintf("%%%ds", width), s))
+				} else {
+					output.WriteString(s)
+				}
+				argi++
+			} else {
+				output.WriteString("")
+			}
+		case 'd', 'i':
+			if argi < len(args) {
+				num, err := strconv.Atoi(args[argi])
+				if err != nil {
+					ccmd.Warnf("invalid number '%s'", args[argi])
+					output.WriteString("0")
+					argi++
+					continue
+				}
+				if width > 0 {
+					output.WriteString(fmt.Sprintf(fmt.Sprintf("%%%dd", width), num))
+				} else {
+					output.WriteString(strconv.Itoa(num))
+				}
+				argi++
+			} else {
+				output.WriteString("0")
+			}
+		case 'f':
+			if argi < len(args) {
+				num, err := strconv.ParseFloat(args[argi], 64)
+				if err != nil {
+					ccmd.Warnf("invalid number '%s'", args[argi])
+					output.WriteString("0.000000")
+					argi++
+					continue
+				}
+				if precision >= 0 {
+					output.WriteString(fmt.Sprintf(fmt.Sprintf("%%.%df", precision), num))
+				} else {
+					output.WriteString(fmt.Sprintf("%.6f", num))
+				}
+				argi++
+			} else {
+				output.WriteString("0.000000")
+			}
+		case 'b':
+			if argi < len(args) {
+				unescaped, err := unescape(args[argi])
+				// only the extensions refused by POSIXLY_CORRECT abort printf
+				var extErr *ccmd.ExtensionError
+				if errors.As(err, &extErr) {
+					return "", err
+				}
+				if err != nil {
+					ccmd.Warnf("invalid escape sequence '%s'", args[argi])
+					output.WriteString("")
+					argi++
+					continue
+				}
+				output.WriteString(unescaped)
+				argi++
+			} else {
+				output.WriteString("")
+			}
+		case '%':
+			output.WriteString("%")
+		default:
+			return "", fmt.Errorf("%%: invalid format")
+		}
+	}
+
+	return output.String(), nil
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isOctalDigit(c byte) bool {
+	return c >= '0' && c <= '7'
+}
+
+// unescape expands the escape sequences of an argument of %b, see printf.
+func unescape(s string) (string, error) {
+	var output strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			switch s[i+1] {
+			case 'n':
+				output.WriteString("\n")
+			case 't':
+				output.WriteString("\t")
+			case '\\':
+				output.WriteString("\\")
+			case 'a':
+				output.WriteString("\a")
+			case 'b':
+				output.WriteString("\b")
+			case 'e':
+				if err := ccmd.CheckExtension(`\e`); err != nil {
+					return "", err
+				}
+				output.WriteString("\033")
+			case 'f':
+				output.WriteString("\f")
+			case 'r':
+				output.WriteString("\r")
+			case 'v':
+				output.WriteString("\v")
+			case 'x':
+				if i+3 < len(s) && isHexDigit(s[i+2]) && isHexDigit(s[i+3]) {
+					if err := ccmd.CheckExtension(`\x`); err != nil {
+						return "", err
+					}
+					hex := s[i+2 : i+4]
+					num, _ := strconv.ParseInt(hex, 16, 32)
+					output.WriteByte(byte(num))
+					i += 3
+					continue
+				}
+			case '0', '1', '2', '3', '4', '5', '6', '7':
+				if i+3 < len(s) && isOctalDigit(s[i+2]) && isOctalDigit(s[i+3]) {
+					oct := s[i+1 : i+4]
+					num, _ := strconv.ParseInt(oct, 8, 32)
+					output.WriteByte(byte(num))
+					i += 3
+					continue
+				}
+			default:
+				output.WriteByte(s[i])
+			}
+			i++
+		} else {
+			output.WriteByte(s[i])
+		}
+	}
+	return output.String(), nil
+}
+
+func init() {
+	ccmd.Register("printf", Main)
+}
+
+// Main runs printf, see ccmd.MainFunc.
+func Main(args []string, stdio ccmd.Stdio) int {
+	if len(args) < 2 {
+		usage()
+	}
+
+	format := args[1]
+	args = args[2:]
+
+	output, err := printf(format, args)
+	if err != nil {
+		ccmd.Fatalf("%v", err)
+	}
+
+	fmt.Fprint(stdio.Out, output)
+	return 0
+}
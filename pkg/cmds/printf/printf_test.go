@@ -1,13 +1,15 @@
 // Copyright 2013-2024 the u-root Authors. All rights reserved
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
-package main
+package printf
 
 import (
 	"fmt"
 	"io"
 	"os"
 	"testing"
+
+	"github.com/xplshn/a-utils/pkg/ccmd"
 )
 
 func TestPrintf(t *testing.T) {
@@ -166,6 +168,18 @@ func TestPrintf(t *testing.T) {
 	}
 }
 
+func TestPosixlyCorrect(t *testing.T) {
+	t.Setenv("POSIXLY_CORRECT", "1")
+	for _, format := range []string{`\e`, `\x41`, "%z", "%b"} {
+		if _, err := printf(format, []string{`\e`}); ccmd.ExitCodeOf(err) != ccmd.ExitUsage {
+			t.Errorf("printf(%q) = %v, want an extension error", format, err)
+		}
+	}
+	if output, err := printf(`%s\101%b`, []string{"A", `\n`}); err != nil || output != "AA\n" {
+		t.Errorf("printf() = %q, %v", output, err)
+	}
+}
+
 func TestMain(m *testing.M) {
 	// Capture standard output for testing
 	old := os.Stdout
@@ -0,0 +1,312 @@
+package relf
+
+import (
+	"bufio"
+	"debug/elf"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xplshn/a-utils/pkg/ccmd"
+)
+
+// humanReadableSize converts a size in bytes to a human-readable format.
+func humanReadableSize(size uint64) string {
+	const (
+		KB = 1024
+		MB = KB * 1024
+		GB = MB * 1024
+	)
+
+	switch {
+	case size >= GB:
+		return fmt.Sprintf("%.2f GB", float64(size)/GB)
+	case size >= MB:
+		return fmt.Sprintf("%.2f MB", float64(size)/MB)
+	case size >= KB:
+		return fmt.Sprintf("%.2f KB", float64(size)/KB)
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
+
+// Refinement holds functions to check for specific conditions and the corresponding section name.
+type Refinement struct {
+	SectionCheck func(*elf.File) bool
+	StringCheck  func(string) bool
+	Name         string
+}
+
+// containsString checks if the file contains a specific string using the strings utility logic.
+func containsString(filename, target string) bool {
+	file, err := os.Open(filename)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	in := bufio.NewReader(file)
+	str := make([]rune, 0, 256)
+	filePos := int64(0)
+	for {
+		var (
+			r   rune
+			wid int
+			err error
+		)
+		for {
+			r, wid, err = in.ReadRune()
+			if err != nil {
+				if err != io.EOF {
+					ccmd.Warnf("reading file: %v", err)
+				}
+				return false
+			}
+			filePos += int64(wid)
+			if !strconv.IsPrint(r) || r >= 0xFF {
+				if strings.Contains(string(str), target) {
+					return true
+				}
+				str = str[0:0]
+				continue
+			}
+			if len(str) >= 256 {
+				if strings.Contains(string(str), target) {
+					return true
+				}
+				str = str[0:0]
+			}
+			str = append(str, r)
+		}
+	}
+}
+
+// formatSize formats the size based on the human-readable flag.
+func formatSize(size uint64, humanReadable bool) string {
+	if humanReadable {
+		return humanReadableSize(size)
+	}
+	return fmt.Sprintf("%d", size)
+}
+
+// printSectionSizes prints the sizes of each section in a human-readable format.
+func printSectionSizes(w io.Writer, file *elf.File, filePath string, fileSize uint64, refinements []Refinement, humanReadable bool) {
+	var totalSize uint64
+	var sections []*elf.Section
+	for _, section := range file.Sections {
+		if section.Size > 0 {
+			sections = append(sections, section)
+			totalSize += section.Size
+		}
+	}
+
+	sort.Slice(sections, func(i, j int) bool {
+		return sections[i].Size > sections[j].Size
+	})
+
+	for _, section := range sections {
+		fmt.Fprintf(w, "%-24s %s\n", section.Name, formatSize(section.Size, humanReadable))
+	}
+
+	if totalSize < fileSize {
+		unknownSize := fileSize - totalSize
+		sectionName := "unknown"
+		for _, refinement := range refinements {
+			if refinement.SectionCheck != nil && refinement.SectionCheck(file) {
+				sectionName = refinement.Name
+				break
+			}
+			if refinement.StringCheck != nil && refinement.StringCheck(filePath) {
+				sectionName = refinement.Name
+				break
+			}
+		}
+		fmt.Fprintf(w, "!%-24s %s\n", sectionName, formatSize(unknownSize, humanReadable))
+	}
+}
+
+// printTree prints the tree structure of the sections.
+func printTree(w io.Writer, file *elf.File, filePath string, fileSize uint64, refinements []Refinement, humanReadable bool) {
+	var totalSize uint64
+	var sections []*elf.Section
+	for _, section := range file.Sections {
+		if section.Size > 0 {
+			sections = append(sections, section)
+			totalSize += section.Size
+		}
+	}
+
+	sort.Slice(sections, func(i, j int) bool {
+		return sections[i].Size > sections[j].Size
+	})
+
+	fmt.Fprintln(w, filePath)
+	for i, section := range sections {
+		prefix := "├──"
+		if i == len(sections)-1 {
+			prefix = "└──"
+		}
+		fmt.Fprintf(w, "    %s %s (%s)\n", prefix, section.Name, formatSize(section.Size, humanReadable))
+	}
+
+	if totalSize < fileSize {
+		unknownSize := fileSize - totalSize
+		sectionName := "!unknown"
+		for _, refinement := range refinements {
+			if refinement.SectionCheck != nil && refinement.SectionCheck(file) {
+				sectionName = refinement.Name
+				break
+			}
+			if refinement.StringCheck != nil && refinement.StringCheck(filePath) {
+				sectionName = refinement.Name
+				break
+			}
+		}
+		fmt.Fprintf(w, "    %s %s (%s)\n", "└──", sectionName, formatSize(unknownSize, humanReadable))
+	}
+}
+
+// writeSectionRecords writes one record per section, as well as one for the data that isn't in any section.
+func writeSectionRecords(out *ccmd.Output, file *elf.File, filePath string, fileSize uint64, refinements []Refinement) {
+	var totalSize uint64
+	var sections []*elf.Section
+	for _, section := range file.Sections {
+		if section.Size > 0 {
+			sections = append(sections, section)
+			totalSize += section.Size
+		}
+	}
+
+	sort.Slice(sections, func(i, j int) bool {
+		return sections[i].Size > sections[j].Size
+	})
+
+	for _, section := range sections {
+		out.Write(ccmd.Record{"file": filePath, "section": section.Name, "size": section.Size, "inferred": false})
+	}
+
+	if totalSize < fileSize {
+		sectionName := "unknown"
+		for _, refinement := range refinements {
+			if (refinement.SectionCheck != nil && refinement.SectionCheck(file)) || (refinement.StringCheck != nil && refinement.StringCheck(filePath)) {
+				sectionName = refinement.Name
+				break
+			}
+		}
+		out.Write(ccmd.Record{"file": filePath, "section": sectionName, "size": fileSize - totalSize, "inferred": true})
+	}
+}
+
+// isElfFile checks if the file is an ELF file by reading its magic number.
+func isElfFile(filename string) bool {
+	file, err := os.Open(filename)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	var magic [4]byte
+	_, err = file.Read(magic[:])
+	if err != nil {
+		return false
+	}
+
+	return string(magic[:]) == "\x7FELF"
+}
+
+func init() {
+	ccmd.Register("relf", Main)
+}
+
+// Main runs relf, see ccmd.MainFunc.
+func Main(args []string, stdio ccmd.Stdio) int {
+	fs := ccmd.NewFlagSet("relf", flag.ContinueOnError)
+	treeFlag := fs.Bool("t", false, "Show the section sizes in a tree structure")
+	humanReadableFlag := fs.Bool("s", false, "Show sizes in human-readable format")
+	format := ccmd.FormatText
+	fs.Var(&format, "format", "Output format: 'text', 'json', 'ndjson', 'tsv'")
+
+	cmdInfo := &ccmd.CmdInfo{
+		Authors:     []string{"xplshn"},
+		Name:        "relf",
+		Synopsis:    "<|-t|-s|> <elf-file>",
+		Description: "Prints section sizes of ELF files",
+		Repository:  "https://github.com/xplshn/a-utils",
+		Examples: []ccmd.Example{
+			{Description: "Print section sizes in human-readable format", Command: "relf -s file.elf"},
+			{Description: "Print the sizes of the sections, as JSON", Command: "relf --format=json file.elf"},
+		},
+		Behavior: "With --format=json|ndjson|tsv, -t and -s are ignored and sizes are in bytes.",
+		Fields: []ccmd.Field{
+			{Name: "file", Description: "Path of the ELF file"},
+			{Name: "section", Description: "Name of the section"},
+			{Name: "size", Description: "Size of the section, in bytes"},
+			{Name: "inferred", Description: "Whether the data is outside of any section, its name being guessed (e.g: \"sqfs\", \"unknown\")"},
+		},
+		Flags: fs,
+	}
+	helpPage, err := cmdInfo.GenerateHelpPage()
+	if err != nil {
+		ccmd.Fatalf("generating help page: %v", err)
+	}
+	// Set usage to print the CCMD-generated help page
+	fs.Usage = func() {
+		fmt.Fprint(stdio.Out, helpPage)
+	}
+
+	cmdInfo.ParseArgs(args[1:])
+
+	if fs.NArg() < 1 {
+		fmt.Fprint(stdio.Out, helpPage)
+		return int(ccmd.ExitUsage)
+	}
+
+	filePath := fs.Arg(0)
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		ccmd.Fatalf("%v", err)
+	}
+	if !isElfFile(filePath) {
+		ccmd.Fatalf("%s is not an ELF file", filePath)
+	}
+	fileSize := uint64(fileInfo.Size())
+
+	refinements := []Refinement{
+		{SectionCheck: func(file *elf.File) bool { return hasSection(file, ".appimage") }, StringCheck: func(filename string) bool {
+			return containsString(filename, "--appimage-offset") && containsString(filename, "fsname=squashfuse")
+		}, Name: "appimage.archive.sqfs"},
+		{SectionCheck: func(file *elf.File) bool { return hasSection(file, ".sqfs") }, StringCheck: func(filename string) bool { return containsString(filename, "fsname=squashfuse") }, Name: "sqfs"},
+		//{SectionCheck: func(file *elf.File) bool { return hasSection(file, ".dwfs") }, StringCheck: func(filename string) bool { return containsString(filename, "DWARFS") }, Name: "!dwfs"}, // unreliable
+	}
+
+	file, err := elf.Open(filePath)
+	if err != nil {
+		ccmd.Fatalf("%v", err)
+	}
+	defer file.Close()
+
+	if format.Structured() {
+		out := cmdInfo.NewOutput(stdio.Out, format)
+		writeSectionRecords(out, file, filePath, fileSize, refinements)
+		out.Close()
+	} else if *treeFlag {
+		printTree(stdio.Out, file, filePath, fileSize, refinements, *humanReadableFlag)
+	} else {
+		printSectionSizes(stdio.Out, file, filePath, fileSize, refinements, *humanReadableFlag)
+	}
+	return 0
+}
+
+// hasSection checks if the ELF file contains a specific section.
+func hasSection(file *elf.File, sectionName string) bool {
+	for _, section := range file.Sections {
+		if section.Name == sectionName {
+			return true
+		}
+	}
+	return false
+}
@@ -1,9 +1,13 @@
-package main
+package test
 
 import (
 	"os"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/xplshn/a-utils/pkg/ccmd"
+	"github.com/xplshn/a-utils/pkg/ccmd/ccmdtest"
 )
 
 // Test for intcmp function
@@ -245,6 +249,10 @@ func TestPerformTest(t *testing.T) {
 		{[]string{"124", "-le", "124"}, true},
 		{[]string{"!", "-f", "test_test.go"}, false},
 		{[]string{"!", "-d", "test_test.go"}, true},
+		{[]string{"a", "<", "b"}, true},
+		{[]string{"a", ">", "b"}, false},
+		{[]string{"abc", "=~", "^a.c$"}, true},
+		{[]string{"abc", "=~", "^b"}, false},
 	}
 
 	for _, test := range tests {
@@ -254,3 +262,37 @@ func TestPerformTest(t *testing.T) {
 		}
 	}
 }
+
+// Test that invalid expressions are errors rather than false
+func TestInvalidExpressions(t *testing.T) {
+	tests := [][]string{
+		{"-Q", "test_test.go"},
+		{"a", "-nope", "b"},
+		{"a", "b", "c", "d"},
+		{"a", "-eq", "1"},
+		{"a", "=~", "("},
+	}
+
+	for _, args := range tests {
+		ccmdtest.AssertExit(t, ccmd.ExitUsage, func() { performTest(args) })
+	}
+}
+
+// Test that extensions are rejected when POSIXLY_CORRECT is set
+func TestPosixlyCorrect(t *testing.T) {
+	if code := Main([]string{"test", "[[", "a", "<", "b", "]]"}, ccmd.OSStdio()); code != 0 {
+		t.Errorf("[[ a < b ]] exited with %d", code)
+	}
+
+	t.Setenv("POSIXLY_CORRECT", "1")
+	for _, args := range [][]string{
+		{"test", "a", "=~", "a"},
+		{"test", "a", "<", "b"},
+		{"test", "[[", "a", "]]"},
+	} {
+		stderr := ccmdtest.AssertExit(t, ccmd.ExitUsage, func() { Main(args, ccmd.OSStdio()) })
+		if !strings.Contains(stderr, "NOT POSIX") {
+			t.Errorf("%q: diagnostic %q", args, stderr)
+		}
+	}
+}
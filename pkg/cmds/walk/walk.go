@@ -0,0 +1,268 @@
+// walk traverses directory trees, printing visited files // heavily modified version of ![Torgo's walk.go](https://github.com/as/torgo/blob/master/walk/walk.go)
+package walk
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/charlievieth/fastwalk"
+	"github.com/xplshn/a-utils/pkg/ccmd"
+)
+
+var (
+	visitedMap  = make(map[string]bool)
+	rwlock      sync.RWMutex
+	visitedFunc = markVisited
+	out         *ccmd.Output
+)
+
+type FileInfo struct {
+	Path      string
+	IsDir     bool
+	IsSymlink bool
+}
+
+func isdirectory(path string) bool {
+	FileInfo, err := gatherFileInfo(path)
+	if err != nil {
+		ccmd.Warnf("%v", err)
+		return false
+	}
+	return FileInfo.IsDir
+}
+
+func isNotdirectory(path string) bool {
+	return !isdirectory(path)
+}
+
+func isHidden(path string) bool {
+	components := strings.Split(path, "/")
+	filename := components[len(components)-1]
+	if strings.HasPrefix(filename, ".") {
+		return true
+	}
+	for _, component := range components {
+		if strings.HasPrefix(component, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+func isNotHidden(path string) bool {
+	return !isHidden(path)
+}
+
+func markVisited(path string) (alreadyVisited bool) {
+	rwlock.RLock()
+	_, alreadyVisited = visitedMap[path]
+	rwlock.RUnlock()
+
+	if !alreadyVisited {
+		rwlock.Lock()
+		visitedMap[path] = true
+		rwlock.Unlock()
+	}
+	return
+}
+
+// entryType names the type of a directory entry for the "type" field.
+func entryType(d fs.DirEntry) string {
+	switch mode := d.Type(); {
+	case mode.IsDir():
+		return "directory"
+	case mode&fs.ModeSymlink != 0:
+		return "symlink"
+	case mode.IsRegular():
+		return "file"
+	case mode&fs.ModeNamedPipe != 0:
+		return "fifo"
+	case mode&fs.ModeSocket != 0:
+		return "socket"
+	case mode&fs.ModeDevice != 0:
+		return "device"
+	}
+	return "other"
+}
+
+func printFile(path string, d fs.DirEntry, conditions []func(string) bool) {
+	for _, condition := range conditions {
+		if !condition(path) {
+			return
+		}
+	}
+	out.Write(ccmd.Record{"path": path, "type": entryType(d)})
+}
+
+func printAbsoluteFile(path string, d fs.DirEntry, conditions []func(string) bool) bool {
+	var err error
+	if !filepath.IsAbs(path) {
+		if path, err = filepath.Abs(path); err != nil {
+			ccmd.Warnf("%v", err)
+			return false
+		}
+	}
+	printFile(path, d, conditions)
+	return true
+}
+
+func init() {
+	ccmd.Register("walk", Main)
+}
+
+// Main runs walk, see ccmd.MainFunc.
+func Main(args []string, stdio ccmd.Stdio) int {
+	flags := ccmd.NewFlagSet("walk", flag.ContinueOnError)
+	traversalLimit := flags.Int64("t", 1024*1024*1024, "Traversal depth limit")
+	printDirectories := flags.Bool("d", false, "Print directories only")
+	printFiles := flags.Bool("f", false, "Print files only")
+	printAbsolute := flags.Bool("A", false, "Print absolute paths")
+	showAll := flags.Bool("a", false, `Show paths that contain a directory or file prepended with '.'`)
+	format := ccmd.FormatText
+	flags.Var(&format, "format", "Output format: 'text', 'json', 'ndjson', 'tsv'")
+
+	cmdInfo := &ccmd.CmdInfo{
+		Name:        "walk",
+		Authors:     []string{"as", "xplshn"}, // Should his name ("as") be here? This is nothing like the original, not anymore.
+		Repository:  "https://github.com/xplshn/a-utils",
+		Description: "traverse a list of targets (directories or files)",
+		Synopsis:    "<|-t [INT]|-d|-f|-A|-a|--format=FORMAT|> [target ...]",
+		Fields: []ccmd.Field{
+			{Name: "path", Description: "Path of the file, absolute with -A"},
+			{Name: "type", Description: "One of: file, directory, symlink, fifo, socket, device, other"},
+		},
+		Flags: flags,
+	}
+	helpPage, err := cmdInfo.GenerateHelpPage()
+	if err != nil {
+		ccmd.Fatalf("generating help page: %v", err)
+	}
+	flags.Usage = func() {
+		fmt.Fprint(stdio.Out, helpPage)
+	}
+	cmdInfo.ParseArgs(args[1:])
+
+	if *printDirectories && *printFiles {
+		ccmd.Exitf(ccmd.ExitUsage, "bad args: -dirs-only and -files-only cannot both be true")
+	}
+
+	visitedMap = make(map[string]bool)
+	out = cmdInfo.NewOutput(stdio.Out, format)
+	out.Text = func(w io.Writer, r ccmd.Record) error {
+		_, err := fmt.Fprintln(w, r["path"])
+		return err
+	}
+
+	var conditions []func(string) bool
+	if !*showAll {
+		conditions = append(conditions, isNotHidden)
+	}
+	if *printDirectories {
+		conditions = append(conditions, isdirectory)
+	}
+	if *printFiles {
+		conditions = append(conditions, isNotdirectory)
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	// An interrupt stops the traversal, what was found until then is still printed
+	ctx := ccmd.Context()
+
+	var wg sync.WaitGroup
+	var visitedCount int64
+	var visitedLock sync.Mutex
+
+	for _, target := range paths {
+		wg.Add(1)
+		go func(target string) {
+			defer wg.Done()
+			if target != "-" {
+				walkFn := func(path string, d fs.DirEntry, err error) error {
+					if ctx.Err() != nil {
+						return fs.SkipAll
+					}
+					if err != nil {
+						if !errors.Is(err, fs.SkipAll) {
+							ccmd.Warnf("%s: %v", path, err)
+						}
+						return nil
+					}
+					if visitedFunc(path) {
+						return nil
+					}
+					visitedLock.Lock()
+					if visitedCount >= *traversalLimit {
+						visitedLock.Unlock()
+						return fs.SkipAll
+					}
+					visitedCount++
+					visitedLock.Unlock()
+					if *printAbsolute {
+						printAbsoluteFile(path, d, conditions)
+					} else {
+						printFile(path, d, conditions)
+					}
+					return nil
+				}
+				conf := fastwalk.Config{
+					Follow: false,
+				}
+				if err := fastwalk.Walk(&conf, target, walkFn); err != nil && !errors.Is(err, fs.SkipAll) {
+					ccmd.Fatalf("%s: %v", target, err)
+				}
+			} else {
+				in := bufio.NewScanner(stdio.In)
+				for ctx.Err() == nil && in.Scan() {
+					walkFn := func(path string, d fs.DirEntry, err error) error {
+						if ctx.Err() != nil {
+							return fs.SkipAll
+						}
+						if err != nil {
+							if !errors.Is(err, fs.SkipAll) {
+								ccmd.Warnf("%s: %v", path, err)
+							}
+							return nil
+						}
+						if visitedFunc(path) {
+							return nil
+						}
+						visitedLock.Lock()
+						if visitedCount >= *traversalLimit {
+							visitedLock.Unlock()
+							return fs.SkipAll
+						}
+						visitedCount++
+						visitedLock.Unlock()
+						if *printAbsolute {
+							printAbsoluteFile(path, d, conditions)
+						} else {
+							printFile(path, d, conditions)
+						}
+						return nil
+					}
+					conf := fastwalk.Config{
+						Follow: false,
+					}
+					if err := fastwalk.Walk(&conf, in.Text(), walkFn); err != nil && !errors.Is(err, fs.SkipAll) {
+						ccmd.Fatalf("%s: %v", in.Text(), err)
+					}
+				}
+			}
+		}(target)
+	}
+	wg.Wait()
+	out.Close()
+	return int(ccmd.ExitCodeOf(context.Cause(ctx)))
+}
@@ -1,5 +1,5 @@
 //go:build plan9
-package main
+package walk
 
 import (
 	"os"
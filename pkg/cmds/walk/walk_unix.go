@@ -1,5 +1,5 @@
 //go:build !plan9 && !windows
-package main
+package walk
 
 import (
 	"syscall"